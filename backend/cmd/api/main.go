@@ -2,14 +2,19 @@ package main
 
 import (
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jajanin/backend/internal/config"
 	"github.com/jajanin/backend/internal/database"
 	"github.com/jajanin/backend/internal/handlers"
+	"github.com/jajanin/backend/internal/mailer"
 	"github.com/jajanin/backend/internal/middleware"
+	"github.com/jajanin/backend/internal/models"
 	"github.com/jajanin/backend/internal/repository"
 	"github.com/jajanin/backend/internal/services"
+	"github.com/jajanin/backend/internal/storage"
 	"github.com/jajanin/backend/internal/utils"
 )
 
@@ -21,6 +26,15 @@ func main() {
 	utils.InitLogger(cfg.Env)
 	utils.Log.Info().Str("env", cfg.Env).Msg("Starting Jajanin API server")
 
+	if err := cfg.Validate(); err != nil {
+		utils.Log.Fatal().Err(err).Msg("Invalid configuration")
+	}
+
+	// Load the keys that sign access tokens
+	if err := utils.InitJWTKeys(cfg); err != nil {
+		utils.Log.Fatal().Err(err).Msg("Failed to load JWT signing keys")
+	}
+
 	// Connect to database
 	db, err := database.Connect(cfg)
 	if err != nil {
@@ -40,28 +54,114 @@ func main() {
 	withdrawalRepo := repository.NewWithdrawalRepository(db)
 	quickItemRepo := repository.NewQuickItemRepository(db)
 	settingsRepo := repository.NewSystemSettingsRepository(db)
+	goalRepo := repository.NewGoalRepository(db)
+	leaderboardRepo := repository.NewLeaderboardRepository(db)
+	subathonRepo := repository.NewSubathonRepository(db)
+	tickerRepo := repository.NewTickerRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	auditLogRepo := repository.NewAuditLogRepository(db)
+	loginSecurityRepo := repository.NewLoginSecurityRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	accessTokenRepo := repository.NewAccessTokenRepository(db)
+	userTokenRepo := repository.NewUserTokenRepository(db)
+	pollRepo := repository.NewPollRepository(db)
+	overlayTokenRepo := repository.NewOverlayTokenRepository(db)
+	assetRepo := repository.NewAssetRepository(db)
+	overlayProfileRepo := repository.NewOverlayProfileRepository(db)
+	privacyRepo := repository.NewPrivacyRepository(db)
+
+	// Initialize upload storage
+	store, err := storage.New(cfg)
+	if err != nil {
+		utils.Log.Fatal().Err(err).Msg("Failed to initialize storage")
+	}
+
+	// Initialize email delivery
+	mail, err := mailer.New(cfg)
+	if err != nil {
+		utils.Log.Fatal().Err(err).Msg("Failed to initialize mailer")
+	}
 
 	// Initialize services
 	paylabsService, err := services.NewPaylabsService(cfg)
 	if err != nil {
 		utils.Log.Fatal().Err(err).Msg("Failed to initialize Paylabs service")
 	}
-	alertService := services.NewAlertService()
-	authService := services.NewAuthService(userRepo)
-	userService := services.NewUserService(userRepo)
-	donationService := services.NewDonationService(donationRepo, userRepo, paylabsService, alertService)
+	subscribers := services.NewSubscribers(services.StreamLimits{
+		MaxPerCreator: cfg.SSEMaxPerCreator,
+		MaxTotal:      cfg.SSEMaxConnections,
+		Buffer:        cfg.SSEBufferSize,
+	})
+	alertService := services.NewAlertService(subscribers)
+	streamHub := services.NewStreamHub(subscribers)
+	overlayPresence := services.NewOverlayPresence()
+	sessionService := services.NewSessionService(sessionRepo, userRepo, time.Duration(cfg.SessionTTLDays)*24*time.Hour)
+	googleVerifier := services.NewGoogleTokenVerifier(cfg.GoogleClientID, cfg.GoogleJWKSURL)
+	twoFactorSealer, err := utils.NewSealer(cfg.TwoFactorEncryptionKey, strings.Split(cfg.TwoFactorPreviousKeys, ",")...)
+	if err != nil {
+		utils.Log.Fatal().Err(err).Msg("Failed to initialize two-factor encryption")
+	}
+	auditService := services.NewAuditService(auditLogRepo)
+	accountService := services.NewAccountService(userRepo, userTokenRepo, sessionService, auditService, googleVerifier, mail, cfg.FrontendURL,
+		time.Duration(cfg.EmailVerifyTTLHours)*time.Hour, time.Duration(cfg.PasswordResetTTLMinutes)*time.Minute)
+	loginGuard := services.NewLoginGuardService(loginSecurityRepo, userRepo, auditService, accountService, services.LockoutPolicy{
+		Threshold: cfg.LoginLockoutThreshold,
+		Base:      time.Duration(cfg.LoginLockoutBaseMinutes) * time.Minute,
+		Max:       time.Duration(cfg.LoginLockoutMaxHours) * time.Hour,
+	})
+	twoFactorService := services.NewTwoFactorService(twoFactorRepo, userRepo, sessionRepo, twoFactorSealer, loginGuard)
+	permissionService := services.NewPermissionService(roleRepo, userRepo, twoFactorRepo, auditService)
+	twoFactorService.AddChangeListener(permissionService)
+	accessTokenService := services.NewAccessTokenService(accessTokenRepo, auditService)
+	authService := services.NewAuthService(userRepo, sessionService, googleVerifier, twoFactorService, loginGuard, accessTokenService)
+	userService := services.NewUserService(userRepo, assetRepo, alertService, time.Duration(cfg.StreamKeyGraceHours)*time.Hour,
+		time.Duration(cfg.UsernameCooloffDays)*24*time.Hour)
+	overlayTokenService := services.NewOverlayTokenService(overlayTokenRepo, overlayProfileRepo, userService)
+	overlayProfileService := services.NewOverlayProfileService(overlayProfileRepo, userService, overlayTokenService)
+	assetService := services.NewAssetService(assetRepo, store, cfg)
+	donationService := services.NewDonationService(donationRepo, userRepo, pollRepo, paylabsService, alertService)
 	withdrawalService := services.NewWithdrawalService(withdrawalRepo, donationRepo, userRepo)
+	privacyService := services.NewPrivacyService(privacyRepo, userRepo, assetRepo, withdrawalService, store, auditService)
 	quickItemService := services.NewQuickItemService(quickItemRepo, userRepo)
+	goalService := services.NewGoalService(goalRepo, userRepo, streamHub, alertService)
+	leaderboardService := services.NewLeaderboardService(leaderboardRepo, userRepo, streamHub)
+	donationService.AddPaidListener(goalService)
+	subathonService := services.NewSubathonService(subathonRepo, streamHub)
+	donationService.AddPaidListener(leaderboardService)
+	pollService := services.NewPollService(pollRepo, userRepo, streamHub)
+	donationService.AddPaidListener(subathonService)
+	donationService.AddPaidListener(pollService)
+	tickerService := services.NewTickerService(tickerRepo, donationRepo, userRepo, streamHub)
+	donationService.AddPaidListener(tickerService)
+	donationService.AddApprovedListener(tickerService)
+	donationService.AddApprovedListener(leaderboardService)
+	qrService := services.NewQRService(donationRepo, userRepo, cfg.FrontendURL)
 
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(authService)
+	authHandler := handlers.NewAuthHandler(authService, accountService)
+	accountHandler := handlers.NewAccountHandler(accountService)
+	sessionHandler := handlers.NewSessionHandler(sessionService)
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService)
+	accessTokenHandler := handlers.NewAccessTokenHandler(accessTokenService)
 	userHandler := handlers.NewUserHandler(userService)
+	privacyHandler := handlers.NewPrivacyHandler(privacyService)
 	donationHandler := handlers.NewDonationHandler(donationService)
 	paymentHandler := handlers.NewPaymentHandler(paylabsService, donationService)
 	withdrawalHandler := handlers.NewWithdrawalHandler(withdrawalService)
-	overlayHandler := handlers.NewOverlayHandler(alertService, userService)
+	overlayHandler := handlers.NewOverlayHandler(alertService, donationService, userService, overlayTokenService, assetService, overlayProfileService, goalService, leaderboardService, subathonService, pollService, tickerService, streamHub, overlayPresence)
+	assetHandler := handlers.NewAssetHandler(assetService)
+	overlayProfileHandler := handlers.NewOverlayProfileHandler(overlayProfileService)
 	quickItemHandler := handlers.NewQuickItemHandler(quickItemService)
-	adminHandler := handlers.NewAdminHandler(db)
+	adminHandler := handlers.NewAdminHandler(db, loginGuard, auditService)
+	roleHandler := handlers.NewRoleHandler(permissionService)
+	goalHandler := handlers.NewGoalHandler(goalService)
+	leaderboardHandler := handlers.NewLeaderboardHandler(leaderboardService)
+	subathonHandler := handlers.NewSubathonHandler(subathonService)
+	tickerHandler := handlers.NewTickerHandler(tickerService)
+	qrHandler := handlers.NewQRHandler(qrService, overlayTokenService)
+	pollHandler := handlers.NewPollHandler(pollService)
+	jwksHandler := handlers.NewJWKSHandler(utils.JWTKeys())
 
 	// Setup Gin
 	if cfg.Env == "production" {
@@ -80,6 +180,9 @@ func main() {
 		c.JSON(http.StatusOK, gin.H{"status": "ok", "service": "jajanin-api", "version": "v1"})
 	})
 
+	// Public keys for services that verify our access tokens
+	r.GET("/.well-known/jwks.json", jwksHandler.GetJWKS)
+
 	// API v1 routes with general rate limiting
 	api := r.Group("/api/v1")
 	api.Use(middleware.GeneralRateLimitMiddleware())
@@ -91,21 +194,108 @@ func main() {
 			authStrict.POST("/register", authHandler.Register)
 			authStrict.POST("/login", authHandler.Login)
 			authStrict.POST("/google", authHandler.GoogleAuth)
+			authStrict.POST("/refresh", sessionHandler.Refresh)
+			authStrict.POST("/forgot-password", accountHandler.ForgotPassword)
+			authStrict.POST("/reset-password", accountHandler.ResetPassword)
+			authStrict.POST("/verify-email", accountHandler.VerifyEmail)
+			authStrict.POST("/2fa/verify", authHandler.VerifyTwoFactor)
 		}
 
 		// Auth /me endpoint (separate, no strict rate limit - called frequently)
-		api.GET("/auth/me", middleware.AuthMiddleware(), authHandler.Me)
+		api.GET("/auth/me", middleware.AuthMiddleware(sessionService), authHandler.Me)
+
+		// Sessions (signed-in devices)
+		api.POST("/auth/logout", middleware.AuthMiddleware(sessionService), sessionHandler.Logout)
+		api.POST("/auth/logout-all", middleware.AuthMiddleware(sessionService), sessionHandler.LogoutAll)
+		api.GET("/auth/sessions", middleware.AuthMiddleware(sessionService), sessionHandler.ListSessions)
+		api.DELETE("/auth/sessions/:id", middleware.AuthMiddleware(sessionService), sessionHandler.RevokeSession)
+		api.POST("/auth/verify-email/resend", middleware.AuthMiddleware(sessionService), accountHandler.ResendVerification)
+
+		// Two-factor authentication (codes are rate limited like logins)
+		api.GET("/auth/2fa", middleware.AuthMiddleware(sessionService), twoFactorHandler.GetStatus)
+		twoFactor := api.Group("/auth/2fa")
+		twoFactor.Use(middleware.StrictRateLimitMiddleware(), middleware.AuthMiddleware(sessionService))
+		{
+			twoFactor.POST("/setup", twoFactorHandler.Setup)
+			twoFactor.POST("/enable", twoFactorHandler.Enable)
+			twoFactor.POST("/disable", twoFactorHandler.Disable)
+			twoFactor.POST("/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+			twoFactor.POST("/step-up", twoFactorHandler.StepUp)
+		}
+		requireStepUp := middleware.RequireStepUp(twoFactorService)
+
+		// Changing the password or email needs the current password, so
+		// attempts are rate limited like logins
+		account := api.Group("/auth")
+		account.Use(middleware.StrictRateLimitMiddleware())
+		{
+			account.POST("/change-password", middleware.AuthMiddleware(sessionService), requireStepUp, accountHandler.ChangePassword)
+			account.POST("/change-email", middleware.AuthMiddleware(sessionService), requireStepUp, accountHandler.ChangeEmail)
+			account.POST("/confirm-email-change", accountHandler.ConfirmEmailChange)
+		}
+
+		// Personal access tokens for creator automation. Managing them needs
+		// a signed-in session; tokenAuth lets them call the scoped routes.
+		tokenAuth := middleware.NewTokenAuth(sessionService, accessTokenService, cfg.AccessTokenRateLimit)
+		accessTokens := api.Group("/auth/tokens")
+		accessTokens.Use(middleware.AuthMiddleware(sessionService))
+		{
+			accessTokens.GET("", accessTokenHandler.GetTokens)
+			accessTokens.POST("", requireStepUp, accessTokenHandler.CreateToken)
+			accessTokens.DELETE("/:id", accessTokenHandler.RevokeToken)
+		}
 
 		// User routes
 		users := api.Group("/users")
 		{
 			users.GET("/:username", userHandler.GetProfile)
 			users.GET("/:username/alert-settings", userHandler.GetAlertSettings) // Public for overlay
-			users.PUT("/profile", middleware.AuthMiddleware(), userHandler.UpdateProfile)
-			users.PUT("/bank", middleware.AuthMiddleware(), userHandler.UpdateBank)
-			users.PUT("/social", middleware.AuthMiddleware(), userHandler.UpdateSocialLinks)
-			users.PUT("/alert-settings", middleware.AuthMiddleware(), userHandler.UpdateAlertSettings)
-			users.POST("/regenerate-stream-key", middleware.AuthMiddleware(), userHandler.RegenerateStreamKey)
+			users.PUT("/profile", middleware.AuthMiddleware(sessionService), userHandler.UpdateProfile)
+			users.PUT("/bank", middleware.AuthMiddleware(sessionService), requireStepUp, userHandler.UpdateBank)
+			users.PUT("/social", middleware.AuthMiddleware(sessionService), userHandler.UpdateSocialLinks)
+			users.PUT("/alert-settings", middleware.AuthMiddleware(sessionService), userHandler.UpdateAlertSettings)
+			users.PATCH("/alert-settings", middleware.AuthMiddleware(sessionService), userHandler.PatchAlertSettings)
+			users.PUT("/alert-approval", middleware.AuthMiddleware(sessionService), userHandler.UpdateAlertApproval)
+			users.POST("/regenerate-stream-key", middleware.AuthMiddleware(sessionService), requireStepUp, userHandler.RegenerateStreamKey)
+
+			// Personal data export and account deletion
+			users.GET("/me/export", middleware.AuthMiddleware(sessionService), requireStepUp, privacyHandler.ExportData)
+			users.DELETE("/me", middleware.AuthMiddleware(sessionService), requireStepUp, privacyHandler.DeleteAccount)
+
+			// Donation goals
+			users.GET("/:username/goal", goalHandler.GetPublicGoal) // Public for creator page
+			users.GET("/goals", middleware.AuthMiddleware(sessionService), goalHandler.GetGoals)
+			users.POST("/goals", middleware.AuthMiddleware(sessionService), goalHandler.CreateGoal)
+			users.PUT("/goals/:id", middleware.AuthMiddleware(sessionService), goalHandler.UpdateGoal)
+			users.DELETE("/goals/:id", middleware.AuthMiddleware(sessionService), goalHandler.DeleteGoal)
+
+			// Printable donation page QR code
+			users.GET("/:username/qr", qrHandler.ProfileQR)
+
+			// Top supporters leaderboard
+			users.GET("/:username/leaderboard", leaderboardHandler.GetPublicLeaderboard) // Public for creator page
+			users.GET("/leaderboard/settings", middleware.AuthMiddleware(sessionService), leaderboardHandler.GetSettings)
+			users.PUT("/leaderboard/settings", middleware.AuthMiddleware(sessionService), leaderboardHandler.UpdateSettings)
+			users.POST("/leaderboard/session", middleware.AuthMiddleware(sessionService), leaderboardHandler.StartSession)
+
+			// Recent supporters ticker
+			users.GET("/ticker", middleware.AuthMiddleware(sessionService), tickerHandler.GetTicker)
+			users.GET("/ticker/settings", middleware.AuthMiddleware(sessionService), tickerHandler.GetSettings)
+			users.PUT("/ticker/settings", middleware.AuthMiddleware(sessionService), tickerHandler.UpdateSettings)
+
+			// Subathon timer
+			users.GET("/subathon", middleware.AuthMiddleware(sessionService), subathonHandler.GetTimer)
+			users.PUT("/subathon/rules", middleware.AuthMiddleware(sessionService), subathonHandler.UpdateRules)
+			users.POST("/subathon/start", middleware.AuthMiddleware(sessionService), subathonHandler.Start)
+			users.POST("/subathon/pause", middleware.AuthMiddleware(sessionService), subathonHandler.Pause)
+			users.POST("/subathon/resume", middleware.AuthMiddleware(sessionService), subathonHandler.Resume)
+			users.POST("/subathon/end", middleware.AuthMiddleware(sessionService), subathonHandler.End)
+
+			// Donation-powered polls
+			users.GET("/:username/poll", pollHandler.GetPublicPoll) // Public for donation page
+			users.GET("/polls", middleware.AuthMiddleware(sessionService), pollHandler.GetPolls)
+			users.POST("/polls", middleware.AuthMiddleware(sessionService), pollHandler.CreatePoll)
+			users.POST("/polls/:id/close", middleware.AuthMiddleware(sessionService), pollHandler.ClosePoll)
 		}
 
 		// Quick Items routes (global products)
@@ -118,10 +308,15 @@ func main() {
 		// Donation routes
 		donations := api.Group("/donations")
 		{
-			donations.POST("", middleware.OptionalAuth(), donationHandler.CreateDonation)
-			donations.GET("", middleware.AuthMiddleware(), donationHandler.GetDonations)
-			donations.GET("/stats", middleware.AuthMiddleware(), donationHandler.GetStats)
+			donations.POST("", middleware.OptionalAuth(sessionService), donationHandler.CreateDonation)
+			donations.GET("", tokenAuth.Require(models.ScopeDonationsRead), donationHandler.GetDonations)
+			donations.GET("/stats", tokenAuth.Require(models.ScopeStatsRead), donationHandler.GetStats)
 			donations.GET("/recent/:username", donationHandler.GetRecentDonations)
+
+			// Manual approval queue
+			donations.GET("/pending-review", middleware.AuthMiddleware(sessionService), donationHandler.GetPendingAlerts)
+			donations.PUT("/:id/approve", middleware.AuthMiddleware(sessionService), donationHandler.ApproveAlert)
+			donations.PUT("/:id/reject", middleware.AuthMiddleware(sessionService), donationHandler.RejectAlert)
 		}
 
 		// Payment routes
@@ -129,39 +324,84 @@ func main() {
 		{
 			payment.POST("/webhook", paymentHandler.PaylabsWebhook)
 			payment.GET("/status/:orderID", paymentHandler.CheckPaymentStatus) // For status polling
+			payment.GET("/qr/:orderID", qrHandler.PaymentQR)                   // QRIS image (PNG/SVG)
 			payment.POST("/cancel", paymentHandler.CancelPayment)              // Cancel pending order
 		}
 
 		// Withdrawal routes
 		withdrawals := api.Group("/withdrawals")
 		{
-			withdrawals.Use(middleware.AuthMiddleware())
-			withdrawals.POST("", withdrawalHandler.CreateWithdrawal)
+			withdrawals.Use(middleware.AuthMiddleware(sessionService))
+			withdrawals.POST("", requireStepUp, withdrawalHandler.CreateWithdrawal)
 			withdrawals.GET("", withdrawalHandler.GetWithdrawals)
 			withdrawals.GET("/balance", withdrawalHandler.GetBalance)
 		}
 
-		// Admin routes (requires admin role)
+		// Admin routes (requires a staff role, each route checks its permission)
 		admin := api.Group("/admin")
-		admin.Use(middleware.AuthMiddleware())
-		admin.Use(middleware.AdminMiddleware(db))
+		admin.Use(middleware.AuthMiddleware(sessionService))
+		admin.Use(middleware.AdminMiddleware(permissionService))
 		{
-			admin.GET("/stats", adminHandler.GetStats)
-			admin.GET("/users", adminHandler.GetUsers)
-			admin.GET("/withdrawals", adminHandler.GetWithdrawals)
-			admin.PUT("/withdrawals/:id/approve", adminHandler.ApproveWithdrawal)
-			admin.PUT("/withdrawals/:id/reject", adminHandler.RejectWithdrawal)
-			admin.PUT("/withdrawals/:id/complete", adminHandler.CompleteWithdrawal)
+			admin.GET("/me", roleHandler.GetMe)
+			admin.GET("/stats", middleware.RequirePermission(models.PermStatsRead), adminHandler.GetStats)
+			admin.GET("/users", middleware.RequirePermission(models.PermUsersRead), adminHandler.GetUsers)
+			admin.POST("/users/:id/unlock", middleware.RequirePermission(models.PermUsersWrite), adminHandler.UnlockUser)
+			admin.PUT("/users/:id/role", middleware.RequirePermission(models.PermRolesManage), roleHandler.SetUserRole)
+			admin.GET("/audit-logs", middleware.RequirePermission(models.PermAuditRead), adminHandler.GetAuditLogs)
+
+			// Withdrawals
+			withdrawalsRead := middleware.RequirePermission(models.PermWithdrawalsRead)
+			withdrawalsWrite := middleware.RequirePermission(models.PermWithdrawalsWrite)
+			admin.GET("/withdrawals", withdrawalsRead, adminHandler.GetWithdrawals)
+			admin.PUT("/withdrawals/:id/approve", withdrawalsWrite, adminHandler.ApproveWithdrawal)
+			admin.PUT("/withdrawals/:id/reject", withdrawalsWrite, adminHandler.RejectWithdrawal)
+			admin.PUT("/withdrawals/:id/complete", withdrawalsWrite, adminHandler.CompleteWithdrawal)
 
 			// Admin product management
-			admin.GET("/products", quickItemHandler.GetAll)
-			admin.POST("/products", quickItemHandler.Create)
-			admin.PUT("/products/:id", quickItemHandler.Update)
-			admin.DELETE("/products/:id", quickItemHandler.Delete)
+			products := admin.Group("/products", middleware.RequirePermission(models.PermProductsManage))
+			products.GET("", quickItemHandler.GetAll)
+			products.POST("", quickItemHandler.Create)
+			products.PUT("/:id", quickItemHandler.Update)
+			products.DELETE("/:id", quickItemHandler.Delete)
 
 			// Admin settings
-			admin.GET("/settings", adminHandler.GetSettings)
-			admin.PUT("/settings", adminHandler.UpdateSettings)
+			settings := admin.Group("/settings", middleware.RequirePermission(models.PermSettingsManage))
+			settings.GET("", adminHandler.GetSettings)
+			settings.PUT("", adminHandler.UpdateSettings)
+
+			// Roles and permissions
+			roles := admin.Group("/roles", middleware.RequirePermission(models.PermRolesManage))
+			roles.GET("", roleHandler.GetRoles)
+			roles.PUT("/:name", roleHandler.UpdateRole)
+		}
+
+		// Uploaded alert assets (custom sounds / images)
+		assets := api.Group("/assets")
+		assets.Use(middleware.AuthMiddleware(sessionService))
+		{
+			assets.GET("", assetHandler.GetAll)
+			assets.POST("", assetHandler.Upload)
+			assets.DELETE("/:id", assetHandler.Delete)
+		}
+
+		// Overlay management (authenticated creator session)
+		api.POST("/overlay/test", tokenAuth.Require(models.ScopeAlertsTest), overlayHandler.TestAlert)
+		overlayAPI := api.Group("/overlay")
+		overlayAPI.Use(middleware.AuthMiddleware(sessionService))
+		{
+			overlayAPI.GET("/status", overlayHandler.GetStatus)
+			overlayAPI.GET("/tokens", overlayHandler.GetTokens)
+			overlayAPI.POST("/tokens", overlayHandler.CreateToken)
+			overlayAPI.DELETE("/tokens/:id", overlayHandler.RevokeToken)
+
+			// Named overlay profiles (alert settings per game / scene)
+			overlayAPI.GET("/profiles", overlayProfileHandler.GetProfiles)
+			overlayAPI.POST("/profiles", overlayProfileHandler.CreateProfile)
+			overlayAPI.GET("/profiles/:id", overlayProfileHandler.GetProfile)
+			overlayAPI.PATCH("/profiles/:id", overlayProfileHandler.UpdateProfile)
+			overlayAPI.DELETE("/profiles/:id", overlayProfileHandler.DeleteProfile)
+			overlayAPI.POST("/profiles/:id/clone", overlayProfileHandler.CloneProfile)
+			overlayAPI.POST("/profiles/:id/default", overlayProfileHandler.SetDefaultProfile)
 		}
 
 		// Public config (for frontend to get admin fee percentage)
@@ -173,12 +413,24 @@ func main() {
 		})
 	}
 
-	// Overlay routes (public, for OBS browser source)
+	// Signed asset URLs (public, used by overlays)
+	r.GET("/assets/:id", assetHandler.Serve)
+
+	// Overlay routes (public, read-only, for OBS browser source).
+	// :token is a widget-scoped overlay token or the legacy stream key.
 	overlay := r.Group("/overlay")
 	{
-		overlay.GET("/alert/:streamKey", overlayHandler.AlertStream)
-		overlay.POST("/test/:streamKey", overlayHandler.TestAlert)
-		overlay.GET("/settings/:streamKey", userHandler.GetAlertSettingsByStreamKey) // Get settings by stream key
+		overlay.GET("/alert/:token", overlayHandler.AlertStream)
+		overlay.POST("/ack/:token", overlayHandler.AckAlert)
+		overlay.GET("/view/:token", overlayHandler.ViewAlert)
+		overlay.GET("/static/:file", overlayHandler.StaticAsset)
+		overlay.GET("/settings/:token", overlayHandler.GetAlertSettings)
+		overlay.GET("/goal/:token", overlayHandler.GoalStream)
+		overlay.GET("/leaderboard/:token", overlayHandler.LeaderboardStream)
+		overlay.GET("/ticker/:token", overlayHandler.TickerStream)
+		overlay.GET("/qr/:token", qrHandler.OverlayQR)
+		overlay.GET("/timer/:token", overlayHandler.TimerStream)
+		overlay.GET("/poll/:token", overlayHandler.PollStream)
 	}
 
 	// Start server
@@ -1,6 +1,6 @@
 module github.com/jajanin/backend
 
-go 1.23
+go 1.24.0
 
 require (
 	github.com/gin-contrib/cors v1.7.6
@@ -11,6 +11,7 @@ require (
 	github.com/midtrans/midtrans-go v1.3.8
 	github.com/rs/zerolog v1.34.0
 	golang.org/x/crypto v0.46.0
+	gorm.io/datatypes v1.2.7
 	gorm.io/driver/postgres v1.6.0
 	gorm.io/gorm v1.31.1
 )
@@ -54,6 +55,5 @@ require (
 	golang.org/x/sys v0.39.0 // indirect
 	golang.org/x/text v0.32.0 // indirect
 	google.golang.org/protobuf v1.36.11 // indirect
-	gorm.io/datatypes v1.2.7 // indirect
 	gorm.io/driver/mysql v1.5.6 // indirect
 )
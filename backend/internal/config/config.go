@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"log"
 	"os"
 	"strconv"
@@ -23,12 +24,32 @@ type Config struct {
 	DBSSLMode  string
 
 	// JWT
-	JWTSecret      string
-	JWTExpiryHours int
+	JWTSecret           string
+	JWTPreviousSecrets  string // comma separated HS256 secrets still accepted while rotating
+	JWTKeysDir          string // RS256/EdDSA private keys as <kid>.pem; replaces JWTSecret for tokens
+	JWTActiveKID        string // key in JWTKeysDir that signs new tokens
+	JWTAccessTTLMinutes int    // access tokens, renewed with the refresh token
+	SessionTTLDays      int    // refresh tokens; a session ends after this long unused
+
+	// Two-factor authentication
+	TwoFactorEncryptionKey string // encrypts TOTP secrets at rest, falls back to JWT secret outside production
+	TwoFactorPreviousKeys  string // comma separated keys still used to read secrets while rotating
+
+	// Login lockout (per email address)
+	LoginLockoutThreshold   int // failed logins before the first lockout
+	LoginLockoutBaseMinutes int // first lockout, doubled with every further failure
+	LoginLockoutMaxHours    int // longest lockout; failures are forgotten after this
+
+	// Personal access tokens
+	AccessTokenRateLimit int // requests per minute, counted per token
+
+	// Account deletion
+	UsernameCooloffDays int // a deleted account's username can't be taken before this
 
 	// Google OAuth
 	GoogleClientID     string
 	GoogleClientSecret string
+	GoogleJWKSURL      string // keys that sign Google ID tokens
 
 	// Paylabs
 	PaylabsMerchantID string
@@ -38,17 +59,66 @@ type Config struct {
 	// URLs
 	FrontendURL string
 	AppURL      string
+
+	// Overlay
+	StreamKeyGraceHours int // how long a regenerated stream key keeps working
+	SSEMaxPerCreator    int // overlay/widget connections per creator, oldest is evicted
+	SSEMaxConnections   int // overlay/widget connections across all creators
+	SSEBufferSize       int // events queued per connection before new ones are dropped
+
+	// Email
+	MailDriver              string // "log", "file" or "smtp"
+	MailFrom                string
+	MailFileDir             string // where the file driver writes .eml files
+	SMTPHost                string
+	SMTPPort                int
+	SMTPUsername            string
+	SMTPPassword            string
+	EmailVerifyTTLHours     int
+	PasswordResetTTLMinutes int
+
+	// Uploads (alert sounds and images)
+	StorageDriver    string // "local" or "s3"
+	StorageLocalPath string
+	S3Endpoint       string
+	S3Region         string
+	S3Bucket         string
+	S3AccessKey      string
+	S3SecretKey      string
+	UploadQuotaMB    int    // total upload size allowed per creator
+	AssetURLSecret   string // signs asset URLs, falls back to JWT secret outside production
+	AssetURLTTLHours int
 }
 
 var AppConfig *Config
 
+// DefaultJWTSecret is the development fallback for JWT_SECRET. It is public,
+// so Validate refuses it in production.
+const DefaultJWTSecret = "default_secret_change_this"
+
 func LoadConfig() *Config {
 	// Load .env file
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, using environment variables")
 	}
 
-	jwtExpiry, _ := strconv.Atoi(getEnv("JWT_EXPIRY_HOURS", "72"))
+	jwtAccessTTL, _ := strconv.Atoi(getEnv("JWT_ACCESS_TTL_MINUTES", "15"))
+	sessionTTL, _ := strconv.Atoi(getEnv("SESSION_TTL_DAYS", "30"))
+	streamKeyGrace, _ := strconv.Atoi(getEnv("STREAM_KEY_GRACE_HOURS", "24"))
+	uploadQuota, _ := strconv.Atoi(getEnv("UPLOAD_QUOTA_MB", "50"))
+	assetURLTTL, _ := strconv.Atoi(getEnv("ASSET_URL_TTL_HOURS", "24"))
+	sseMaxPerCreator, _ := strconv.Atoi(getEnv("SSE_MAX_PER_CREATOR", "20"))
+	sseMaxConnections, _ := strconv.Atoi(getEnv("SSE_MAX_CONNECTIONS", "10000"))
+	sseBufferSize, _ := strconv.Atoi(getEnv("SSE_BUFFER_SIZE", "16"))
+	smtpPort, _ := strconv.Atoi(getEnv("SMTP_PORT", "587"))
+	emailVerifyTTL, _ := strconv.Atoi(getEnv("EMAIL_VERIFY_TTL_HOURS", "48"))
+	passwordResetTTL, _ := strconv.Atoi(getEnv("PASSWORD_RESET_TTL_MINUTES", "60"))
+	lockoutThreshold, _ := strconv.Atoi(getEnv("LOGIN_LOCKOUT_THRESHOLD", "5"))
+	lockoutBase, _ := strconv.Atoi(getEnv("LOGIN_LOCKOUT_BASE_MINUTES", "1"))
+	lockoutMax, _ := strconv.Atoi(getEnv("LOGIN_LOCKOUT_MAX_HOURS", "24"))
+	accessTokenRateLimit, _ := strconv.Atoi(getEnv("ACCESS_TOKEN_RATE_LIMIT", "60"))
+	usernameCooloff, _ := strconv.Atoi(getEnv("USERNAME_COOLOFF_DAYS", "30"))
+	jwtSecret := getEnv("JWT_SECRET", DefaultJWTSecret)
 
 	// Load Paylabs private key - either from file or directly from env
 	paylabsPrivateKey := getEnv("PAYLABS_PRIVATE_KEY", "")
@@ -75,12 +145,32 @@ func LoadConfig() *Config {
 		DBSSLMode:  getEnv("DB_SSLMODE", "disable"),
 
 		// JWT
-		JWTSecret:      getEnv("JWT_SECRET", "default_secret_change_this"),
-		JWTExpiryHours: jwtExpiry,
+		JWTSecret:           jwtSecret,
+		JWTPreviousSecrets:  getEnv("JWT_PREVIOUS_SECRETS", ""),
+		JWTKeysDir:          getEnv("JWT_KEYS_DIR", ""),
+		JWTActiveKID:        getEnv("JWT_ACTIVE_KID", ""),
+		JWTAccessTTLMinutes: jwtAccessTTL,
+		SessionTTLDays:      sessionTTL,
+
+		// Two-factor authentication
+		TwoFactorEncryptionKey: getEnv("TWO_FACTOR_ENCRYPTION_KEY", jwtSecret),
+		TwoFactorPreviousKeys:  getEnv("TWO_FACTOR_PREVIOUS_KEYS", ""),
+
+		// Login lockout
+		LoginLockoutThreshold:   lockoutThreshold,
+		LoginLockoutBaseMinutes: lockoutBase,
+		LoginLockoutMaxHours:    lockoutMax,
+
+		// Personal access tokens
+		AccessTokenRateLimit: accessTokenRateLimit,
+
+		// Account deletion
+		UsernameCooloffDays: usernameCooloff,
 
 		// Google OAuth
 		GoogleClientID:     getEnv("GOOGLE_CLIENT_ID", ""),
 		GoogleClientSecret: getEnv("GOOGLE_CLIENT_SECRET", ""),
+		GoogleJWKSURL:      getEnv("GOOGLE_JWKS_URL", "https://www.googleapis.com/oauth2/v3/certs"),
 
 		// Paylabs
 		PaylabsMerchantID: getEnv("PAYLABS_MERCHANT_ID", ""),
@@ -90,11 +180,59 @@ func LoadConfig() *Config {
 		// URLs
 		FrontendURL: getEnv("FRONTEND_URL", "http://localhost:3000"),
 		AppURL:      getEnv("APP_URL", "http://localhost:8080"),
+
+		// Overlay
+		StreamKeyGraceHours: streamKeyGrace,
+		SSEMaxPerCreator:    sseMaxPerCreator,
+		SSEMaxConnections:   sseMaxConnections,
+		SSEBufferSize:       sseBufferSize,
+
+		// Email
+		MailDriver:              getEnv("MAIL_DRIVER", "log"),
+		MailFrom:                getEnv("MAIL_FROM", "Jajanin <no-reply@jajanin.online>"),
+		MailFileDir:             getEnv("MAIL_FILE_DIR", "./mail"),
+		SMTPHost:                getEnv("SMTP_HOST", ""),
+		SMTPPort:                smtpPort,
+		SMTPUsername:            getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:            getEnv("SMTP_PASSWORD", ""),
+		EmailVerifyTTLHours:     emailVerifyTTL,
+		PasswordResetTTLMinutes: passwordResetTTL,
+
+		// Uploads
+		StorageDriver:    getEnv("STORAGE_DRIVER", "local"),
+		StorageLocalPath: getEnv("STORAGE_LOCAL_PATH", "./uploads"),
+		S3Endpoint:       getEnv("S3_ENDPOINT", ""),
+		S3Region:         getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:         getEnv("S3_BUCKET", ""),
+		S3AccessKey:      getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:      getEnv("S3_SECRET_KEY", ""),
+		UploadQuotaMB:    uploadQuota,
+		AssetURLSecret:   getEnv("ASSET_URL_SECRET", jwtSecret),
+		AssetURLTTLHours: assetURLTTL,
 	}
 
 	return AppConfig
 }
 
+// Validate reports settings that are unsafe to run with in production
+func (c *Config) Validate() error {
+	if c.Env != "production" {
+		return nil
+	}
+	// Asset URLs and two-factor secrets fall back to JWT_SECRET in
+	// development. In production they need their own keys: rotating
+	// JWT_SECRET would otherwise make every TOTP secret unreadable.
+	switch {
+	case c.JWTKeysDir == "" && c.JWTSecret == DefaultJWTSecret:
+		return errors.New("JWT_SECRET must be set in production")
+	case c.AssetURLSecret == DefaultJWTSecret || c.AssetURLSecret == c.JWTSecret:
+		return errors.New("ASSET_URL_SECRET must be set in production and differ from JWT_SECRET")
+	case c.TwoFactorEncryptionKey == DefaultJWTSecret || c.TwoFactorEncryptionKey == c.JWTSecret:
+		return errors.New("TWO_FACTOR_ENCRYPTION_KEY must be set in production and differ from JWT_SECRET")
+	}
+	return nil
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -8,8 +8,10 @@ import (
 	"github.com/jajanin/backend/internal/config"
 	"github.com/jajanin/backend/internal/models"
 	"github.com/jajanin/backend/internal/utils"
+	"gorm.io/datatypes"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	gormlogger "gorm.io/gorm/logger"
 )
 
@@ -123,11 +125,47 @@ func AutoMigrate(db *gorm.DB) error {
 		&models.Withdrawal{},
 		&models.QuickItem{},
 		&models.SystemSettings{},
+		&models.Goal{},
+		&models.LeaderboardSettings{},
+		&models.SubathonTimer{},
+		&models.Poll{},
+		&models.PollOption{},
+		&models.OverlayToken{},
+		&models.Asset{},
+		&models.OverlayProfile{},
+		&models.TickerSettings{},
+		&models.Session{},
+		&models.UserToken{},
+		&models.TwoFactor{},
+		&models.RecoveryCode{},
+		&models.AuditLog{},
+		&models.LoginThrottle{},
+		&models.LoginDevice{},
+		&models.Role{},
+		&models.PersonalAccessToken{},
 	)
 	if err != nil {
 		return err
 	}
 
+	// Google verified the email of accounts that signed in with it before
+	// email verification existed
+	if err := db.Model(&models.User{}).
+		Where("google_id IS NOT NULL AND email_verified_at IS NULL").
+		Update("email_verified_at", gorm.Expr("created_at")).Error; err != nil {
+		return err
+	}
+
+	// Seed the built-in roles; existing ones keep their edited permissions
+	// except admin, which always has every permission
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.DefaultRoles).Error; err != nil {
+		return err
+	}
+	if err := db.Model(&models.Role{}).Where("name = ?", models.RoleAdmin).
+		Update("permissions", datatypes.JSONSlice[string]{models.PermAll}).Error; err != nil {
+		return err
+	}
+
 	utils.Log.Info().Msg("✅ Database migrations completed")
 	return nil
 }
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/services"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+type AccessTokenHandler struct {
+	accessTokenService *services.AccessTokenService
+}
+
+func NewAccessTokenHandler(accessTokenService *services.AccessTokenService) *AccessTokenHandler {
+	return &AccessTokenHandler{accessTokenService: accessTokenService}
+}
+
+// GetTokens lists the user's personal access tokens
+func (h *AccessTokenHandler) GetTokens(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	tokens, err := h.accessTokenService.GetAll(userID.(uuid.UUID))
+	if err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("AccessTokenHandler.GetTokens", err, "Failed to get access tokens")
+		utils.InternalError(c, "Failed to get access tokens")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "", tokens)
+}
+
+// CreateToken creates a scoped personal access token; the token is only
+// returned in this response
+func (h *AccessTokenHandler) CreateToken(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var input services.CreateAccessTokenInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	log := utils.GetLoggerFromContext(c)
+	resp, err := h.accessTokenService.Create(log, userID.(uuid.UUID), &input)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidAccessScope),
+			errors.Is(err, services.ErrAccessTokenNoScopes),
+			errors.Is(err, services.ErrAccessTokenLifetime),
+			errors.Is(err, services.ErrAccessTokenLimit):
+			utils.BadRequest(c, err.Error())
+		default:
+			log.LogError("AccessTokenHandler.CreateToken", err, "Failed to create access token")
+			utils.InternalError(c, err.Error())
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "Access token created", resp)
+}
+
+// RevokeToken revokes a personal access token immediately
+func (h *AccessTokenHandler) RevokeToken(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid token ID")
+		return
+	}
+
+	log := utils.GetLoggerFromContext(c)
+	if err := h.accessTokenService.Revoke(log, userID.(uuid.UUID), id); err != nil {
+		if errors.Is(err, services.ErrAccessTokenNotFound) {
+			utils.NotFound(c, err.Error())
+			return
+		}
+		log.LogError("AccessTokenHandler.RevokeToken", err, "Failed to revoke access token")
+		utils.InternalError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Access token revoked", nil)
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/services"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+type AccountHandler struct {
+	accountService *services.AccountService
+}
+
+func NewAccountHandler(accountService *services.AccountService) *AccountHandler {
+	return &AccountHandler{accountService: accountService}
+}
+
+// VerifyEmail consumes the link from the verification email
+func (h *AccountHandler) VerifyEmail(c *gin.Context) {
+	var input services.VerifyEmailInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	user, err := h.accountService.VerifyEmail(&input)
+	if err != nil {
+		if !errors.Is(err, services.ErrInvalidUserToken) {
+			log := utils.GetLoggerFromContext(c)
+			log.LogError("AccountHandler.VerifyEmail", err, "Failed to verify email")
+		}
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Email verified", user)
+}
+
+// ResendVerification sends a new verification email to the signed-in user
+func (h *AccountHandler) ResendVerification(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	log := utils.GetLoggerFromContext(c)
+
+	if err := h.accountService.ResendVerificationEmail(log, userID.(uuid.UUID)); err != nil {
+		if !errors.Is(err, services.ErrEmailAlreadyVerified) {
+			log.LogError("AccountHandler.ResendVerification", err, "Failed to send verification email")
+		}
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Verification email sent", nil)
+}
+
+// ForgotPassword emails a reset link. The response is the same whether or
+// not the address has an account.
+func (h *AccountHandler) ForgotPassword(c *gin.Context) {
+	var input services.ForgotPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	log := utils.GetLoggerFromContext(c)
+	if err := h.accountService.RequestPasswordReset(log, &input); err != nil {
+		log.LogError("AccountHandler.ForgotPassword", err, "Failed to request password reset")
+		utils.InternalError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "If the email is registered, a reset link has been sent", nil)
+}
+
+// ResetPassword sets a new password from the emailed link
+func (h *AccountHandler) ResetPassword(c *gin.Context) {
+	var input services.ResetPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.accountService.ResetPassword(&input); err != nil {
+		if !errors.Is(err, services.ErrInvalidUserToken) {
+			log := utils.GetLoggerFromContext(c)
+			log.LogError("AccountHandler.ResetPassword", err, "Failed to reset password")
+		}
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Password has been reset, please log in again", nil)
+}
+
+// ChangePassword sets a new password and signs out the user's other devices
+func (h *AccountHandler) ChangePassword(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	sessionID, _ := c.Get("session_id")
+
+	var input services.ChangePasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	log := utils.GetLoggerFromContext(c)
+	if err := h.accountService.ChangePassword(log, userID.(uuid.UUID), sessionID.(uuid.UUID), &input); err != nil {
+		if errors.Is(err, services.ErrWrongPassword) || errors.Is(err, services.ErrGoogleReauthRequired) {
+			utils.BadRequest(c, err.Error())
+			return
+		}
+		log.LogError("AccountHandler.ChangePassword", err, "Failed to change password")
+		utils.InternalError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Password changed, other devices have been signed out", nil)
+}
+
+// ChangeEmail sends a confirmation link to the new address
+func (h *AccountHandler) ChangeEmail(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var input services.ChangeEmailInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	log := utils.GetLoggerFromContext(c)
+	if err := h.accountService.RequestEmailChange(log, userID.(uuid.UUID), &input); err != nil {
+		switch {
+		case errors.Is(err, services.ErrWrongPassword),
+			errors.Is(err, services.ErrPasswordNotSet),
+			errors.Is(err, services.ErrSameEmail),
+			errors.Is(err, services.ErrEmailTaken):
+			utils.BadRequest(c, err.Error())
+		default:
+			log.LogError("AccountHandler.ChangeEmail", err, "Failed to request email change")
+			utils.InternalError(c, err.Error())
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Check your new email for a confirmation link", nil)
+}
+
+// ConfirmEmailChange consumes the link sent to the new address
+func (h *AccountHandler) ConfirmEmailChange(c *gin.Context) {
+	var input services.ConfirmEmailChangeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	log := utils.GetLoggerFromContext(c)
+	user, err := h.accountService.ConfirmEmailChange(log, &input)
+	if err != nil {
+		if !errors.Is(err, services.ErrInvalidUserToken) && !errors.Is(err, services.ErrEmailTaken) {
+			log.LogError("AccountHandler.ConfirmEmailChange", err, "Failed to change email")
+		}
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Email changed", user)
+}
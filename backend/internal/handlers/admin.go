@@ -1,14 +1,17 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/jajanin/backend/internal/models"
 	"github.com/jajanin/backend/internal/repository"
+	"github.com/jajanin/backend/internal/services"
 	"github.com/jajanin/backend/internal/utils"
 	"gorm.io/gorm"
 )
@@ -16,12 +19,16 @@ import (
 type AdminHandler struct {
 	db           *gorm.DB
 	settingsRepo *repository.SystemSettingsRepository
+	loginGuard   *services.LoginGuardService
+	auditService *services.AuditService
 }
 
-func NewAdminHandler(db *gorm.DB) *AdminHandler {
+func NewAdminHandler(db *gorm.DB, loginGuard *services.LoginGuardService, auditService *services.AuditService) *AdminHandler {
 	return &AdminHandler{
 		db:           db,
 		settingsRepo: repository.NewSystemSettingsRepository(db),
+		loginGuard:   loginGuard,
+		auditService: auditService,
 	}
 }
 
@@ -83,17 +90,30 @@ func (h *AdminHandler) GetUsers(c *gin.Context) {
 		return
 	}
 
+	// Login lockouts are kept per lowercased email
+	emails := make([]string, len(users))
+	for i, u := range users {
+		emails[i] = strings.ToLower(u.Email)
+	}
+	var throttles []models.LoginThrottle
+	h.db.Where("email IN ? AND locked_until > ?", emails, time.Now()).Find(&throttles)
+	lockedUntil := make(map[string]*time.Time, len(throttles))
+	for _, t := range throttles {
+		lockedUntil[t.Email] = t.LockedUntil
+	}
+
 	// Return safe user data
 	var result []gin.H
 	for _, u := range users {
 		result = append(result, gin.H{
-			"id":         u.ID,
-			"email":      u.Email,
-			"name":       u.Name,
-			"username":   u.Username,
-			"role":       u.Role,
-			"image_url":  u.ImageURL,
-			"created_at": u.CreatedAt,
+			"id":           u.ID,
+			"email":        u.Email,
+			"name":         u.Name,
+			"username":     u.Username,
+			"role":         u.Role,
+			"image_url":    u.ImageURL,
+			"created_at":   u.CreatedAt,
+			"locked_until": lockedUntil[strings.ToLower(u.Email)],
 		})
 	}
 
@@ -106,6 +126,65 @@ func (h *AdminHandler) GetUsers(c *gin.Context) {
 	})
 }
 
+// UnlockUser lifts a login lockout on a user's email address
+func (h *AdminHandler) UnlockUser(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid user ID")
+		return
+	}
+	adminID, _ := c.Get("admin_user_id")
+
+	log := utils.GetLoggerFromContext(c)
+	if err := h.loginGuard.Unlock(log, adminID.(uuid.UUID), userID); err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			utils.NotFound(c, "User not found")
+			return
+		}
+		log.LogError("AdminHandler.UnlockUser", err, "Failed to unlock user")
+		utils.InternalError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "User unlocked", nil)
+}
+
+// GetAuditLogs returns security events, filterable by user_id, action and email
+func (h *AdminHandler) GetAuditLogs(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filter := repository.AuditLogFilter{
+		Action: c.Query("action"),
+		Email:  strings.ToLower(strings.TrimSpace(c.Query("email"))),
+	}
+	if raw := c.Query("user_id"); raw != "" {
+		userID, err := uuid.Parse(raw)
+		if err != nil {
+			utils.BadRequest(c, "Invalid user ID")
+			return
+		}
+		filter.UserID = &userID
+	}
+
+	result, err := h.auditService.List(filter, page, limit)
+	if err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("AdminHandler.GetAuditLogs", err, "Failed to fetch audit logs")
+		utils.InternalError(c, "Failed to fetch audit logs")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "", result)
+}
+
 // GetWithdrawals returns list of all withdrawals with pagination
 func (h *AdminHandler) GetWithdrawals(c *gin.Context) {
 	status := c.Query("status") // pending, approved, rejected
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/services"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+type AssetHandler struct {
+	assetService *services.AssetService
+}
+
+func NewAssetHandler(assetService *services.AssetService) *AssetHandler {
+	return &AssetHandler{assetService: assetService}
+}
+
+// Upload stores a custom alert sound (mp3/ogg/wav) or image (gif/webp)
+func (h *AssetHandler) Upload(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	// Leave some room for the multipart envelope on top of the largest file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, services.MaxAssetSize+(1<<20))
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.BadRequest(c, "File is required (max 5 MB)")
+		return
+	}
+	if fileHeader.Size > services.MaxAssetSize {
+		utils.BadRequest(c, services.ErrAssetTooLarge.Error())
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.BadRequest(c, "Failed to read file")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, services.MaxAssetSize+1))
+	if err != nil {
+		utils.BadRequest(c, "Failed to read file")
+		return
+	}
+
+	asset, err := h.assetService.Upload(c.Request.Context(), userID.(uuid.UUID), fileHeader.Filename, data)
+	if err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("AssetHandler.Upload", err, "Failed to upload asset")
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "File uploaded", asset)
+}
+
+// GetAll lists the creator's uploads and quota usage
+func (h *AssetHandler) GetAll(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	assets, usage, err := h.assetService.GetAll(userID.(uuid.UUID))
+	if err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("AssetHandler.GetAll", err, "Failed to get assets")
+		utils.InternalError(c, "Failed to get assets")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "", gin.H{
+		"assets": assets,
+		"usage":  usage,
+	})
+}
+
+// Delete removes an upload
+func (h *AssetHandler) Delete(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid asset ID")
+		return
+	}
+
+	if err := h.assetService.Delete(c.Request.Context(), userID.(uuid.UUID), id); err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("AssetHandler.Delete", err, "Failed to delete asset")
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "File deleted", nil)
+}
+
+// Serve streams an asset behind a signed URL (public, used by overlays)
+func (h *AssetHandler) Serve(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	asset, rc, expiresAt, err := h.assetService.Open(c.Request.Context(), id, c.Query("exp"), c.Query("sig"))
+	if errors.Is(err, services.ErrInvalidAssetURL) {
+		c.Status(http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	defer rc.Close()
+
+	maxAge := int(time.Until(expiresAt).Seconds())
+	c.Header("Cache-Control", "private, max-age="+strconv.Itoa(maxAge))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Disposition", "inline")
+	c.DataFromReader(http.StatusOK, asset.Size, asset.ContentType, rc, nil)
+}
@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -10,11 +12,31 @@ import (
 )
 
 type AuthHandler struct {
-	authService *services.AuthService
+	authService    *services.AuthService
+	accountService *services.AccountService
 }
 
-func NewAuthHandler(authService *services.AuthService) *AuthHandler {
-	return &AuthHandler{authService: authService}
+func NewAuthHandler(authService *services.AuthService, accountService *services.AccountService) *AuthHandler {
+	return &AuthHandler{authService: authService, accountService: accountService}
+}
+
+// sessionMeta describes the device making the request
+func sessionMeta(c *gin.Context) services.SessionMeta {
+	return services.SessionMeta{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+}
+
+// respondLocked answers 429 with Retry-After if err is a login lockout
+func respondLocked(c *gin.Context, err error) bool {
+	var locked *services.AccountLockedError
+	if !errors.As(err, &locked) {
+		return false
+	}
+	c.Header("Retry-After", strconv.Itoa(int(locked.RetryAfter.Seconds())+1))
+	utils.Error(c, http.StatusTooManyRequests, err.Error())
+	return true
 }
 
 func (h *AuthHandler) Register(c *gin.Context) {
@@ -24,14 +46,19 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.authService.Register(&input)
+	log := utils.GetLoggerFromContext(c)
+	resp, err := h.authService.Register(log, &input, sessionMeta(c))
 	if err != nil {
-		log := utils.GetLoggerFromContext(c)
 		log.LogError("AuthHandler.Register", err, "Registration failed")
 		utils.BadRequest(c, err.Error())
 		return
 	}
 
+	// The account works right away; the email can be verified later
+	if err := h.accountService.SendVerificationEmail(log, resp.User); err != nil {
+		log.LogError("AuthHandler.Register", err, "Failed to send verification email")
+	}
+
 	utils.Success(c, http.StatusCreated, "Registration successful", resp)
 }
 
@@ -42,14 +69,50 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.authService.Login(&input)
+	log := utils.GetLoggerFromContext(c)
+	resp, err := h.authService.Login(log, &input, sessionMeta(c))
 	if err != nil {
-		log := utils.GetLoggerFromContext(c)
 		log.LogWarn("AuthHandler.Login", "Login failed: "+err.Error())
+		if respondLocked(c, err) {
+			return
+		}
 		utils.Unauthorized(c, err.Error())
 		return
 	}
 
+	if resp.TwoFactorRequired {
+		utils.Success(c, http.StatusOK, "Two-factor verification required", resp)
+		return
+	}
+	utils.Success(c, http.StatusOK, "Login successful", resp)
+}
+
+// VerifyTwoFactor completes a login that asked for a two-factor code
+func (h *AuthHandler) VerifyTwoFactor(c *gin.Context) {
+	var input services.TwoFactorLoginInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	log := utils.GetLoggerFromContext(c)
+	resp, err := h.authService.CompleteTwoFactorLogin(log, &input, sessionMeta(c))
+	if err != nil {
+		if respondLocked(c, err) {
+			log.LogWarn("AuthHandler.VerifyTwoFactor", "Two-factor login failed: "+err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrInvalidChallenge) || errors.Is(err, services.ErrInvalidTwoFactorCode) ||
+			errors.Is(err, services.ErrTwoFactorNotEnabled) {
+			log.LogWarn("AuthHandler.VerifyTwoFactor", "Two-factor login failed: "+err.Error())
+			utils.Unauthorized(c, err.Error())
+			return
+		}
+		log.LogError("AuthHandler.VerifyTwoFactor", err, "Two-factor login failed")
+		utils.InternalError(c, err.Error())
+		return
+	}
+
 	utils.Success(c, http.StatusOK, "Login successful", resp)
 }
 
@@ -60,14 +123,27 @@ func (h *AuthHandler) GoogleAuth(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.authService.GoogleAuth(&input)
+	log := utils.GetLoggerFromContext(c)
+	resp, err := h.authService.GoogleAuth(log, &input, sessionMeta(c))
 	if err != nil {
-		log := utils.GetLoggerFromContext(c)
-		log.LogError("AuthHandler.GoogleAuth", err, "Google auth failed")
-		utils.BadRequest(c, err.Error())
+		switch {
+		case errors.Is(err, services.ErrInvalidGoogleToken):
+			log.LogWarn("AuthHandler.GoogleAuth", "Rejected Google token: "+err.Error())
+			utils.Unauthorized(c, services.ErrInvalidGoogleToken.Error())
+		case errors.Is(err, services.ErrGoogleEmailNotVerified):
+			log.LogWarn("AuthHandler.GoogleAuth", "Google auth failed: "+err.Error())
+			utils.Unauthorized(c, err.Error())
+		default:
+			log.LogError("AuthHandler.GoogleAuth", err, "Google auth failed")
+			utils.BadRequest(c, err.Error())
+		}
 		return
 	}
 
+	if resp.TwoFactorRequired {
+		utils.Success(c, http.StatusOK, "Two-factor verification required", resp)
+		return
+	}
 	utils.Success(c, http.StatusOK, "Authentication successful", resp)
 }
 
@@ -130,3 +130,81 @@ func (h *DonationHandler) GetStats(c *gin.Context) {
 		"daily":   dailyStats,
 	})
 }
+
+// GetPendingAlerts lists paid donations waiting for the creator's approval
+func (h *DonationHandler) GetPendingAlerts(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	donations, total, err := h.donationService.GetPendingAlerts(userID.(uuid.UUID), page, limit)
+	if err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("DonationHandler.GetPendingAlerts", err, "Failed to get pending alerts")
+		utils.InternalError(c, "Failed to get pending alerts")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "", gin.H{
+		"donations":   donations,
+		"total":       total,
+		"page":        page,
+		"limit":       limit,
+		"total_pages": (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
+// ApproveAlert approves a queued donation and shows it on the overlay
+func (h *DonationHandler) ApproveAlert(c *gin.Context) {
+	log := utils.GetLoggerFromContext(c)
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid donation ID")
+		return
+	}
+
+	donation, err := h.donationService.ApproveAlert(log, userID.(uuid.UUID), id, userID.(uuid.UUID))
+	if err != nil {
+		log.LogError("DonationHandler.ApproveAlert", err, "Failed to approve")
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Alert approved", donation)
+}
+
+// RejectAlert rejects a queued donation so it is never shown on the overlay
+func (h *DonationHandler) RejectAlert(c *gin.Context) {
+	log := utils.GetLoggerFromContext(c)
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid donation ID")
+		return
+	}
+
+	var input struct {
+		Reason string `json:"reason"`
+	}
+	_ = c.ShouldBindJSON(&input) // Reason is optional
+
+	donation, err := h.donationService.RejectAlert(log, userID.(uuid.UUID), id, userID.(uuid.UUID), input.Reason)
+	if err != nil {
+		log.LogError("DonationHandler.RejectAlert", err, "Failed to reject")
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Alert rejected", donation)
+}
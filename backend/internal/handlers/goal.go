@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/services"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+type GoalHandler struct {
+	goalService *services.GoalService
+}
+
+func NewGoalHandler(goalService *services.GoalService) *GoalHandler {
+	return &GoalHandler{goalService: goalService}
+}
+
+// GetGoals lists the authenticated creator's goals with progress
+func (h *GoalHandler) GetGoals(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	goals, err := h.goalService.GetAll(userID.(uuid.UUID))
+	if err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("GoalHandler.GetGoals", err, "Failed to get goals")
+		utils.InternalError(c, "Failed to get goals")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "", goals)
+}
+
+func (h *GoalHandler) CreateGoal(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var input services.CreateGoalInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	goal, err := h.goalService.Create(userID.(uuid.UUID), &input)
+	if err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("GoalHandler.CreateGoal", err, "Failed to create")
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "Goal created", goal)
+}
+
+func (h *GoalHandler) UpdateGoal(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid goal ID")
+		return
+	}
+
+	var input services.UpdateGoalInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	log := utils.GetLoggerFromContext(c)
+	goal, err := h.goalService.Update(log, userID.(uuid.UUID), id, &input)
+	if err != nil {
+		log.LogError("GoalHandler.UpdateGoal", err, "Failed to update")
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Goal updated", goal)
+}
+
+func (h *GoalHandler) DeleteGoal(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid goal ID")
+		return
+	}
+
+	if err := h.goalService.Delete(userID.(uuid.UUID), id); err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("GoalHandler.DeleteGoal", err, "Failed to delete")
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Goal deleted", nil)
+}
+
+// GetPublicGoal returns the active goal for a creator page (public)
+func (h *GoalHandler) GetPublicGoal(c *gin.Context) {
+	username := c.Param("username")
+
+	goal, err := h.goalService.GetActiveByUsername(username)
+	if err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "", goal.ToProgress())
+}
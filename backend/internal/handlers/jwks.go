@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+type JWKSHandler struct {
+	keys *utils.Keyring
+}
+
+func NewJWKSHandler(keys *utils.Keyring) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// GetJWKS publishes the public keys that verify access tokens. It is a
+// plain JWK Set rather than the usual response envelope so standard JWT
+// libraries can consume it.
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/services"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+type LeaderboardHandler struct {
+	leaderboardService *services.LeaderboardService
+}
+
+func NewLeaderboardHandler(leaderboardService *services.LeaderboardService) *LeaderboardHandler {
+	return &LeaderboardHandler{leaderboardService: leaderboardService}
+}
+
+// GetPublicLeaderboard returns the top supporters for a creator (public)
+func (h *LeaderboardHandler) GetPublicLeaderboard(c *gin.Context) {
+	username := c.Param("username")
+	period := c.DefaultQuery("period", models.LeaderboardAllTime)
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+	if !services.IsValidLeaderboardPeriod(period) {
+		utils.BadRequest(c, "Invalid period, use all, month, week or session")
+		return
+	}
+
+	board, err := h.leaderboardService.GetLeaderboardByUsername(username, period, limit)
+	if err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "", board)
+}
+
+func (h *LeaderboardHandler) GetSettings(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	settings, err := h.leaderboardService.GetSettings(userID.(uuid.UUID))
+	if err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("LeaderboardHandler.GetSettings", err, "Failed to get settings")
+		utils.InternalError(c, "Failed to get leaderboard settings")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "", settings)
+}
+
+func (h *LeaderboardHandler) UpdateSettings(c *gin.Context) {
+	log := utils.GetLoggerFromContext(c)
+	userID, _ := c.Get("user_id")
+
+	var input services.UpdateLeaderboardSettingsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	settings, err := h.leaderboardService.UpdateSettings(log, userID.(uuid.UUID), &input)
+	if err != nil {
+		log.LogError("LeaderboardHandler.UpdateSettings", err, "Failed to update")
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Leaderboard settings updated", settings)
+}
+
+// StartSession starts a new stream session, resetting the session leaderboard
+func (h *LeaderboardHandler) StartSession(c *gin.Context) {
+	log := utils.GetLoggerFromContext(c)
+	userID, _ := c.Get("user_id")
+
+	settings, err := h.leaderboardService.StartSession(log, userID.(uuid.UUID))
+	if err != nil {
+		log.LogError("LeaderboardHandler.StartSession", err, "Failed to start session")
+		utils.InternalError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Stream session started", settings)
+}
@@ -4,93 +4,361 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
 	"github.com/jajanin/backend/internal/services"
 	"github.com/jajanin/backend/internal/utils"
 )
 
 type OverlayHandler struct {
-	alertService *services.AlertService
-	userService  *services.UserService
+	alertService        *services.AlertService
+	donationService     *services.DonationService
+	userService         *services.UserService
+	overlayTokenService *services.OverlayTokenService
+	assetService        *services.AssetService
+	profileService      *services.OverlayProfileService
+	goalService         *services.GoalService
+	leaderboardService  *services.LeaderboardService
+	subathonService     *services.SubathonService
+	pollService         *services.PollService
+	tickerService       *services.TickerService
+	hub                 *services.StreamHub
+	presence            *services.OverlayPresence
 }
 
-func NewOverlayHandler(alertService *services.AlertService, userService *services.UserService) *OverlayHandler {
+func NewOverlayHandler(
+	alertService *services.AlertService,
+	donationService *services.DonationService,
+	userService *services.UserService,
+	overlayTokenService *services.OverlayTokenService,
+	assetService *services.AssetService,
+	profileService *services.OverlayProfileService,
+	goalService *services.GoalService,
+	leaderboardService *services.LeaderboardService,
+	subathonService *services.SubathonService,
+	pollService *services.PollService,
+	tickerService *services.TickerService,
+	hub *services.StreamHub,
+	presence *services.OverlayPresence,
+) *OverlayHandler {
 	return &OverlayHandler{
-		alertService: alertService,
-		userService:  userService,
+		alertService:        alertService,
+		donationService:     donationService,
+		userService:         userService,
+		overlayTokenService: overlayTokenService,
+		assetService:        assetService,
+		profileService:      profileService,
+		goalService:         goalService,
+		leaderboardService:  leaderboardService,
+		subathonService:     subathonService,
+		pollService:         pollService,
+		tickerService:       tickerService,
+		hub:                 hub,
+		presence:            presence,
 	}
 }
 
-func (h *OverlayHandler) AlertStream(c *gin.Context) {
-	log := utils.GetLoggerFromContext(c)
-	streamKey := c.Param("streamKey")
+func setSSEHeaders(c *gin.Context) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("Access-Control-Allow-Origin", "*")
+	c.Header("X-Accel-Buffering", "no")
+}
+
+// sseWriteTimeout bounds each write so a stalled client can't hold the
+// stream goroutine
+const sseWriteTimeout = 10 * time.Second
+
+// writeSSE writes and flushes raw SSE data. An error means the client is
+// gone (or too slow) and the stream must end.
+func writeSSE(c *gin.Context, data []byte) error {
+	rc := http.NewResponseController(c.Writer)
+	rc.SetWriteDeadline(time.Now().Add(sseWriteTimeout)) // not supported by test recorders, ignored
 
-	// Validate stream key and get user
-	user, err := h.userService.GetByStreamKey(streamKey)
+	if _, err := c.Writer.Write(data); err != nil {
+		return err
+	}
+	if err := rc.Flush(); err != nil {
+		return err
+	}
+	rc.SetWriteDeadline(time.Time{})
+	return nil
+}
+
+// writeEvent writes an overlay event envelope as an unnamed SSE message.
+// Clients read it with onmessage and switch on the envelope's type.
+func writeEvent(c *gin.Context, event *services.OverlayEvent) error {
+	data, _ := json.Marshal(event)
+	return writeSSE(c, []byte(fmt.Sprintf("id: %s\ndata: %s\n\n", event.ID, data)))
+}
+
+// resolveOverlay authenticates the overlay token (or legacy stream key) in
+// the URL for the given widget
+func (h *OverlayHandler) resolveOverlay(c *gin.Context, widget string) (*models.User, bool) {
+	user, err := h.overlayTokenService.Resolve(c.Param("token"), widget)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Invalid stream key"})
-		return
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invalid overlay token"})
+		return nil, false
 	}
+	return user, true
+}
 
-	username := ""
-	if user.Username != nil {
-		username = *user.Username
+// resolveAlertOverlay authenticates an alert overlay and returns the alert
+// settings of the profile behind the token, with signed asset URLs
+func (h *OverlayHandler) resolveAlertOverlay(c *gin.Context) (*models.User, *models.AlertSettings, bool) {
+	user, token, err := h.overlayTokenService.ResolveToken(c.Param("token"), models.OverlayWidgetAlert)
+	if err != nil {
+		return nil, nil, false
 	}
 
-	c.Header("Content-Type", "text/event-stream")
-	c.Header("Cache-Control", "no-cache")
-	c.Header("Connection", "keep-alive")
-	c.Header("Access-Control-Allow-Origin", "*")
-	c.Header("X-Accel-Buffering", "no")
+	settings := h.profileService.SettingsFor(user, token)
+	h.assetService.ResolveSettingsURLs(user.ID, settings)
+	return user, settings, true
+}
 
-	alertChan := make(chan *services.AlertData, 10)
-	// Register using user ID to ensure uniqueness
-	userKey := user.ID.String()
-	h.alertService.Register(userKey, alertChan)
+// stillAuthorized re-checks the overlay credential so revoked tokens and
+// expired stream keys are disconnected on the next heartbeat
+func (h *OverlayHandler) stillAuthorized(c *gin.Context, widget string) bool {
+	return h.overlayTokenService.StillValid(c.Param("token"), widget)
+}
 
-	defer func() {
-		h.alertService.Unregister(userKey, alertChan)
-	}()
+// streamTopic serves a widget SSE stream from the StreamHub. The initial
+// event (if any) is sent right after connecting so widgets can render
+// without waiting for the next donation.
+func (h *OverlayHandler) streamTopic(c *gin.Context, widget, topic string, userID uuid.UUID, initial *services.OverlayEvent) {
+	sub := h.hub.Subscribe(topic, userID)
+	defer h.hub.Unsubscribe(sub)
 
-	c.SSEvent("connected", gin.H{"message": "Connected to alert stream", "username": username})
-	c.Writer.Flush()
+	h.serveStream(c, widget, topic, userID, sub, nil, initial)
+}
+
+// serveStream writes the subscriber's events as SSE until the client leaves,
+// is evicted or loses access. prepare (optional) adjusts each event for this
+// connection; returning false ends the stream.
+func (h *OverlayHandler) serveStream(c *gin.Context, widget, topic string, userID uuid.UUID, sub *services.Subscriber,
+	prepare func(*services.OverlayEvent) (*services.OverlayEvent, bool), initial ...*services.OverlayEvent) {
+	log := utils.GetLoggerFromContext(c)
+
+	setSSEHeaders(c)
+
+	sessionID := h.presence.Connect(userID, widget, c.Request.UserAgent())
+	defer h.presence.Disconnect(userID, sessionID)
+
+	if err := writeEvent(c, services.NewOverlayEvent(services.EventConnected, gin.H{"message": "Connected to " + topic + " stream", "session_id": sessionID})); err != nil {
+		return
+	}
+	for _, event := range initial {
+		if event == nil {
+			continue
+		}
+		if err := writeEvent(c, event); err != nil {
+			return
+		}
+	}
 
-	log.Info().Str("user", username).Str("user_id", userKey).Msg("SSE client connected")
+	log.Info().Str("topic", topic).Str("user_id", userID.String()).Msg("SSE client connected")
 
 	heartbeat := time.NewTicker(30 * time.Second)
 	defer heartbeat.Stop()
 
 	for {
 		select {
-		case alert, ok := <-alertChan:
-			if !ok {
+		case event := <-sub.Events:
+			if prepare != nil {
+				var ok bool
+				if event, ok = prepare(event); !ok {
+					return
+				}
+			}
+			if err := writeEvent(c, event); err != nil {
+				log.Info().Str("topic", topic).Str("user_id", userID.String()).Err(err).Msg("SSE write failed")
 				return
 			}
-			data, _ := json.Marshal(alert)
-			c.Writer.Write([]byte(fmt.Sprintf("event: alert\ndata: %s\n\n", data)))
-			c.Writer.Flush()
+
+		case <-sub.Done():
+			log.Info().Str("topic", topic).Str("user_id", userID.String()).Msg("SSE client evicted, connection limit reached")
+			return
 
 		case <-heartbeat.C:
-			c.Writer.Write([]byte(": heartbeat\n\n"))
-			c.Writer.Flush()
+			if !h.stillAuthorized(c, widget) {
+				log.Info().Str("topic", topic).Str("user_id", userID.String()).Msg("SSE client token revoked")
+				return
+			}
+			h.presence.Heartbeat(userID, sessionID)
+			if err := writeSSE(c, []byte(": heartbeat\n\n")); err != nil {
+				log.Info().Str("topic", topic).Str("user_id", userID.String()).Err(err).Msg("SSE write failed")
+				return
+			}
 
 		case <-c.Request.Context().Done():
-			log.Info().Str("user", username).Msg("SSE client disconnected")
+			log.Info().Str("topic", topic).Str("user_id", userID.String()).Msg("SSE client disconnected")
 			return
 		}
 	}
 }
 
+func (h *OverlayHandler) AlertStream(c *gin.Context) {
+	user, settings, ok := h.resolveAlertOverlay(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invalid overlay token"})
+		return
+	}
+
+	sub := h.alertService.Subscribe(user.ID)
+	defer h.alertService.Unsubscribe(sub)
+
+	// Settings depend on the profile bound to this token, so each
+	// connection resolves its own copy
+	resolveSettings := func(event *services.OverlayEvent) (*services.OverlayEvent, bool) {
+		if event.Type != services.EventSettingsChanged {
+			return event, true
+		}
+		_, settings, ok := h.resolveAlertOverlay(c)
+		if !ok {
+			return nil, false
+		}
+		return event.WithData(settings), true
+	}
+
+	h.serveStream(c, models.OverlayWidgetAlert, "alert", user.ID, sub, resolveSettings,
+		services.NewOverlayEvent(services.EventSettingsChanged, settings))
+}
+
+type AckAlertInput struct {
+	DonationID uuid.UUID `json:"donation_id" binding:"required"`
+}
+
+// AckAlert is called by the alert overlay once an alert has been displayed
+func (h *OverlayHandler) AckAlert(c *gin.Context) {
+	user, ok := h.resolveOverlay(c, models.OverlayWidgetAlert)
+	if !ok {
+		return
+	}
+
+	var input AckAlertInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.donationService.AckAlert(user.ID, input.DonationID); err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("OverlayHandler.AckAlert", err, "Failed to acknowledge alert")
+		utils.InternalError(c, "Failed to acknowledge alert")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "", nil)
+}
+
+// GetAlertSettings returns alert settings for the alert overlay (read-only)
+func (h *OverlayHandler) GetAlertSettings(c *gin.Context) {
+	_, settings, ok := h.resolveAlertOverlay(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invalid overlay token"})
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "", settings)
+}
+
+// GoalStream pushes goal progress to the goal overlay widget
+func (h *OverlayHandler) GoalStream(c *gin.Context) {
+	user, ok := h.resolveOverlay(c, models.OverlayWidgetGoal)
+	if !ok {
+		return
+	}
+
+	var initial *services.OverlayEvent
+	if goal, err := h.goalService.GetActive(user.ID); err == nil {
+		initial = services.NewOverlayEvent(services.EventGoalProgress, goal.ToProgress())
+	}
+
+	h.streamTopic(c, models.OverlayWidgetGoal, services.TopicGoal, user.ID, initial)
+}
+
+// LeaderboardStream pushes the top supporters board to the leaderboard overlay
+func (h *OverlayHandler) LeaderboardStream(c *gin.Context) {
+	period := c.DefaultQuery("period", models.LeaderboardAllTime)
+
+	if !services.IsValidLeaderboardPeriod(period) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid period"})
+		return
+	}
+
+	user, ok := h.resolveOverlay(c, models.OverlayWidgetLeaderboard)
+	if !ok {
+		return
+	}
+
+	var initial *services.OverlayEvent
+	if board, err := h.leaderboardService.GetLeaderboard(user.ID, period, services.LeaderboardOverlaySize); err == nil {
+		initial = services.NewOverlayEvent(services.EventLeaderboard, board)
+	}
+
+	h.streamTopic(c, models.OverlayWidgetLeaderboard, services.LeaderboardTopic(period), user.ID, initial)
+}
+
+// TimerStream pushes the subathon timer state to the timer overlay. The
+// overlay counts down locally from ends_at and resyncs on every event.
+func (h *OverlayHandler) TimerStream(c *gin.Context) {
+	user, ok := h.resolveOverlay(c, models.OverlayWidgetTimer)
+	if !ok {
+		return
+	}
+
+	var initial *services.OverlayEvent
+	if timer, err := h.subathonService.Get(user.ID); err == nil {
+		initial = services.NewOverlayEvent(services.EventTimer, timer.ToState(time.Now()))
+	}
+
+	h.streamTopic(c, models.OverlayWidgetTimer, services.TopicTimer, user.ID, initial)
+}
+
+// PollStream pushes live poll results to the poll overlay
+func (h *OverlayHandler) PollStream(c *gin.Context) {
+	user, ok := h.resolveOverlay(c, models.OverlayWidgetPoll)
+	if !ok {
+		return
+	}
+
+	var initial *services.OverlayEvent
+	if poll, err := h.pollService.GetOpen(user.ID); err == nil {
+		initial = services.NewOverlayEvent(services.EventPoll, poll.ToResults())
+	}
+
+	h.streamTopic(c, models.OverlayWidgetPoll, services.TopicPoll, user.ID, initial)
+}
+
+// TickerStream pushes the recent supporters ticker: a snapshot on connect,
+// then one ticker_item per new donation
+func (h *OverlayHandler) TickerStream(c *gin.Context) {
+	user, ok := h.resolveOverlay(c, models.OverlayWidgetTicker)
+	if !ok {
+		return
+	}
+
+	var initial *services.OverlayEvent
+	if ticker, err := h.tickerService.GetTicker(user.ID); err == nil {
+		initial = services.NewOverlayEvent(services.EventTicker, ticker)
+	}
+
+	h.streamTopic(c, models.OverlayWidgetTicker, services.TopicTicker, user.ID, initial)
+}
+
+// TestAlert sends a test alert to the authenticated creator's overlays
 func (h *OverlayHandler) TestAlert(c *gin.Context) {
 	log := utils.GetLoggerFromContext(c)
-	streamKey := c.Param("streamKey")
+	userID, _ := c.Get("user_id")
 
-	// Validate stream key and get user
-	user, err := h.userService.GetByStreamKey(streamKey)
+	user, err := h.userService.GetByID(userID.(uuid.UUID))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Invalid stream key"})
+		utils.NotFound(c, "User not found")
 		return
 	}
 
@@ -108,7 +376,7 @@ func (h *OverlayHandler) TestAlert(c *gin.Context) {
 		CreatorName:   username,
 	}
 
-	h.alertService.Broadcast(log, userKey, alert)
+	h.alertService.Publish(log, userKey, services.NewOverlayEvent(services.EventTest, alert))
 
 	c.JSON(http.StatusOK, gin.H{
 		"success":      true,
@@ -116,3 +384,92 @@ func (h *OverlayHandler) TestAlert(c *gin.Context) {
 		"client_count": h.alertService.GetClientCount(userKey),
 	})
 }
+
+// GetTokens lists the creator's active overlay tokens
+func (h *OverlayHandler) GetTokens(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	tokens, err := h.overlayTokenService.GetAll(userID.(uuid.UUID))
+	if err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("OverlayHandler.GetTokens", err, "Failed to get tokens")
+		utils.InternalError(c, "Failed to get overlay tokens")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "", tokens)
+}
+
+// CreateToken creates a labelled overlay token scoped to one widget
+func (h *OverlayHandler) CreateToken(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var input services.CreateOverlayTokenInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	resp, err := h.overlayTokenService.Create(userID.(uuid.UUID), &input)
+	if err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("OverlayHandler.CreateToken", err, "Failed to create token")
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "Overlay token created", resp)
+}
+
+// RevokeToken revokes an overlay token; connected overlays drop on the next heartbeat
+func (h *OverlayHandler) RevokeToken(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid token ID")
+		return
+	}
+
+	if err := h.overlayTokenService.Revoke(userID.(uuid.UUID), id); err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("OverlayHandler.RevokeToken", err, "Failed to revoke token")
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Overlay token revoked", nil)
+}
+
+// OverlayStatus is the dashboard view of connected overlays and recent alert delivery
+type OverlayStatus struct {
+	Connections []services.OverlaySession `json:"connections"`
+	Deliveries  []models.AlertDelivery    `json:"deliveries"`
+	Stream      services.StreamStats      `json:"stream"` // evicted connections and dropped events since the server started
+}
+
+// GetStatus shows the creator's live overlay connections and the delivery
+// state of their most recent donations
+func (h *OverlayHandler) GetStatus(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uuid.UUID)
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	deliveries, err := h.donationService.GetRecentDeliveries(uid, limit)
+	if err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("OverlayHandler.GetStatus", err, "Failed to get deliveries")
+		utils.InternalError(c, "Failed to get overlay status")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "", OverlayStatus{
+		Connections: h.presence.List(uid),
+		Deliveries:  deliveries,
+		Stream:      h.alertService.Stats(uid),
+	})
+}
@@ -0,0 +1,170 @@
+// Self-contained alert overlay served by the backend (see OverlayHandler.ViewAlert).
+// Reads its config from the embedded JSON block, listens to the alert SSE stream
+// and acknowledges every displayed donation.
+(function () {
+    'use strict';
+
+    var config = JSON.parse(document.getElementById('overlay-config').textContent);
+    var settings = config.settings;
+
+    var FONT_SIZES = {
+        small: { supporter: '18px', amount: '22px', message: '14px' },
+        medium: { supporter: '22px', amount: '28px', message: '16px' },
+        large: { supporter: '26px', amount: '34px', message: '18px' }
+    };
+
+    // Synthesized with Web Audio so the overlay needs no external sound files
+    var SOUNDS = {
+        default: [660, 880],
+        coin: [988, 1319],
+        bell: [523, 659, 784],
+        chime: [784, 988, 1175]
+    };
+
+    var box = document.getElementById('alert');
+    var jajanEl = box.querySelector('.alert-jajan');
+    var fromEl = box.querySelector('.alert-from');
+    var pollEl = box.querySelector('.alert-poll');
+    var messageEl = box.querySelector('.alert-message');
+    var imageEl = box.querySelector('.alert-image');
+
+    function applySettings(next) {
+        settings = next;
+
+        var root = document.documentElement.style;
+        var sizes = FONT_SIZES[settings.font_size] || FONT_SIZES.medium;
+        root.setProperty('--bg-color', settings.background_color);
+        root.setProperty('--text-color', settings.text_color);
+        root.setProperty('--accent-color', settings.accent_color);
+        root.setProperty('--supporter-size', sizes.supporter);
+        root.setProperty('--amount-size', sizes.amount);
+        root.setProperty('--message-size', sizes.message);
+
+        box.className = box.className.replace(/animation-\S+/, 'animation-' + settings.animation);
+
+        imageEl.hidden = !settings.image_url;
+        if (settings.image_url) {
+            imageEl.src = settings.image_url;
+        } else {
+            imageEl.removeAttribute('src');
+        }
+    }
+
+    document.documentElement.style.setProperty('--font-family', config.font_family);
+    applySettings(settings);
+
+    var queue = [];
+    var showing = false;
+    var audioCtx = null;
+
+    function formatAmount(amount) {
+        return new Intl.NumberFormat('id-ID', {
+            style: 'currency',
+            currency: 'IDR',
+            minimumFractionDigits: 0
+        }).format(amount);
+    }
+
+    function jajanText(alert) {
+        if (alert.product_name) {
+            return (alert.product_emoji || '🍽️') + ' Jajanin ' + (alert.quantity || 1) + ' ' + alert.product_name;
+        }
+        return formatAmount(alert.amount);
+    }
+
+    function playSound() {
+        if (!settings.sound_enabled) return;
+
+        // Uploaded custom sound takes precedence over the synthesized presets
+        if (settings.sound_url) {
+            var audio = new Audio(settings.sound_url);
+            audio.volume = Math.max(0, Math.min(100, settings.sound_volume)) / 100;
+            audio.play().catch(function () {});
+            return;
+        }
+
+        try {
+            audioCtx = audioCtx || new (window.AudioContext || window.webkitAudioContext)();
+            var notes = SOUNDS[settings.sound_file] || SOUNDS.default;
+            var volume = Math.max(0, Math.min(100, settings.sound_volume)) / 100;
+            notes.forEach(function (freq, i) {
+                var start = audioCtx.currentTime + i * 0.15;
+                var osc = audioCtx.createOscillator();
+                var gain = audioCtx.createGain();
+                osc.frequency.value = freq;
+                gain.gain.setValueAtTime(volume * 0.3, start);
+                gain.gain.exponentialRampToValueAtTime(0.001, start + 0.4);
+                osc.connect(gain).connect(audioCtx.destination);
+                osc.start(start);
+                osc.stop(start + 0.4);
+            });
+        } catch (e) {
+            // Audio unavailable - alerts still show
+        }
+    }
+
+    function ack(alert) {
+        if (!alert.donation_id) return; // test alerts are not tracked
+        fetch(config.ack_url, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ donation_id: alert.donation_id })
+        }).catch(function () {});
+    }
+
+    function showNext() {
+        var alert = queue.shift();
+        if (!alert) {
+            showing = false;
+            return;
+        }
+        showing = true;
+
+        jajanEl.textContent = jajanText(alert);
+        fromEl.textContent = 'dari ' + alert.supporter_name;
+        pollEl.hidden = !alert.poll_option;
+        pollEl.textContent = alert.poll_option ? 'Vote: ' + alert.poll_option : '';
+        messageEl.hidden = !alert.message;
+        messageEl.textContent = alert.message ? '"' + alert.message + '"' : '';
+
+        box.hidden = false;
+        box.classList.remove('alert-idle', 'alert-hide');
+        box.classList.add('alert-show');
+        playSound();
+        ack(alert);
+
+        setTimeout(function () {
+            box.classList.remove('alert-show');
+            box.classList.add('alert-hide');
+            setTimeout(function () {
+                box.hidden = true;
+                showNext();
+            }, 500); // wait for the exit animation
+        }, settings.duration * 1000);
+    }
+
+    var source = new EventSource(config.stream_url);
+
+    // Every message is an event envelope: {v, id, type, timestamp, data}.
+    // Unknown types are ignored so new events don't break old overlays.
+    source.onmessage = function (message) {
+        var event;
+        try {
+            event = JSON.parse(message.data);
+        } catch (e) {
+            return; // malformed event - ignore
+        }
+
+        switch (event.type) {
+            case 'settings_changed':
+                // Sent on every (re)connect and whenever the settings are saved
+                if (event.data) applySettings(event.data);
+                break;
+            case 'donation':
+            case 'test':
+                queue.push(event.data);
+                if (!showing) showNext();
+                break;
+        }
+    };
+})();
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/services"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+type OverlayProfileHandler struct {
+	profileService *services.OverlayProfileService
+}
+
+func NewOverlayProfileHandler(profileService *services.OverlayProfileService) *OverlayProfileHandler {
+	return &OverlayProfileHandler{profileService: profileService}
+}
+
+// GetProfiles lists the creator's overlay profiles
+func (h *OverlayProfileHandler) GetProfiles(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	profiles, err := h.profileService.GetAll(userID.(uuid.UUID))
+	if err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("OverlayProfileHandler.GetProfiles", err, "Failed to get profiles")
+		utils.InternalError(c, "Failed to get overlay profiles")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "", profiles)
+}
+
+func (h *OverlayProfileHandler) GetProfile(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid profile ID")
+		return
+	}
+
+	profile, err := h.profileService.GetByID(userID.(uuid.UUID), id)
+	if err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "", profile)
+}
+
+func (h *OverlayProfileHandler) CreateProfile(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var input services.CreateOverlayProfileInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	profile, err := h.profileService.Create(userID.(uuid.UUID), &input)
+	if err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("OverlayProfileHandler.CreateProfile", err, "Failed to create")
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "Overlay profile created", profile)
+}
+
+// UpdateProfile renames a profile and/or patches its settings
+func (h *OverlayProfileHandler) UpdateProfile(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid profile ID")
+		return
+	}
+
+	var input services.UpdateOverlayProfileInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	profile, err := h.profileService.Update(utils.GetLoggerFromContext(c), userID.(uuid.UUID), id, &input)
+	if err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("OverlayProfileHandler.UpdateProfile", err, "Failed to update")
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Overlay profile updated", profile)
+}
+
+func (h *OverlayProfileHandler) CloneProfile(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid profile ID")
+		return
+	}
+
+	var input services.CloneOverlayProfileInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			utils.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	profile, err := h.profileService.Clone(userID.(uuid.UUID), id, input.Name)
+	if err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("OverlayProfileHandler.CloneProfile", err, "Failed to clone")
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "Overlay profile cloned", profile)
+}
+
+// SetDefaultProfile copies the profile's settings into the creator's alert
+// settings, used by stream keys and unbound tokens
+func (h *OverlayProfileHandler) SetDefaultProfile(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid profile ID")
+		return
+	}
+
+	profile, err := h.profileService.SetDefault(utils.GetLoggerFromContext(c), userID.(uuid.UUID), id)
+	if err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("OverlayProfileHandler.SetDefaultProfile", err, "Failed to set default")
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Default overlay profile updated", profile)
+}
+
+func (h *OverlayProfileHandler) DeleteProfile(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid profile ID")
+		return
+	}
+
+	if err := h.profileService.Delete(utils.GetLoggerFromContext(c), userID.(uuid.UUID), id); err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("OverlayProfileHandler.DeleteProfile", err, "Failed to delete")
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Overlay profile deleted", nil)
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"html/template"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+// Overlay pages served straight from the backend so OBS browser sources keep
+// working independently of the Next.js frontend
+//
+//go:embed overlay_assets
+var overlayAssets embed.FS
+
+var alertViewTemplate = template.Must(template.ParseFS(overlayAssets, "overlay_assets/alert.html"))
+
+// overlayStaticTypes lists the embedded files exposed under /overlay/static
+var overlayStaticTypes = map[string]string{
+	"alert.js":  "application/javascript; charset=utf-8",
+	"alert.css": "text/css; charset=utf-8",
+}
+
+// overlayAssetVersions holds a content hash per static asset. Pages reference
+// assets as ?v=<hash>, so the files can be cached forever and still update on deploy.
+var overlayAssetVersions = func() map[string]string {
+	versions := make(map[string]string, len(overlayStaticTypes))
+	for name := range overlayStaticTypes {
+		data, err := overlayAssets.ReadFile("overlay_assets/" + name)
+		if err != nil {
+			panic(err)
+		}
+		sum := sha256.Sum256(data)
+		versions[name] = hex.EncodeToString(sum[:])[:12]
+	}
+	return versions
+}()
+
+// overlayFonts maps AlertSettings.FontFamily to a CSS font stack and its Google Fonts stylesheet
+var overlayFonts = map[string]struct {
+	Family string
+	URL    string
+}{
+	"inter":      {"'Inter', system-ui, sans-serif", "https://fonts.googleapis.com/css2?family=Inter:wght@400;600;700;800&display=swap"},
+	"poppins":    {"'Poppins', sans-serif", "https://fonts.googleapis.com/css2?family=Poppins:wght@400;600;700;800&display=swap"},
+	"roboto":     {"'Roboto', sans-serif", "https://fonts.googleapis.com/css2?family=Roboto:wght@400;500;700;900&display=swap"},
+	"montserrat": {"'Montserrat', sans-serif", "https://fonts.googleapis.com/css2?family=Montserrat:wght@400;600;700;800&display=swap"},
+	"comic-sans": {"'Comic Sans MS', cursive", ""},
+}
+
+// overlayViewCSP only allows the embedded assets, Google Fonts and the
+// same-origin SSE/ack endpoints. No inline scripts or styles.
+// Uploaded sounds/images are additionally allowed from assetOrigin.
+func overlayViewCSP(assetOrigin string) string {
+	return "default-src 'none'; " +
+		"script-src 'self'; " +
+		"style-src 'self' https://fonts.googleapis.com; " +
+		"font-src https://fonts.gstatic.com; " +
+		"connect-src 'self'; " +
+		"img-src 'self' data: " + assetOrigin + "; " +
+		"media-src 'self' " + assetOrigin + "; " +
+		"base-uri 'none'; " +
+		"form-action 'none'; " +
+		"frame-ancestors 'none'"
+}
+
+type alertViewConfig struct {
+	Settings   *models.AlertSettings `json:"settings"`
+	FontFamily string                `json:"font_family"`
+	StreamURL  string                `json:"stream_url"`
+	AckURL     string                `json:"ack_url"`
+}
+
+type alertViewData struct {
+	Settings   *models.AlertSettings
+	FontURL    string
+	JSVersion  string
+	CSSVersion string
+	Config     alertViewConfig
+}
+
+// ViewAlert renders the self-contained alert overlay page for OBS
+func (h *OverlayHandler) ViewAlert(c *gin.Context) {
+	_, settings, ok := h.resolveAlertOverlay(c)
+	if !ok {
+		c.Header("Cache-Control", "no-store")
+		c.String(http.StatusNotFound, "Invalid overlay token")
+		return
+	}
+	font, ok := overlayFonts[settings.FontFamily]
+	if !ok {
+		font = overlayFonts["inter"]
+	}
+
+	token := url.PathEscape(c.Param("token"))
+	data := alertViewData{
+		Settings:   settings,
+		FontURL:    font.URL,
+		JSVersion:  overlayAssetVersions["alert.js"],
+		CSSVersion: overlayAssetVersions["alert.css"],
+		Config: alertViewConfig{
+			Settings:   settings,
+			FontFamily: font.Family,
+			StreamURL:  "/overlay/alert/" + token,
+			AckURL:     "/overlay/ack/" + token,
+		},
+	}
+
+	// The token is part of the URL and settings change at any time:
+	// never cache the page or leak it through the Referer header
+	c.Header("Content-Security-Policy", overlayViewCSP(h.assetService.Origin()))
+	c.Header("Cache-Control", "no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	if err := alertViewTemplate.Execute(c.Writer, data); err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("OverlayHandler.ViewAlert", err, "Failed to render overlay page")
+	}
+}
+
+// StaticAsset serves the embedded overlay JS/CSS. Versioned requests are
+// immutable; anything else must be revalidated.
+func (h *OverlayHandler) StaticAsset(c *gin.Context) {
+	name := path.Base(c.Param("file"))
+	contentType, ok := overlayStaticTypes[name]
+	if !ok {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	data, err := overlayAssets.ReadFile("overlay_assets/" + name)
+	if err != nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	version := overlayAssetVersions[name]
+	if c.Query("v") == version {
+		c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		c.Header("Cache-Control", "no-cache")
+	}
+	c.Header("ETag", `"`+version+`"`)
+	c.Header("X-Content-Type-Options", "nosniff")
+
+	if c.GetHeader("If-None-Match") == `"`+version+`"` {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, data)
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/services"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+type PollHandler struct {
+	pollService *services.PollService
+}
+
+func NewPollHandler(pollService *services.PollService) *PollHandler {
+	return &PollHandler{pollService: pollService}
+}
+
+// GetPolls lists the authenticated creator's recent polls
+func (h *PollHandler) GetPolls(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	polls, err := h.pollService.GetAll(userID.(uuid.UUID))
+	if err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("PollHandler.GetPolls", err, "Failed to get polls")
+		utils.InternalError(c, "Failed to get polls")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "", polls)
+}
+
+func (h *PollHandler) CreatePoll(c *gin.Context) {
+	log := utils.GetLoggerFromContext(c)
+	userID, _ := c.Get("user_id")
+
+	var input services.CreatePollInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	poll, err := h.pollService.Create(log, userID.(uuid.UUID), &input)
+	if err != nil {
+		log.LogError("PollHandler.CreatePoll", err, "Failed to create")
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "Poll created", poll)
+}
+
+// ClosePoll freezes the poll results
+func (h *PollHandler) ClosePoll(c *gin.Context) {
+	log := utils.GetLoggerFromContext(c)
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid poll ID")
+		return
+	}
+
+	poll, err := h.pollService.Close(log, userID.(uuid.UUID), id)
+	if err != nil {
+		log.LogError("PollHandler.ClosePoll", err, "Failed to close")
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Poll closed", poll.ToResults())
+}
+
+// GetPublicPoll returns the open poll for the donation page (public)
+func (h *PollHandler) GetPublicPoll(c *gin.Context) {
+	username := c.Param("username")
+
+	poll, err := h.pollService.GetOpenByUsername(username)
+	if err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "", poll.ToResults())
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/services"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+type PrivacyHandler struct {
+	privacyService *services.PrivacyService
+}
+
+func NewPrivacyHandler(privacyService *services.PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{privacyService: privacyService}
+}
+
+// ExportData downloads a ZIP archive of the user's personal data
+func (h *PrivacyHandler) ExportData(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	// Build the archive first so a failure can still be reported as JSON
+	var buf bytes.Buffer
+	if err := h.privacyService.Export(userID.(uuid.UUID), &buf); err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("PrivacyHandler.ExportData", err, "Failed to export user data")
+		utils.InternalError(c, "Failed to export data")
+		return
+	}
+
+	filename := "jajanin-export-" + time.Now().Format("2006-01-02") + ".zip"
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// DeleteAccount erases the user's personal data and closes the account
+func (h *PrivacyHandler) DeleteAccount(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var input services.DeleteAccountInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	log := utils.GetLoggerFromContext(c)
+	if err := h.privacyService.DeleteAccount(c.Request.Context(), log, userID.(uuid.UUID), &input); err != nil {
+		switch {
+		case errors.Is(err, services.ErrDeleteConfirmation),
+			errors.Is(err, services.ErrDeleteEmailMismatch):
+			utils.BadRequest(c, err.Error())
+		case errors.Is(err, services.ErrPendingWithdrawals),
+			errors.Is(err, services.ErrOutstandingBalance),
+			errors.Is(err, services.ErrForfeitBalance):
+			utils.Error(c, http.StatusConflict, err.Error())
+		case errors.Is(err, services.ErrDeleteStaffAccount):
+			utils.Forbidden(c, err.Error())
+		default:
+			log.LogError("PrivacyHandler.DeleteAccount", err, "Failed to delete account")
+			utils.InternalError(c, err.Error())
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Account deleted", nil)
+}
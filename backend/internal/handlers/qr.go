@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/qrcode"
+	"github.com/jajanin/backend/internal/services"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+type QRHandler struct {
+	qrService           *services.QRService
+	overlayTokenService *services.OverlayTokenService
+}
+
+func NewQRHandler(qrService *services.QRService, overlayTokenService *services.OverlayTokenService) *QRHandler {
+	return &QRHandler{
+		qrService:           qrService,
+		overlayTokenService: overlayTokenService,
+	}
+}
+
+// qrSize reads the ?size= query (pixels), clamped to the allowed range
+func qrSize(c *gin.Context) int {
+	size, err := strconv.Atoi(c.Query("size"))
+	if err != nil {
+		return services.DefaultQRSize
+	}
+	return min(max(size, services.MinQRSize), services.MaxQRSize)
+}
+
+// writeQR renders the code as PNG or SVG depending on ?format=
+func writeQR(c *gin.Context, code *qrcode.Code, opts qrcode.RenderOptions, defaultFormat, cacheControl string) {
+	c.Header("Cache-Control", cacheControl)
+	c.Header("X-Content-Type-Options", "nosniff")
+
+	switch c.DefaultQuery("format", defaultFormat) {
+	case "svg":
+		c.Data(http.StatusOK, "image/svg+xml", code.SVG(opts))
+	case "png":
+		data, err := code.PNG(opts)
+		if err != nil {
+			log := utils.GetLoggerFromContext(c)
+			log.LogError("QRHandler", err, "Failed to render QR code")
+			utils.InternalError(c, "Failed to render QR code")
+			return
+		}
+		c.Data(http.StatusOK, "image/png", data)
+	default:
+		utils.BadRequest(c, "Invalid format, use png or svg")
+	}
+}
+
+// PaymentQR renders the QRIS code of a pending donation, so clients don't
+// need their own QR library
+func (h *QRHandler) PaymentQR(c *gin.Context) {
+	code, err := h.qrService.PaymentQR(c.Param("orderID"))
+	if err != nil {
+		if errors.Is(err, services.ErrQRNotAvailable) {
+			utils.Error(c, http.StatusGone, "Payment is no longer pending")
+			return
+		}
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	opts := qrcode.DefaultRenderOptions()
+	opts.Size = qrSize(c)
+	writeQR(c, code, opts, "png", "no-store")
+}
+
+// ProfileQR renders a branded, printable QR code of the creator's donation page (public)
+func (h *QRHandler) ProfileQR(c *gin.Context) {
+	code, err := h.qrService.ProfileQR(c.Param("username"))
+	if err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	writeQR(c, code, services.BrandedQROptions(qrSize(c)), "png", "public, max-age=3600")
+}
+
+// OverlayQR is the stream widget variant of ProfileQR, authenticated with a
+// qr overlay token. ?transparent=1 drops the white background.
+func (h *QRHandler) OverlayQR(c *gin.Context) {
+	user, err := h.overlayTokenService.Resolve(c.Param("token"), models.OverlayWidgetQR)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invalid overlay token"})
+		return
+	}
+
+	code, err := h.qrService.ProfileQRForUser(user)
+	if err != nil {
+		utils.NotFound(c, "Set a username to get a donation page QR code")
+		return
+	}
+
+	opts := services.BrandedQROptions(qrSize(c))
+	if c.Query("transparent") == "1" {
+		opts.Background.A = 0
+	}
+
+	// The token is part of the URL: never cache it or leak it through Referer.
+	// SVG by default as it scales cleanly in OBS.
+	c.Header("Referrer-Policy", "no-referrer")
+	writeQR(c, code, opts, "svg", "no-store")
+}
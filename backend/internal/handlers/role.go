@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/services"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+type RoleHandler struct {
+	permissionService *services.PermissionService
+}
+
+func NewRoleHandler(permissionService *services.PermissionService) *RoleHandler {
+	return &RoleHandler{permissionService: permissionService}
+}
+
+// roleError maps user mistakes to 400/403/404 and the rest to 500
+func roleError(c *gin.Context, location string, err error) {
+	switch {
+	case errors.Is(err, services.ErrUnknownPermission):
+		utils.BadRequest(c, err.Error())
+	case errors.Is(err, services.ErrRoleNotEditable),
+		errors.Is(err, services.ErrOwnRole):
+		utils.Forbidden(c, err.Error())
+	case errors.Is(err, services.ErrRoleNotFound),
+		errors.Is(err, services.ErrUserNotFound):
+		utils.NotFound(c, err.Error())
+	default:
+		log := utils.GetLoggerFromContext(c)
+		log.LogError(location, err, "Role request failed")
+		utils.InternalError(c, err.Error())
+	}
+}
+
+// GetMe returns the signed-in staff member's role and permissions, so the
+// admin panel only shows what they can use
+func (h *RoleHandler) GetMe(c *gin.Context) {
+	value, _ := c.Get("principal")
+	principal := value.(*services.Principal)
+
+	utils.Success(c, http.StatusOK, "", gin.H{
+		"role":        principal.Role,
+		"permissions": principal.Permissions.List(),
+	})
+}
+
+// GetRoles lists every role and the permissions that can be granted
+func (h *RoleHandler) GetRoles(c *gin.Context) {
+	roles, err := h.permissionService.ListRoles()
+	if err != nil {
+		roleError(c, "RoleHandler.GetRoles", err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "", gin.H{
+		"roles":       roles,
+		"permissions": models.Permissions,
+	})
+}
+
+// UpdateRole replaces the permissions of a role
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	var input services.UpdateRoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+	adminID, _ := c.Get("admin_user_id")
+
+	log := utils.GetLoggerFromContext(c)
+	role, err := h.permissionService.UpdateRolePermissions(log, adminID.(uuid.UUID), c.Param("name"), &input)
+	if err != nil {
+		roleError(c, "RoleHandler.UpdateRole", err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Role updated", role)
+}
+
+// SetUserRole assigns a role to a user
+func (h *RoleHandler) SetUserRole(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid user ID")
+		return
+	}
+	var input services.SetUserRoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+	adminID, _ := c.Get("admin_user_id")
+
+	log := utils.GetLoggerFromContext(c)
+	if err := h.permissionService.SetUserRole(log, adminID.(uuid.UUID), userID, &input); err != nil {
+		roleError(c, "RoleHandler.SetUserRole", err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "User role updated", nil)
+}
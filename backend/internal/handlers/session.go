@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/services"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+type SessionHandler struct {
+	sessionService *services.SessionService
+}
+
+func NewSessionHandler(sessionService *services.SessionService) *SessionHandler {
+	return &SessionHandler{sessionService: sessionService}
+}
+
+type RefreshInput struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// Refresh exchanges a refresh token for a new access and refresh token
+func (h *SessionHandler) Refresh(c *gin.Context) {
+	var input RefreshInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	log := utils.GetLoggerFromContext(c)
+	tokens, err := h.sessionService.Refresh(log, input.RefreshToken, sessionMeta(c))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) || errors.Is(err, services.ErrRefreshTokenReused) {
+			utils.Unauthorized(c, err.Error())
+			return
+		}
+		log.LogError("SessionHandler.Refresh", err, "Failed to refresh session")
+		utils.InternalError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Session refreshed", tokens)
+}
+
+// Logout ends the session the request was made with
+func (h *SessionHandler) Logout(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	sessionID, _ := c.Get("session_id")
+
+	err := h.sessionService.Revoke(userID.(uuid.UUID), sessionID.(uuid.UUID), models.SessionRevokedLogout)
+	if err != nil && !errors.Is(err, services.ErrSessionNotFound) {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("SessionHandler.Logout", err, "Failed to log out")
+		utils.InternalError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Logged out", nil)
+}
+
+// LogoutAll ends every session of the user, including this one
+func (h *SessionHandler) LogoutAll(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	count, err := h.sessionService.RevokeAll(userID.(uuid.UUID), models.SessionRevokedLogoutAll)
+	if err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("SessionHandler.LogoutAll", err, "Failed to log out everywhere")
+		utils.InternalError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Logged out from all devices", gin.H{"revoked": count})
+}
+
+// SessionView is an active session in the dashboard list
+type SessionView struct {
+	models.Session
+	Current bool `json:"current"` // the session making this request
+}
+
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	sessionID, _ := c.Get("session_id")
+
+	sessions, err := h.sessionService.List(userID.(uuid.UUID))
+	if err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("SessionHandler.ListSessions", err, "Failed to list sessions")
+		utils.InternalError(c, "Failed to get sessions")
+		return
+	}
+
+	views := make([]SessionView, len(sessions))
+	for i, s := range sessions {
+		views[i] = SessionView{Session: s, Current: s.ID == sessionID}
+	}
+
+	utils.Success(c, http.StatusOK, "", views)
+}
+
+// RevokeSession signs one device out
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid session ID")
+		return
+	}
+
+	if err := h.sessionService.Revoke(userID.(uuid.UUID), id, models.SessionRevokedByUser); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			utils.NotFound(c, err.Error())
+			return
+		}
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("SessionHandler.RevokeSession", err, "Failed to revoke session")
+		utils.InternalError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Session revoked", nil)
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/services"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+type SubathonHandler struct {
+	subathonService *services.SubathonService
+}
+
+func NewSubathonHandler(subathonService *services.SubathonService) *SubathonHandler {
+	return &SubathonHandler{subathonService: subathonService}
+}
+
+func timerResponse(timer *models.SubathonTimer) gin.H {
+	return gin.H{
+		"timer": timer,
+		"state": timer.ToState(time.Now()),
+	}
+}
+
+// GetTimer returns the creator's subathon timer and rules
+func (h *SubathonHandler) GetTimer(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	timer, err := h.subathonService.Get(userID.(uuid.UUID))
+	if err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("SubathonHandler.GetTimer", err, "Failed to get timer")
+		utils.InternalError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "", timerResponse(timer))
+}
+
+func (h *SubathonHandler) UpdateRules(c *gin.Context) {
+	log := utils.GetLoggerFromContext(c)
+	userID, _ := c.Get("user_id")
+
+	var input services.UpdateSubathonRulesInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	timer, err := h.subathonService.UpdateRules(log, userID.(uuid.UUID), &input)
+	if err != nil {
+		log.LogError("SubathonHandler.UpdateRules", err, "Failed to update rules")
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Subathon rules updated", timerResponse(timer))
+}
+
+func (h *SubathonHandler) Start(c *gin.Context) {
+	log := utils.GetLoggerFromContext(c)
+	userID, _ := c.Get("user_id")
+
+	var input services.StartSubathonInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	timer, err := h.subathonService.Start(log, userID.(uuid.UUID), &input)
+	if err != nil {
+		log.LogError("SubathonHandler.Start", err, "Failed to start")
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Subathon started", timerResponse(timer))
+}
+
+func (h *SubathonHandler) Pause(c *gin.Context) {
+	log := utils.GetLoggerFromContext(c)
+	userID, _ := c.Get("user_id")
+
+	timer, err := h.subathonService.Pause(log, userID.(uuid.UUID))
+	if err != nil {
+		log.LogError("SubathonHandler.Pause", err, "Failed to pause")
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Subathon paused", timerResponse(timer))
+}
+
+func (h *SubathonHandler) Resume(c *gin.Context) {
+	log := utils.GetLoggerFromContext(c)
+	userID, _ := c.Get("user_id")
+
+	timer, err := h.subathonService.Resume(log, userID.(uuid.UUID))
+	if err != nil {
+		log.LogError("SubathonHandler.Resume", err, "Failed to resume")
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Subathon resumed", timerResponse(timer))
+}
+
+func (h *SubathonHandler) End(c *gin.Context) {
+	log := utils.GetLoggerFromContext(c)
+	userID, _ := c.Get("user_id")
+
+	timer, err := h.subathonService.End(log, userID.(uuid.UUID))
+	if err != nil {
+		log.LogError("SubathonHandler.End", err, "Failed to end")
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Subathon ended", timerResponse(timer))
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/services"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+type TickerHandler struct {
+	tickerService *services.TickerService
+}
+
+func NewTickerHandler(tickerService *services.TickerService) *TickerHandler {
+	return &TickerHandler{tickerService: tickerService}
+}
+
+func (h *TickerHandler) GetSettings(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	settings, err := h.tickerService.GetSettings(userID.(uuid.UUID))
+	if err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("TickerHandler.GetSettings", err, "Failed to get settings")
+		utils.InternalError(c, "Failed to get ticker settings")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "", settings)
+}
+
+func (h *TickerHandler) UpdateSettings(c *gin.Context) {
+	log := utils.GetLoggerFromContext(c)
+	userID, _ := c.Get("user_id")
+
+	var input services.UpdateTickerSettingsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	settings, err := h.tickerService.UpdateSettings(log, userID.(uuid.UUID), &input)
+	if err != nil {
+		log.LogError("TickerHandler.UpdateSettings", err, "Failed to update")
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Ticker settings updated", settings)
+}
+
+// GetTicker returns the current ticker snapshot (dashboard preview)
+func (h *TickerHandler) GetTicker(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	ticker, err := h.tickerService.GetTicker(userID.(uuid.UUID))
+	if err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("TickerHandler.GetTicker", err, "Failed to get ticker")
+		utils.InternalError(c, "Failed to get ticker")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "", ticker)
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/services"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+type TwoFactorHandler struct {
+	twoFactorService *services.TwoFactorService
+}
+
+func NewTwoFactorHandler(twoFactorService *services.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{twoFactorService: twoFactorService}
+}
+
+// twoFactorError maps user mistakes to 400/403, lockouts to 429 and the
+// rest to 500
+func twoFactorError(c *gin.Context, location string, err error) {
+	if respondLocked(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, services.ErrInvalidTwoFactorCode),
+		errors.Is(err, services.ErrTwoFactorNotEnabled),
+		errors.Is(err, services.ErrTwoFactorAlreadyEnabled),
+		errors.Is(err, services.ErrTwoFactorSetupMissing):
+		utils.BadRequest(c, err.Error())
+	case errors.Is(err, services.ErrTwoFactorMandatory):
+		utils.Forbidden(c, err.Error())
+	default:
+		log := utils.GetLoggerFromContext(c)
+		log.LogError(location, err, "Two-factor request failed")
+		utils.InternalError(c, err.Error())
+	}
+}
+
+// GetStatus returns whether two-factor authentication is on
+func (h *TwoFactorHandler) GetStatus(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	status, err := h.twoFactorService.Status(userID.(uuid.UUID))
+	if err != nil {
+		twoFactorError(c, "TwoFactorHandler.GetStatus", err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "", status)
+}
+
+// Setup creates a new secret and returns its QR code
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	setup, err := h.twoFactorService.Setup(userID.(uuid.UUID))
+	if err != nil {
+		twoFactorError(c, "TwoFactorHandler.Setup", err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Scan the QR code with your authenticator app", setup)
+}
+
+// Enable confirms setup with a code and returns the recovery codes
+func (h *TwoFactorHandler) Enable(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var input services.TwoFactorCodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	codes, err := h.twoFactorService.Enable(userID.(uuid.UUID), &input)
+	if err != nil {
+		twoFactorError(c, "TwoFactorHandler.Enable", err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Two-factor authentication enabled", codes)
+}
+
+// Disable turns two-factor authentication off
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var input services.TwoFactorCodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	log := utils.GetLoggerFromContext(c)
+	if err := h.twoFactorService.Disable(log, userID.(uuid.UUID), &input, sessionMeta(c)); err != nil {
+		twoFactorError(c, "TwoFactorHandler.Disable", err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Two-factor authentication disabled", nil)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var input services.TwoFactorCodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	log := utils.GetLoggerFromContext(c)
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(log, userID.(uuid.UUID), &input, sessionMeta(c))
+	if err != nil {
+		twoFactorError(c, "TwoFactorHandler.RegenerateRecoveryCodes", err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Recovery codes regenerated", codes)
+}
+
+// StepUp re-verifies the user before a sensitive action
+func (h *TwoFactorHandler) StepUp(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	sessionID, _ := c.Get("session_id")
+
+	var input services.TwoFactorCodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	log := utils.GetLoggerFromContext(c)
+	if err := h.twoFactorService.StepUp(log, userID.(uuid.UUID), sessionID.(uuid.UUID), &input, sessionMeta(c)); err != nil {
+		twoFactorError(c, "TwoFactorHandler.StepUp", err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Verified", gin.H{"valid_for_seconds": int(services.StepUpWindow.Seconds())})
+}
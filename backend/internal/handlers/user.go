@@ -5,7 +5,6 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/jajanin/backend/internal/models"
 	"github.com/jajanin/backend/internal/services"
 	"github.com/jajanin/backend/internal/utils"
 )
@@ -93,13 +92,13 @@ func (h *UserHandler) UpdateSocialLinks(c *gin.Context) {
 func (h *UserHandler) UpdateAlertSettings(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 
-	var input models.AlertSettings
-	if err := c.ShouldBindJSON(&input); err != nil {
-		utils.BadRequest(c, err.Error())
+	body, err := c.GetRawData()
+	if err != nil {
+		utils.BadRequest(c, "Invalid request body")
 		return
 	}
 
-	user, err := h.userService.UpdateAlertSettings(userID.(uuid.UUID), &input)
+	user, err := h.userService.UpdateAlertSettings(utils.GetLoggerFromContext(c), userID.(uuid.UUID), body)
 	if err != nil {
 		log := utils.GetLoggerFromContext(c)
 		log.LogError("UserHandler.UpdateAlertSettings", err, "Failed to update")
@@ -110,6 +109,50 @@ func (h *UserHandler) UpdateAlertSettings(c *gin.Context) {
 	utils.Success(c, http.StatusOK, "Alert settings updated", user)
 }
 
+// PatchAlertSettings updates only the alert settings fields sent in the body
+func (h *UserHandler) PatchAlertSettings(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	body, err := c.GetRawData()
+	if err != nil {
+		utils.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	user, err := h.userService.PatchAlertSettings(utils.GetLoggerFromContext(c), userID.(uuid.UUID), body)
+	if err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("UserHandler.PatchAlertSettings", err, "Failed to update")
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Alert settings updated", user)
+}
+
+// UpdateAlertApproval enables or disables the manual approval queue for alerts
+func (h *UserHandler) UpdateAlertApproval(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var input struct {
+		Enabled *bool `json:"enabled" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	user, err := h.userService.UpdateAlertApproval(userID.(uuid.UUID), *input.Enabled)
+	if err != nil {
+		log := utils.GetLoggerFromContext(c)
+		log.LogError("UserHandler.UpdateAlertApproval", err, "Failed to update")
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Alert approval updated", user)
+}
+
 func (h *UserHandler) GetAlertSettings(c *gin.Context) {
 	username := c.Param("username")
 
@@ -136,16 +179,3 @@ func (h *UserHandler) RegenerateStreamKey(c *gin.Context) {
 
 	utils.Success(c, http.StatusOK, "Stream key regenerated", gin.H{"stream_key": newKey})
 }
-
-// GetAlertSettingsByStreamKey returns alert settings for overlay by stream key (public)
-func (h *UserHandler) GetAlertSettingsByStreamKey(c *gin.Context) {
-	streamKey := c.Param("streamKey")
-
-	settings, err := h.userService.GetAlertSettingsByStreamKey(streamKey)
-	if err != nil {
-		utils.NotFound(c, "Invalid stream key")
-		return
-	}
-
-	utils.Success(c, http.StatusOK, "", settings)
-}
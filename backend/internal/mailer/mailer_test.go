@@ -0,0 +1,128 @@
+package mailer
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/mail"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	data := map[string]string{
+		"Name":      "Budi <script>",
+		"URL":       "https://jajan.in/verify-email?token=abc&x=1",
+		"ExpiresIn": "48 jam",
+	}
+
+	for _, name := range []string{TemplateVerifyEmail, TemplateResetPassword, TemplateChangeEmail} {
+		msg, err := Render(name, "budi@example.com", data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if msg.Subject == "" || strings.Contains(msg.Subject, "\n") {
+			t.Errorf("%s: unexpected subject %q", name, msg.Subject)
+		}
+		if !strings.Contains(msg.Text, data["URL"]) || !strings.Contains(msg.Text, "48 jam") {
+			t.Errorf("%s: expected link and expiry in text body", name)
+		}
+		if !strings.Contains(msg.HTML, `href="https://jajan.in/verify-email?token=abc&amp;x=1"`) {
+			t.Errorf("%s: expected escaped link in HTML body", name)
+		}
+		if strings.Contains(msg.HTML, "<script>") {
+			t.Errorf("%s: expected name to be escaped in HTML body", name)
+		}
+	}
+
+	msg, err := Render(TemplateNewLogin, "budi@example.com", map[string]string{
+		"Name":      "Budi",
+		"Device":    "Chrome on Windows",
+		"IPAddress": "203.0.113.7",
+		"Time":      "18 Oct 2026 09:30 WIB",
+		"URL":       "https://jajan.in/dashboard/settings?tab=security",
+		"ResetURL":  "https://jajan.in/forgot-password",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Chrome on Windows", "203.0.113.7", "https://jajan.in/forgot-password"} {
+		if !strings.Contains(msg.Text, want) || !strings.Contains(msg.HTML, want) {
+			t.Errorf("new_login: expected %q in both bodies", want)
+		}
+	}
+
+	msg, err = Render(TemplateEmailChangeRequested, "budi@example.com", map[string]string{
+		"Name":     "Budi",
+		"NewEmail": "budi.baru@example.com",
+		"URL":      "https://jajan.in/dashboard/settings?tab=security",
+		"ResetURL": "https://jajan.in/forgot-password",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(msg.Text, "budi.baru@example.com") || !strings.Contains(msg.HTML, "budi.baru@example.com") {
+		t.Error("email_change_requested: expected the new address in both bodies")
+	}
+
+	if _, err := Render("missing", "budi@example.com", data); err == nil {
+		t.Error("Expected an error for an unknown template")
+	}
+}
+
+func TestFileMailer(t *testing.T) {
+	m, err := NewFileMailer(t.TempDir(), "Jajanin <no-reply@jajan.in>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := &Message{
+		To:      "budi@example.com",
+		Subject: "Halo Budi ☕",
+		Text:    "Baris pertama\nhttps://jajan.in/reset-password?token=" + strings.Repeat("x", 80) + "\n",
+		HTML:    "<p>Halo</p>",
+	}
+	if err := m.Send(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := m.Messages()
+	if err != nil || len(files) != 1 {
+		t.Fatalf("Expected 1 email, got %d (%v)", len(files), err)
+	}
+	f, err := os.Open(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	parsed, err := mail.ReadMessage(f)
+	if err != nil {
+		t.Fatalf("Expected a valid message: %v", err)
+	}
+	if got := parsed.Header.Get("To"); got != msg.To {
+		t.Errorf("Expected To %q, got %q", msg.To, got)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if subject != msg.Subject {
+		t.Errorf("Expected subject %q, got %q", msg.Subject, subject)
+	}
+	if _, err := parsed.Header.Date(); err != nil {
+		t.Errorf("Expected a valid Date header: %v", err)
+	}
+	body, _ := io.ReadAll(parsed.Body)
+	if !strings.Contains(string(body), "text/html") {
+		t.Error("Expected an HTML part")
+	}
+}
+
+func TestEncodeMIME_LineLength(t *testing.T) {
+	msg := &Message{To: "a@example.com", Subject: "s", Text: strings.Repeat("a", 500)}
+	for _, line := range strings.Split(string(encodeMIME("b@example.com", msg, time.Now())), "\r\n") {
+		if len(line) > 78 && !strings.HasPrefix(line, "Content-Type") {
+			t.Fatalf("Line longer than 78 characters: %.40q...", line)
+		}
+	}
+}
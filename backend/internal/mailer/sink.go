@@ -0,0 +1,71 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jajanin/backend/internal/utils"
+)
+
+// LogMailer writes emails to the application log instead of sending them.
+// Meant for local development, where the links in the text body can be
+// copied from the console.
+type LogMailer struct{}
+
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg *Message) error {
+	utils.Log.Info().
+		Str("layer", "mailer").
+		Str("to", msg.To).
+		Str("subject", msg.Subject).
+		Str("body", msg.Text).
+		Msg("Email (not sent, MAIL_DRIVER=log)")
+	return nil
+}
+
+// FileMailer writes each email as an .eml file to a directory, which mail
+// clients can open directly and tests can inspect
+type FileMailer struct {
+	dir  string
+	from string
+	seq  atomic.Int64
+}
+
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if dir == "" {
+		return nil, errors.New("mail file directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg *Message) error {
+	now := time.Now()
+	name := fmt.Sprintf("%s-%04d-%s.eml", now.Format("20060102T150405"), m.seq.Add(1), sanitizeFileName(msg.To))
+	return os.WriteFile(filepath.Join(m.dir, name), encodeMIME(m.from, msg, now), 0o644)
+}
+
+// Messages returns the paths of the written emails, oldest first
+func (m *FileMailer) Messages() ([]string, error) {
+	return filepath.Glob(filepath.Join(m.dir, "*.eml"))
+}
+
+func sanitizeFileName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '@' {
+			return r
+		}
+		return '_'
+	}, s)
+}
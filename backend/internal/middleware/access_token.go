@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/services"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+// AccessTokenNotAllowedMessage is returned when a personal access token is
+// used on a route that needs a signed-in session
+const AccessTokenNotAllowedMessage = "Personal access tokens can't be used for this endpoint"
+
+// AccessTokenAuthenticator resolves a personal access token
+type AccessTokenAuthenticator interface {
+	Authenticate(credential, ip string) (*models.PersonalAccessToken, error)
+}
+
+// TokenAuth guards the routes that creator automation may call. They accept
+// a session JWT like AuthMiddleware, or a personal access token carrying the
+// route's scope. Each token has its own rate limit bucket.
+type TokenAuth struct {
+	sessions SessionValidator
+	tokens   AccessTokenAuthenticator
+	limiter  *RateLimiter
+}
+
+func NewTokenAuth(sessions SessionValidator, tokens AccessTokenAuthenticator, ratePerMinute int) *TokenAuth {
+	return &TokenAuth{
+		sessions: sessions,
+		tokens:   tokens,
+		limiter:  NewRateLimiter(ratePerMinute, time.Minute),
+	}
+}
+
+// Require accepts a session JWT, or a personal access token with scope
+func (a *TokenAuth) Require(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, ok := bearerToken(c)
+		if !ok {
+			return
+		}
+
+		if !services.IsAccessToken(tokenString) {
+			if authenticateSession(c, a.sessions, tokenString) {
+				c.Next()
+			}
+			return
+		}
+
+		token, err := a.tokens.Authenticate(tokenString, c.ClientIP())
+		if err != nil {
+			utils.Unauthorized(c, "Invalid or expired token")
+			c.Abort()
+			return
+		}
+
+		if !a.limiter.allow(token.ID.String()) {
+			utils.Log.Warn().
+				Str("token_id", token.ID.String()).
+				Str("path", c.Request.URL.Path).
+				Msg("Access token rate limit exceeded")
+
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"success": false,
+				"error":   "Too many requests. Please try again later.",
+			})
+			return
+		}
+
+		if !token.HasScope(scope) {
+			utils.Forbidden(c, "Access token is missing the "+scope+" scope")
+			c.Abort()
+			return
+		}
+
+		// No session_id: step-up and session routes stay out of reach
+		c.Set("user_id", token.UserID)
+		c.Set("access_token_id", token.ID)
+		c.Next()
+	}
+}
@@ -3,39 +3,70 @@ package middleware
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/services"
 	"github.com/jajanin/backend/internal/utils"
-	"gorm.io/gorm"
 )
 
-// AdminMiddleware checks if the authenticated user has admin role
-func AdminMiddleware(db *gorm.DB) gin.HandlerFunc {
+// PrincipalResolver looks up a user's role and permissions, usually from a cache
+type PrincipalResolver interface {
+	Principal(userID uuid.UUID) (*services.Principal, error)
+}
+
+// AdminMiddleware lets staff (any role with at least one permission) into
+// the admin routes. Staff must have two-factor authentication turned on.
+// Which routes they may use is checked by RequirePermission.
+func AdminMiddleware(resolver PrincipalResolver) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get user ID from context (set by AuthMiddleware)
-		userID, exists := c.Get("user_id")
-		if !exists {
+		userID, ok := c.Get("user_id")
+		uid, ok2 := userID.(uuid.UUID)
+		if !ok || !ok2 {
 			utils.Forbidden(c, "Access denied")
 			c.Abort()
 			return
 		}
 
-		// Fetch user from database to check role
-		var user models.User
-		if err := db.First(&user, "id = ?", userID.(uuid.UUID)).Error; err != nil {
+		principal, err := resolver.Principal(uid)
+		if err != nil {
 			utils.Forbidden(c, "User not found")
 			c.Abort()
 			return
 		}
 
-		// Check if user is admin
-		if !user.IsAdmin() {
+		if !principal.IsStaff() {
 			utils.Forbidden(c, "Admin access required")
 			c.Abort()
 			return
 		}
 
-		// Set user in context for handler use
-		c.Set("admin_user", user)
+		if !principal.TwoFactorEnabled {
+			utils.Forbidden(c, "Enable two-factor authentication to use the admin panel")
+			c.Abort()
+			return
+		}
+
+		// Set the principal in context for RequirePermission and handlers
+		c.Set("admin_user_id", uid)
+		c.Set("principal", principal)
 		c.Next()
 	}
 }
+
+// RequirePermission allows the request if the staff member has any of perms.
+// Must run after AdminMiddleware.
+func RequirePermission(perms ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get("principal")
+		if principal, ok := value.(*services.Principal); ok {
+			for _, perm := range perms {
+				if principal.Permissions.Has(perm) {
+					c.Next()
+					return
+				}
+			}
+		}
+
+		utils.Forbidden(c, "You don't have permission to do this")
+		c.Abort()
+	}
+}
@@ -4,47 +4,88 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/services"
 	"github.com/jajanin/backend/internal/utils"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// SessionValidator reports whether an access token's session is still active
+type SessionValidator interface {
+	IsActive(sessionID, userID uuid.UUID) bool
+}
+
+// bearerToken returns the credential from the Authorization header, or
+// responds 401 and returns false
+func bearerToken(c *gin.Context) (string, bool) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		utils.Unauthorized(c, "Authorization header required")
+		c.Abort()
+		return "", false
+	}
+
+	// Check Bearer prefix
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		utils.Unauthorized(c, "Invalid authorization header format")
+		c.Abort()
+		return "", false
+	}
+
+	return parts[1], true
+}
+
+// authenticateSession checks a session JWT and sets the user in the context,
+// or responds 401 and returns false
+func authenticateSession(c *gin.Context, sessions SessionValidator, tokenString string) bool {
+	// Validate token
+	claims, err := utils.ValidateToken(tokenString)
+	if err != nil {
+		utils.Unauthorized(c, "Invalid or expired token")
+		c.Abort()
+		return false
+	}
+
+	// Reject tokens whose session was logged out or revoked
+	if !sessions.IsActive(claims.SessionID, claims.UserID) {
+		utils.Unauthorized(c, "Session has been revoked")
+		c.Abort()
+		return false
+	}
+
+	// Set user info in context
+	c.Set("user_id", claims.UserID)
+	c.Set("session_id", claims.SessionID)
+	c.Set("email", claims.Email)
+	c.Set("username", claims.Username)
+	return true
+}
+
+// AuthMiddleware requires a session JWT. Personal access tokens are only
+// accepted on routes guarded by TokenAuth.Require.
+func AuthMiddleware(sessions SessionValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			utils.Unauthorized(c, "Authorization header required")
-			c.Abort()
+		tokenString, ok := bearerToken(c)
+		if !ok {
 			return
 		}
 
-		// Check Bearer prefix
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			utils.Unauthorized(c, "Invalid authorization header format")
+		if services.IsAccessToken(tokenString) {
+			utils.Forbidden(c, AccessTokenNotAllowedMessage)
 			c.Abort()
 			return
 		}
 
-		tokenString := parts[1]
-
-		// Validate token
-		claims, err := utils.ValidateToken(tokenString)
-		if err != nil {
-			utils.Unauthorized(c, "Invalid or expired token")
-			c.Abort()
+		if !authenticateSession(c, sessions, tokenString) {
 			return
 		}
 
-		// Set user info in context
-		c.Set("user_id", claims.UserID)
-		c.Set("email", claims.Email)
-		c.Set("username", claims.Username)
-
 		c.Next()
 	}
 }
 
 // OptionalAuth middleware - doesn't require auth but sets user if present
-func OptionalAuth() gin.HandlerFunc {
+func OptionalAuth(sessions SessionValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -59,12 +100,13 @@ func OptionalAuth() gin.HandlerFunc {
 		}
 
 		claims, err := utils.ValidateToken(parts[1])
-		if err != nil {
+		if err != nil || !sessions.IsActive(claims.SessionID, claims.UserID) {
 			c.Next()
 			return
 		}
 
 		c.Set("user_id", claims.UserID)
+		c.Set("session_id", claims.SessionID)
 		c.Set("email", claims.Email)
 		c.Set("username", claims.Username)
 
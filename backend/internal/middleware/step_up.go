@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+// StepUpRequiredMessage is the 403 message clients look for to ask the user
+// for a two-factor code and retry
+const StepUpRequiredMessage = "two-factor verification required"
+
+// StepUpChecker reports whether the session recently passed a two-factor check
+type StepUpChecker interface {
+	StepUpSatisfied(userID, sessionID uuid.UUID) bool
+}
+
+// RequireStepUp guards sensitive actions of users with two-factor enabled.
+// Must run after AuthMiddleware.
+func RequireStepUp(checker StepUpChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		sessionID, _ := c.Get("session_id")
+
+		uid, ok := userID.(uuid.UUID)
+		sid, ok2 := sessionID.(uuid.UUID)
+		if !ok || !ok2 || !checker.StepUpSatisfied(uid, sid) {
+			utils.Forbidden(c, StepUpRequiredMessage)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Scopes a personal access token can be given
+const (
+	ScopeDonationsRead = "donations:read"
+	ScopeStatsRead     = "stats:read"
+	ScopeAlertsTest    = "alerts:test"
+)
+
+var AccessTokenScopes = []string{
+	ScopeDonationsRead,
+	ScopeStatsRead,
+	ScopeAlertsTest,
+}
+
+// PersonalAccessToken lets a creator's scripts and bots call the API on
+// their behalf, limited to its scopes. Only the SHA-256 hash is stored.
+type PersonalAccessToken struct {
+	ID          uuid.UUID                   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID      uuid.UUID                   `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name        string                      `gorm:"not null" json:"name"` // e.g. "Discord bot"
+	Scopes      datatypes.JSONSlice[string] `gorm:"type:jsonb" json:"scopes"`
+	TokenHash   string                      `gorm:"uniqueIndex;not null" json:"-"`
+	TokenPrefix string                      `gorm:"" json:"token_prefix"` // first characters, to tell tokens apart
+	ExpiresAt   *time.Time                  `gorm:"" json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time                  `gorm:"" json:"last_used_at,omitempty"`
+	LastUsedIP  string                      `gorm:"" json:"last_used_ip,omitempty"`
+	RevokedAt   *time.Time                  `gorm:"" json:"revoked_at,omitempty"`
+	CreatedAt   time.Time                   `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (t *PersonalAccessToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsExpired reports whether the token has passed its expiry date
+func (t *PersonalAccessToken) IsExpired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// HasScope reports whether the token was given scope
+func (t *PersonalAccessToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidAccessTokenScope reports whether scope is a known token scope
+func IsValidAccessTokenScope(scope string) bool {
+	for _, s := range AccessTokenScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -1,13 +1,46 @@
 package models
 
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// AlertSettingsVersion is the schema version written with every save.
+// Bump it and add an entry to alertSettingsMigrations when the schema changes.
+const AlertSettingsVersion = 1
+
+// Allowed alert settings values
+var (
+	AlertThemes     = []string{"default", "custom"}
+	AlertFonts      = []string{"inter", "poppins", "roboto", "montserrat", "comic-sans"}
+	AlertFontSizes  = []string{"small", "medium", "large"}
+	AlertAnimations = []string{"slide", "fade", "bounce", "pop", "zoom"}
+	AlertSounds     = []string{"default", "coin", "bell", "chime"}
+)
+
+const (
+	MinAlertDuration = 3
+	MaxAlertDuration = 10
+)
+
+var hexColorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
 // AlertSettings represents customizable alert box settings
 type AlertSettings struct {
+	Version int `json:"version"` // schema version of the stored JSON
+
 	// Visual Settings
 	Theme           string `json:"theme"`            // "default" or "custom"
 	BackgroundColor string `json:"background_color"` // hex color e.g. "#FE6244"
 	TextColor       string `json:"text_color"`       // hex color e.g. "#FFFFFF"
 	AccentColor     string `json:"accent_color"`     // hex color for amount e.g. "#FBBF24"
-	FontFamily      string `json:"font_family"`      // "inter", "poppins", "roboto", "comic-sans"
+	FontFamily      string `json:"font_family"`      // "inter", "poppins", "roboto", "montserrat", "comic-sans"
 	FontSize        string `json:"font_size"`        // "small", "medium", "large"
 
 	// Animation Settings
@@ -18,11 +51,20 @@ type AlertSettings struct {
 	SoundEnabled bool   `json:"sound_enabled"`
 	SoundFile    string `json:"sound_file"`   // "default", "coin", "bell", "chime"
 	SoundVolume  int    `json:"sound_volume"` // 0-100
+
+	// Uploaded assets (override the preset sound / add an image to the alert)
+	SoundAssetID *uuid.UUID `json:"sound_asset_id,omitempty"`
+	ImageAssetID *uuid.UUID `json:"image_asset_id,omitempty"`
+
+	// Signed asset URLs, filled in when settings are served to overlays (never stored)
+	SoundURL string `json:"sound_url,omitempty"`
+	ImageURL string `json:"image_url,omitempty"`
 }
 
 // DefaultAlertSettings returns the default alert settings
 func DefaultAlertSettings() AlertSettings {
 	return AlertSettings{
+		Version:         AlertSettingsVersion,
 		Theme:           "default",
 		BackgroundColor: "#FE6244",
 		TextColor:       "#FFFFFF",
@@ -36,3 +78,149 @@ func DefaultAlertSettings() AlertSettings {
 		SoundVolume:     50,
 	}
 }
+
+func oneOf(value string, allowed []string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldErrors returns a message per invalid field, keyed by JSON name
+func (s *AlertSettings) fieldErrors() map[string]string {
+	errs := map[string]string{}
+
+	colors := map[string]string{
+		"background_color": s.BackgroundColor,
+		"text_color":       s.TextColor,
+		"accent_color":     s.AccentColor,
+	}
+	for field, color := range colors {
+		if !hexColorPattern.MatchString(color) {
+			errs[field] = "must be a hex color like #FE6244"
+		}
+	}
+
+	enums := []struct {
+		field   string
+		value   string
+		allowed []string
+	}{
+		{"theme", s.Theme, AlertThemes},
+		{"font_family", s.FontFamily, AlertFonts},
+		{"font_size", s.FontSize, AlertFontSizes},
+		{"animation", s.Animation, AlertAnimations},
+		{"sound_file", s.SoundFile, AlertSounds},
+	}
+	for _, e := range enums {
+		if !oneOf(e.value, e.allowed) {
+			errs[e.field] = fmt.Sprintf("must be one of %v", e.allowed)
+		}
+	}
+
+	if s.Duration < MinAlertDuration || s.Duration > MaxAlertDuration {
+		errs["duration"] = fmt.Sprintf("must be between %d and %d seconds", MinAlertDuration, MaxAlertDuration)
+	}
+	if s.SoundVolume < 0 || s.SoundVolume > 100 {
+		errs["sound_volume"] = "must be between 0 and 100"
+	}
+
+	return errs
+}
+
+// Validate checks every field against the schema
+func (s *AlertSettings) Validate() error {
+	errs := s.fieldErrors()
+	if len(errs) == 0 {
+		return nil
+	}
+
+	fields := make([]string, 0, len(errs))
+	for field := range errs {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fmt.Errorf("%s %s", fields[0], errs[fields[0]])
+}
+
+// DecodeAlertSettings strictly decodes a JSON body on top of base: fields
+// missing from the body keep their base value (PATCH semantics) and unknown
+// fields are rejected. The result is validated.
+func DecodeAlertSettings(base AlertSettings, data []byte) (*AlertSettings, error) {
+	settings := base
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&settings); err != nil {
+		return nil, fmt.Errorf("invalid alert settings: %w", err)
+	}
+	if dec.More() {
+		return nil, errors.New("invalid alert settings: unexpected trailing data")
+	}
+
+	// Server-managed fields can't be set by clients
+	settings.Version = AlertSettingsVersion
+	settings.SoundURL = ""
+	settings.ImageURL = ""
+
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// alertSettingsMigrations[n] upgrades stored JSON from version n to n+1
+var alertSettingsMigrations = []func(map[string]interface{}){
+	migrateAlertSettingsV0,
+}
+
+// migrateAlertSettingsV0 cleans settings saved before validation existed
+// (no "version" key): unknown keys and values the schema rejects are dropped
+// so the defaults apply instead.
+func migrateAlertSettingsV0(m map[string]interface{}) {
+	settings := DefaultAlertSettings()
+	for key, value := range m {
+		single, _ := json.Marshal(map[string]interface{}{key: value})
+
+		dec := json.NewDecoder(bytes.NewReader(single))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&settings); err != nil {
+			delete(m, key)
+		}
+	}
+
+	for field := range settings.fieldErrors() {
+		delete(m, field)
+	}
+}
+
+// ParseAlertSettings reads settings stored in the users.alert_settings column,
+// migrating older versions to the current schema. Unreadable JSON yields defaults.
+func ParseAlertSettings(raw []byte) AlertSettings {
+	settings := DefaultAlertSettings()
+	if len(raw) == 0 {
+		return settings
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil || m == nil {
+		return settings
+	}
+
+	version := 0
+	if v, ok := m["version"].(float64); ok {
+		version = int(v)
+	}
+	for ; version < AlertSettingsVersion; version++ {
+		alertSettingsMigrations[version](m)
+	}
+	m["version"] = AlertSettingsVersion
+
+	migrated, _ := json.Marshal(m)
+	if err := json.Unmarshal(migrated, &settings); err != nil {
+		return DefaultAlertSettings()
+	}
+	return settings
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAlertSettings_Validate(t *testing.T) {
+	valid := DefaultAlertSettings()
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Expected defaults to be valid, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*AlertSettings)
+		field  string
+	}{
+		{"bad color", func(s *AlertSettings) { s.BackgroundColor = "red" }, "background_color"},
+		{"short color", func(s *AlertSettings) { s.TextColor = "#FFF" }, "text_color"},
+		{"unknown font", func(s *AlertSettings) { s.FontFamily = "papyrus" }, "font_family"},
+		{"unknown animation", func(s *AlertSettings) { s.Animation = "spin" }, "animation"},
+		{"duration too long", func(s *AlertSettings) { s.Duration = 9999 }, "duration"},
+		{"duration too short", func(s *AlertSettings) { s.Duration = 0 }, "duration"},
+		{"volume too loud", func(s *AlertSettings) { s.SoundVolume = 500 }, "sound_volume"},
+	}
+
+	for _, tt := range tests {
+		s := DefaultAlertSettings()
+		tt.mutate(&s)
+		err := s.Validate()
+		if err == nil || !strings.HasPrefix(err.Error(), tt.field) {
+			t.Errorf("%s: expected %s error, got %v", tt.name, tt.field, err)
+		}
+	}
+}
+
+func TestDecodeAlertSettings_Patch(t *testing.T) {
+	base := DefaultAlertSettings()
+	base.Animation = "pop"
+	base.SoundVolume = 80
+
+	got, err := DecodeAlertSettings(base, []byte(`{"duration": 8}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Duration != 8 || got.Animation != "pop" || got.SoundVolume != 80 {
+		t.Errorf("Expected partial update to keep other fields, got %+v", got)
+	}
+
+	if _, err := DecodeAlertSettings(base, []byte(`{"blink": true}`)); err == nil {
+		t.Error("Expected unknown field to be rejected")
+	}
+	if _, err := DecodeAlertSettings(base, []byte(`{"sound_volume": 500}`)); err == nil {
+		t.Error("Expected out of range volume to be rejected")
+	}
+
+	got, _ = DecodeAlertSettings(base, []byte(`{"version": 99, "sound_url": "https://evil"}`))
+	if got.Version != AlertSettingsVersion || got.SoundURL != "" {
+		t.Errorf("Expected server-managed fields to be ignored, got %+v", got)
+	}
+}
+
+func TestParseAlertSettings_MigratesUnversioned(t *testing.T) {
+	legacy := []byte(`{"theme":"custom","background_color":"#112233","duration":9999,` +
+		`"sound_volume":"loud","font_family":"papyrus","legacy_field":1}`)
+
+	got := ParseAlertSettings(legacy)
+	defaults := DefaultAlertSettings()
+
+	if got.Version != AlertSettingsVersion {
+		t.Errorf("Expected version %d, got %d", AlertSettingsVersion, got.Version)
+	}
+	if got.Theme != "custom" || got.BackgroundColor != "#112233" {
+		t.Errorf("Expected valid legacy values to be kept, got %+v", got)
+	}
+	if got.Duration != defaults.Duration || got.SoundVolume != defaults.SoundVolume || got.FontFamily != defaults.FontFamily {
+		t.Errorf("Expected invalid legacy values to fall back to defaults, got %+v", got)
+	}
+	if err := got.Validate(); err != nil {
+		t.Errorf("Expected migrated settings to be valid, got %v", err)
+	}
+
+	if got := ParseAlertSettings([]byte("not json")); got != defaults {
+		t.Errorf("Expected defaults for unreadable JSON, got %+v", got)
+	}
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Asset kinds
+const (
+	AssetKindSound = "sound"
+	AssetKindImage = "image"
+)
+
+// Asset is a file uploaded by a creator for their alerts (custom sound or image)
+type Asset struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	Kind        string    `gorm:"not null" json:"kind"`
+	Filename    string    `gorm:"" json:"filename"` // original file name, for display
+	ContentType string    `gorm:"not null" json:"content_type"`
+	Size        int64     `gorm:"not null" json:"size"`
+	StorageKey  string    `gorm:"not null" json:"-"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	URL string `gorm:"-" json:"url,omitempty"` // signed URL, filled by the service
+}
+
+// BeforeCreate hook to generate UUID
+func (a *Asset) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// AssetUsage summarizes a creator's upload quota
+type AssetUsage struct {
+	UsedBytes  int64 `json:"used_bytes"`
+	QuotaBytes int64 `json:"quota_bytes"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Audit log actions
+const (
+	AuditLoginSucceeded  = "login.succeeded"
+	AuditLoginFailed     = "login.failed"
+	AuditLoginLocked     = "login.locked" // too many failures, the account is locked for a while
+	AuditLoginNewDevice  = "login.new_device"
+	AuditAccountUnlocked = "account.unlocked"
+	AuditRoleUpdated     = "role.updated"
+	AuditUserRoleChanged = "user.role_changed"
+
+	AuditAccessTokenCreated = "access_token.created"
+	AuditAccessTokenRevoked = "access_token.revoked"
+
+	AuditAccountDeleted       = "account.deleted"
+	AuditPasswordChanged      = "password.changed"
+	AuditEmailChangeRequested = "email.change_requested"
+	AuditEmailChanged         = "email.changed"
+)
+
+// AuditLog records a security relevant event. UserID is the account the
+// event is about, ActorID who caused it when that isn't the user (an admin).
+// Email is kept for failed logins to addresses without an account.
+type AuditLog struct {
+	ID        uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Action    string            `gorm:"not null;index" json:"action"`
+	UserID    *uuid.UUID        `gorm:"type:uuid;index" json:"user_id,omitempty"`
+	ActorID   *uuid.UUID        `gorm:"type:uuid" json:"actor_id,omitempty"`
+	Email     string            `gorm:"index" json:"email,omitempty"`
+	IPAddress string            `gorm:"" json:"ip_address,omitempty"`
+	UserAgent string            `gorm:"" json:"user_agent,omitempty"`
+	Metadata  datatypes.JSONMap `gorm:"type:jsonb" json:"metadata,omitempty"`
+	CreatedAt time.Time         `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (a *AuditLog) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
@@ -16,6 +16,16 @@ const (
 	PaymentStatusExpired PaymentStatus = "expired"
 )
 
+// AlertStatus tracks whether a paid donation has been shown on the overlay
+type AlertStatus string
+
+const (
+	AlertStatusSent          AlertStatus = "sent"           // broadcast immediately after payment
+	AlertStatusPendingReview AlertStatus = "pending_review" // waiting for creator approval
+	AlertStatusApproved      AlertStatus = "approved"       // approved by creator and broadcast
+	AlertStatusRejected      AlertStatus = "rejected"       // never shown, money still credited
+)
+
 type Donation struct {
 	ID            uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	CreatorID     uuid.UUID     `gorm:"type:uuid;not null;index" json:"creator_id"`
@@ -29,17 +39,85 @@ type Donation struct {
 	PaymentID     string        `gorm:"" json:"payment_id,omitempty"`
 	PaymentStatus PaymentStatus `gorm:"default:pending" json:"payment_status"`
 	PaymentMethod string        `gorm:"default:qris" json:"payment_method"` // qris, gopay, dana, shopee, ovo, linkaja
+	QRISPayload   string        `gorm:"type:text" json:"-"`                 // raw QRIS string, rendered by the QR endpoint
 	ProductName   string        `gorm:"" json:"product_name,omitempty"`     // Denormalized for history
 	ProductEmoji  string        `gorm:"" json:"product_emoji,omitempty"`    // Denormalized for history
+	PollOptionID  *uuid.UUID    `gorm:"type:uuid;index" json:"poll_option_id,omitempty"`
+	PollOption    string        `gorm:"" json:"poll_option,omitempty"` // Denormalized option label
 	CreatedAt     time.Time     `gorm:"autoCreateTime" json:"created_at"`
 	PaidAt        *time.Time    `gorm:"" json:"paid_at,omitempty"`
 
+	// Alert moderation (only used when creator has alert approval enabled)
+	AlertStatus    AlertStatus `gorm:"index" json:"alert_status,omitempty"`
+	ModeratedBy    *uuid.UUID  `gorm:"type:uuid" json:"moderated_by,omitempty"`
+	ModeratedAt    *time.Time  `gorm:"" json:"moderated_at,omitempty"`
+	ModerationNote string      `gorm:"type:text" json:"moderation_note,omitempty"`
+
+	// Alert delivery (set when the alert is pushed to / acknowledged by an overlay)
+	AlertDeliveredAt *time.Time `gorm:"" json:"alert_delivered_at,omitempty"`
+	AlertDeliveredTo int        `gorm:"default:0" json:"alert_delivered_to"` // overlay clients connected at broadcast time
+	AlertAckedAt     *time.Time `gorm:"" json:"alert_acked_at,omitempty"`
+
 	// Relations
 	Creator User       `gorm:"foreignKey:CreatorID" json:"creator,omitempty"`
 	Product *QuickItem `gorm:"foreignKey:ProductID" json:"product,omitempty"`
 	Buyer   *User      `gorm:"foreignKey:BuyerID" json:"buyer,omitempty"`
 }
 
+// Delivery states reported on the overlay status dashboard
+const (
+	DeliveryStateNotSent       = "not_sent"       // paid but never broadcast
+	DeliveryStatePendingReview = "pending_review" // held in the approval queue
+	DeliveryStateRejected      = "rejected"       // rejected by the creator
+	DeliveryStateNoOverlay     = "no_overlay"     // broadcast while no alert overlay was connected
+	DeliveryStateDelivered     = "delivered"      // pushed to at least one overlay, not yet acknowledged
+	DeliveryStateAcknowledged  = "acknowledged"   // an overlay confirmed it displayed the alert
+)
+
+// DeliveryState derives where the donation's alert is in the delivery pipeline
+func (d *Donation) DeliveryState() string {
+	switch {
+	case d.AlertStatus == AlertStatusPendingReview:
+		return DeliveryStatePendingReview
+	case d.AlertStatus == AlertStatusRejected:
+		return DeliveryStateRejected
+	case d.AlertAckedAt != nil:
+		return DeliveryStateAcknowledged
+	case d.AlertDeliveredAt != nil && d.AlertDeliveredTo == 0:
+		return DeliveryStateNoOverlay
+	case d.AlertDeliveredAt != nil:
+		return DeliveryStateDelivered
+	default:
+		return DeliveryStateNotSent
+	}
+}
+
+// AlertDelivery is a recent donation with its alert delivery state
+type AlertDelivery struct {
+	DonationID     uuid.UUID  `json:"donation_id"`
+	BuyerName      string     `json:"buyer_name"`
+	Amount         int64      `json:"amount"`
+	PaidAt         *time.Time `json:"paid_at,omitempty"`
+	State          string     `json:"state"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	DeliveredTo    int        `json:"delivered_to"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+}
+
+// ToDelivery converts a donation to its delivery summary
+func (d *Donation) ToDelivery() AlertDelivery {
+	return AlertDelivery{
+		DonationID:     d.ID,
+		BuyerName:      d.BuyerName,
+		Amount:         d.Amount,
+		PaidAt:         d.PaidAt,
+		State:          d.DeliveryState(),
+		DeliveredAt:    d.AlertDeliveredAt,
+		DeliveredTo:    d.AlertDeliveredTo,
+		AcknowledgedAt: d.AlertAckedAt,
+	}
+}
+
 // BeforeCreate hook to generate UUID
 func (d *Donation) BeforeCreate(tx *gorm.DB) error {
 	if d.ID == uuid.Nil {
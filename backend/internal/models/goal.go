@@ -0,0 +1,99 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Goal is a creator's donation target shown on the creator page and overlay
+type Goal struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Title        string     `gorm:"not null" json:"title"`
+	TargetAmount int64      `gorm:"not null" json:"target_amount"`
+	StartsAt     time.Time  `gorm:"not null" json:"starts_at"`
+	EndsAt       *time.Time `gorm:"" json:"ends_at,omitempty"`
+	IsActive     bool       `gorm:"default:false;index" json:"is_active"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Computed from paid donations, not stored
+	CurrentAmount int64 `gorm:"-" json:"current_amount"`
+}
+
+// BeforeCreate hook to generate UUID
+func (g *Goal) BeforeCreate(tx *gorm.DB) error {
+	if g.ID == uuid.Nil {
+		g.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsRunning returns true if the goal window contains t
+func (g *Goal) IsRunning(t time.Time) bool {
+	if t.Before(g.StartsAt) {
+		return false
+	}
+	return g.EndsAt == nil || !t.After(*g.EndsAt)
+}
+
+// Percent returns progress toward the target, capped at 100
+func (g *Goal) Percent() float64 {
+	if g.TargetAmount <= 0 {
+		return 0
+	}
+	p := float64(g.CurrentAmount) / float64(g.TargetAmount) * 100
+	if p > 100 {
+		return 100
+	}
+	return p
+}
+
+// GoalMilestones are the progress percentages announced to overlays
+// (reaching 100% is announced as goal_reached instead)
+var GoalMilestones = []int{25, 50, 75}
+
+// CrossedMilestone returns the highest milestone passed when the amount
+// raised went from before to after, or 0 if none was crossed
+func (g *Goal) CrossedMilestone(before, after int64) int {
+	if g.TargetAmount <= 0 {
+		return 0
+	}
+	crossed := 0
+	for _, m := range GoalMilestones {
+		threshold := g.TargetAmount * int64(m) / 100
+		if before < threshold && after >= threshold {
+			crossed = m
+		}
+	}
+	return crossed
+}
+
+// GoalMilestone is the payload of milestone events
+type GoalMilestone struct {
+	GoalProgress
+	Milestone int `json:"milestone"` // percent
+}
+
+// GoalProgress is the payload pushed to goal overlays
+type GoalProgress struct {
+	GoalID        uuid.UUID `json:"goal_id"`
+	Title         string    `json:"title"`
+	TargetAmount  int64     `json:"target_amount"`
+	CurrentAmount int64     `json:"current_amount"`
+	Percent       float64   `json:"percent"`
+	Reached       bool      `json:"reached"`
+}
+
+func (g *Goal) ToProgress() GoalProgress {
+	return GoalProgress{
+		GoalID:        g.ID,
+		Title:         g.Title,
+		TargetAmount:  g.TargetAmount,
+		CurrentAmount: g.CurrentAmount,
+		Percent:       g.Percent(),
+		Reached:       g.TargetAmount > 0 && g.CurrentAmount >= g.TargetAmount,
+	}
+}
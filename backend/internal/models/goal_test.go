@@ -0,0 +1,29 @@
+package models
+
+import "testing"
+
+func TestGoal_CrossedMilestone(t *testing.T) {
+	goal := &Goal{TargetAmount: 100000}
+
+	tests := []struct {
+		name          string
+		before, after int64
+		want          int
+	}{
+		{"no milestone", 0, 20000, 0},
+		{"exactly 25%", 20000, 25000, 25},
+		{"skips to highest", 10000, 80000, 75},
+		{"already past", 50000, 60000, 0},
+		{"reaching target is not a milestone", 80000, 100000, 0},
+	}
+
+	for _, tt := range tests {
+		if got := goal.CrossedMilestone(tt.before, tt.after); got != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.want, got)
+		}
+	}
+
+	if got := (&Goal{}).CrossedMilestone(0, 100); got != 0 {
+		t.Errorf("Expected 0 for goal without target, got %d", got)
+	}
+}
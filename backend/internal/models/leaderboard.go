@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Leaderboard periods
+const (
+	LeaderboardAllTime = "all"
+	LeaderboardMonthly = "month"
+	LeaderboardWeekly  = "week"
+	LeaderboardSession = "session"
+)
+
+// LeaderboardSettings stores a creator's "top jajan" board preferences
+type LeaderboardSettings struct {
+	UserID           uuid.UUID                   `gorm:"type:uuid;primary_key" json:"user_id"`
+	ExcludeAnonymous bool                        `gorm:"default:false" json:"exclude_anonymous"`
+	ExcludedNames    datatypes.JSONSlice[string] `gorm:"type:jsonb" json:"excluded_names"`
+	SessionStartedAt *time.Time                  `gorm:"" json:"session_started_at,omitempty"` // start of the current stream session
+	UpdatedAt        time.Time                   `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// LeaderboardEntry is one supporter on the board. Supporters are grouped by
+// account (BuyerID) when logged in, otherwise by email; the key is never exposed.
+type LeaderboardEntry struct {
+	Rank          int    `gorm:"-" json:"rank"`
+	SupporterKey  string `json:"-"`
+	Name          string `json:"name"`
+	TotalAmount   int64  `json:"total_amount"`
+	DonationCount int64  `json:"donation_count"`
+}
+
+// Leaderboard is the payload returned by the API and pushed to overlays
+type Leaderboard struct {
+	Period  string             `json:"period"`
+	Since   *time.Time         `json:"since,omitempty"`
+	Entries []LeaderboardEntry `json:"entries"`
+}
+
+// AnonymousNames are buyer names treated as anonymous supporters (lowercase)
+var AnonymousNames = []string{"", "anonymous", "anonim", "anon", "hamba allah", "noname", "-"}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LoginThrottle counts failed logins per email address, whether or not an
+// account exists for it, so attempts spread over many IPs still add up
+type LoginThrottle struct {
+	Email        string     `gorm:"primaryKey" json:"email"` // lowercased
+	FailedCount  int        `gorm:"not null;default:0" json:"failed_count"`
+	LastFailedAt time.Time  `gorm:"not null" json:"last_failed_at"`
+	LockedUntil  *time.Time `gorm:"" json:"locked_until,omitempty"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// IsLocked reports whether logins are refused at now
+func (t *LoginThrottle) IsLocked(now time.Time) bool {
+	return t != nil && t.LockedUntil != nil && now.Before(*t.LockedUntil)
+}
+
+// LoginDevice is an IP address and user agent a user has signed in from.
+// A login from an unseen IP or user agent is reported to the user.
+type LoginDevice struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_login_device" json:"-"`
+	IPAddress   string    `gorm:"not null;uniqueIndex:idx_login_device" json:"ip_address"`
+	UserAgent   string    `gorm:"not null;uniqueIndex:idx_login_device" json:"user_agent"`
+	FirstSeenAt time.Time `gorm:"not null" json:"first_seen_at"`
+	LastSeenAt  time.Time `gorm:"not null" json:"last_seen_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (d *LoginDevice) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// MaxOverlayProfiles caps the number of profiles per creator
+const MaxOverlayProfiles = 20
+
+// OverlayProfile is a named set of alert settings ("Valorant", "Just Chatting").
+// Overlay tokens bound to a profile render with its settings; everything else
+// uses the user's own AlertSettings.
+type OverlayProfile struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID    uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name      string         `gorm:"not null" json:"name"`
+	Settings  datatypes.JSON `gorm:"type:jsonb;default:'{}'" json:"settings"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (p *OverlayProfile) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
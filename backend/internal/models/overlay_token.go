@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Overlay widgets an overlay token can be scoped to
+const (
+	OverlayWidgetAlert       = "alert" // alert stream + alert settings
+	OverlayWidgetGoal        = "goal"
+	OverlayWidgetLeaderboard = "leaderboard"
+	OverlayWidgetTimer       = "timer"
+	OverlayWidgetPoll        = "poll"
+	OverlayWidgetTicker      = "ticker"
+	OverlayWidgetQR          = "qr" // donation page QR code image
+)
+
+var OverlayWidgets = []string{
+	OverlayWidgetAlert,
+	OverlayWidgetGoal,
+	OverlayWidgetLeaderboard,
+	OverlayWidgetTimer,
+	OverlayWidgetPoll,
+	OverlayWidgetTicker,
+	OverlayWidgetQR,
+}
+
+// OverlayToken is a read-only, revocable credential for a single overlay
+// widget. Only the SHA-256 hash of the token is stored.
+type OverlayToken struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Label       string     `gorm:"not null" json:"label"` // e.g. "Scene: Just Chatting"
+	Widget      string     `gorm:"not null" json:"widget"`
+	ProfileID   *uuid.UUID `gorm:"type:uuid;index" json:"profile_id,omitempty"` // alert tokens only: render with this profile
+	TokenHash   string     `gorm:"uniqueIndex;not null" json:"-"`
+	TokenPrefix string     `gorm:"" json:"token_prefix"` // first characters, to tell tokens apart
+	LastUsedAt  *time.Time `gorm:"" json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time `gorm:"" json:"revoked_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (t *OverlayToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsValidOverlayWidget reports whether widget is a known overlay widget
+func IsValidOverlayWidget(widget string) bool {
+	for _, w := range OverlayWidgets {
+		if w == widget {
+			return true
+		}
+	}
+	return false
+}
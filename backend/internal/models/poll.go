@@ -0,0 +1,98 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PollStatus string
+
+const (
+	PollStatusOpen   PollStatus = "open"
+	PollStatusClosed PollStatus = "closed"
+)
+
+// Poll is a donation-powered poll; supporters vote by donating with a poll option
+type Poll struct {
+	ID        uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID    uuid.UUID    `gorm:"type:uuid;not null;index" json:"user_id"`
+	Question  string       `gorm:"not null" json:"question"`
+	Status    PollStatus   `gorm:"default:open;index" json:"status"`
+	CreatedAt time.Time    `gorm:"autoCreateTime" json:"created_at"`
+	ClosedAt  *time.Time   `gorm:"" json:"closed_at,omitempty"`
+	Options   []PollOption `gorm:"foreignKey:PollID" json:"options"`
+}
+
+// PollOption totals are weighted by donation amount
+type PollOption struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PollID      uuid.UUID `gorm:"type:uuid;not null;index" json:"poll_id"`
+	Label       string    `gorm:"not null" json:"label"`
+	SortOrder   int       `gorm:"default:0" json:"sort_order"`
+	TotalAmount int64     `gorm:"default:0" json:"total_amount"`
+	VoteCount   int64     `gorm:"default:0" json:"vote_count"`
+}
+
+// BeforeCreate hook to generate UUID
+func (p *Poll) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate hook to generate UUID
+func (o *PollOption) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == uuid.Nil {
+		o.ID = uuid.New()
+	}
+	return nil
+}
+
+// PollOptionResult is one option with its share of the total amount
+type PollOptionResult struct {
+	ID          uuid.UUID `json:"id"`
+	Label       string    `json:"label"`
+	TotalAmount int64     `json:"total_amount"`
+	VoteCount   int64     `json:"vote_count"`
+	Percent     float64   `json:"percent"`
+}
+
+// PollResults is the payload returned by the API and pushed to poll overlays
+type PollResults struct {
+	PollID      uuid.UUID          `json:"poll_id"`
+	Question    string             `json:"question"`
+	Status      PollStatus         `json:"status"`
+	TotalAmount int64              `json:"total_amount"`
+	Options     []PollOptionResult `json:"options"`
+}
+
+func (p *Poll) ToResults() PollResults {
+	results := PollResults{
+		PollID:   p.ID,
+		Question: p.Question,
+		Status:   p.Status,
+		Options:  make([]PollOptionResult, 0, len(p.Options)),
+	}
+
+	for _, o := range p.Options {
+		results.TotalAmount += o.TotalAmount
+	}
+	for _, o := range p.Options {
+		var percent float64
+		if results.TotalAmount > 0 {
+			percent = float64(o.TotalAmount) / float64(results.TotalAmount) * 100
+		}
+		results.Options = append(results.Options, PollOptionResult{
+			ID:          o.ID,
+			Label:       o.Label,
+			TotalAmount: o.TotalAmount,
+			VoteCount:   o.VoteCount,
+			Percent:     percent,
+		})
+	}
+
+	return results
+}
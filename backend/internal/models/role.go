@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Built-in roles. RoleUser has no staff permissions; RoleAdmin has all of
+// them and can't be edited. The others are seeded and may be changed.
+const (
+	RoleUser    = "user"
+	RoleAdmin   = "admin"
+	RoleFinance = "finance"
+	RoleSupport = "support"
+	RoleContent = "content"
+)
+
+// Staff permissions checked by the admin routes
+const (
+	PermAll              = "*" // every permission, only for RoleAdmin
+	PermStatsRead        = "stats:read"
+	PermUsersRead        = "users:read"
+	PermUsersWrite       = "users:write" // e.g. lift a login lockout
+	PermWithdrawalsRead  = "withdrawals:read"
+	PermWithdrawalsWrite = "withdrawals:write"
+	PermProductsManage   = "products:manage"
+	PermSettingsManage   = "settings:manage"
+	PermAuditRead        = "audit:read"
+	PermRolesManage      = "roles:manage" // edit roles and assign them to users
+)
+
+// Permissions lists every permission a role can be given
+var Permissions = []string{
+	PermStatsRead,
+	PermUsersRead,
+	PermUsersWrite,
+	PermWithdrawalsRead,
+	PermWithdrawalsWrite,
+	PermProductsManage,
+	PermSettingsManage,
+	PermAuditRead,
+	PermRolesManage,
+}
+
+// Role maps a User.Role value to its permissions
+type Role struct {
+	Name        string                      `gorm:"primaryKey" json:"name"`
+	Description string                      `gorm:"" json:"description"`
+	Permissions datatypes.JSONSlice[string] `gorm:"type:jsonb" json:"permissions"`
+	CreatedAt   time.Time                   `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time                   `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// DefaultRoles are created on first start
+var DefaultRoles = []Role{
+	{Name: RoleAdmin, Description: "Full access", Permissions: []string{PermAll}},
+	{Name: RoleFinance, Description: "Handles withdrawals", Permissions: []string{PermStatsRead, PermWithdrawalsRead, PermWithdrawalsWrite}},
+	{Name: RoleSupport, Description: "Read-only access to users", Permissions: []string{PermUsersRead, PermAuditRead}},
+	{Name: RoleContent, Description: "Manages products", Permissions: []string{PermProductsManage}},
+}
+
+// IsValidPermission reports whether p is a permission a role can be given
+func IsValidPermission(p string) bool {
+	for _, known := range Permissions {
+		if p == known {
+			return true
+		}
+	}
+	return false
+}
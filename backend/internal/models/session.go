@@ -0,0 +1,89 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Reasons a session was revoked
+const (
+	SessionRevokedLogout         = "logout"
+	SessionRevokedLogoutAll      = "logout_all"
+	SessionRevokedByUser         = "revoked" // from the active sessions list
+	SessionRevokedReuse          = "refresh_reuse"
+	SessionRevokedPasswordReset  = "password_reset"
+	SessionRevokedPasswordChange = "password_change" // other devices, after a password change
+	SessionRevokedAccountClaimed = "account_claimed" // unverified account taken over through Google sign-in
+)
+
+// Session is one signed-in device. Access tokens carry the session ID and
+// stop working when it is revoked; the refresh token rotates on every use
+// and only the SHA-256 hash of the current one is stored.
+type Session struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID           uuid.UUID  `gorm:"type:uuid;not null;index" json:"-"`
+	RefreshTokenHash string     `gorm:"not null" json:"-"`
+	UserAgent        string     `gorm:"" json:"user_agent"`
+	DeviceName       string     `gorm:"" json:"device_name"` // e.g. "Chrome on Windows"
+	IPAddress        string     `gorm:"" json:"ip_address"`
+	LastUsedAt       time.Time  `gorm:"not null" json:"last_used_at"`
+	ExpiresAt        time.Time  `gorm:"not null;index" json:"expires_at"`
+	StepUpAt         *time.Time `gorm:"" json:"-"` // last two-factor verification in this session
+	RevokedAt        *time.Time `gorm:"" json:"-"`
+	RevokedReason    string     `gorm:"" json:"-"`
+	CreatedAt        time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (s *Session) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsActive reports whether the session is neither revoked nor expired
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// DeviceNameFromUserAgent gives a short "Browser on OS" label for the
+// sessions list. It only needs to be recognisable, not exact.
+func DeviceNameFromUserAgent(ua string) string {
+	browser := "Unknown browser"
+	switch {
+	case strings.Contains(ua, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "OPR/"), strings.Contains(ua, "Opera"):
+		browser = "Opera"
+	case strings.Contains(ua, "SamsungBrowser/"):
+		browser = "Samsung Internet"
+	case strings.Contains(ua, "Firefox/"), strings.Contains(ua, "FxiOS/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "Chrome/"), strings.Contains(ua, "CriOS/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "Safari/"):
+		browser = "Safari"
+	}
+
+	os := "unknown device"
+	switch {
+	case strings.Contains(ua, "Android"):
+		os = "Android"
+	case strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPad"):
+		os = "iOS"
+	case strings.Contains(ua, "Windows"):
+		os = "Windows"
+	case strings.Contains(ua, "Mac OS X"), strings.Contains(ua, "Macintosh"):
+		os = "macOS"
+	case strings.Contains(ua, "CrOS"):
+		os = "ChromeOS"
+	case strings.Contains(ua, "Linux"):
+		os = "Linux"
+	}
+
+	return browser + " on " + os
+}
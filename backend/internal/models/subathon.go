@@ -0,0 +1,115 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type SubathonStatus string
+
+const (
+	SubathonStatusIdle    SubathonStatus = "idle"
+	SubathonStatusRunning SubathonStatus = "running"
+	SubathonStatusPaused  SubathonStatus = "paused"
+	SubathonStatusEnded   SubathonStatus = "ended"
+)
+
+// SubathonProductBonus adds extra seconds per unit of a jajan product
+type SubathonProductBonus struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Seconds   int64     `json:"seconds"`
+}
+
+// SubathonTimer is a per-creator countdown extended by paid donations.
+// While running, EndsAt is the source of truth so the timer survives
+// server restarts; while paused, RemainingSeconds is frozen.
+type SubathonTimer struct {
+	ID               uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID           uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	Status           SubathonStatus `gorm:"default:idle" json:"status"`
+	EndsAt           *time.Time     `gorm:"" json:"ends_at,omitempty"`
+	RemainingSeconds int64          `gorm:"default:0" json:"-"`
+	StartedAt        *time.Time     `gorm:"" json:"started_at,omitempty"`
+	EndedAt          *time.Time     `gorm:"" json:"ended_at,omitempty"`
+
+	// Rules: SecondsPerUnit seconds for every UnitAmount rupiah donated
+	SecondsPerUnit int64                                     `gorm:"default:60" json:"seconds_per_unit"`
+	UnitAmount     int64                                     `gorm:"default:10000" json:"unit_amount"`
+	MaxSeconds     int64                                     `gorm:"default:0" json:"max_seconds"` // cap on remaining time, 0 = no cap
+	ProductBonuses datatypes.JSONSlice[SubathonProductBonus] `gorm:"type:jsonb" json:"product_bonuses"`
+
+	TotalAddedSeconds int64     `gorm:"default:0" json:"total_added_seconds"`
+	CreatedAt         time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (t *SubathonTimer) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// Remaining returns the seconds left on the timer at the given time
+func (t *SubathonTimer) Remaining(now time.Time) int64 {
+	switch t.Status {
+	case SubathonStatusRunning:
+		if t.EndsAt == nil || !t.EndsAt.After(now) {
+			return 0
+		}
+		return int64(t.EndsAt.Sub(now).Seconds())
+	case SubathonStatusPaused:
+		return t.RemainingSeconds
+	default:
+		return 0
+	}
+}
+
+// SecondsFor returns how many seconds a paid donation adds under the timer rules
+func (t *SubathonTimer) SecondsFor(d *Donation) int64 {
+	var seconds int64
+	if t.UnitAmount > 0 && t.SecondsPerUnit > 0 {
+		seconds = d.Amount * t.SecondsPerUnit / t.UnitAmount
+	}
+
+	if d.ProductID != nil {
+		quantity := int64(d.Quantity)
+		if quantity < 1 {
+			quantity = 1
+		}
+		for _, bonus := range t.ProductBonuses {
+			if bonus.ProductID == *d.ProductID {
+				seconds += bonus.Seconds * quantity
+			}
+		}
+	}
+
+	return seconds
+}
+
+// SubathonState is the payload pushed to timer overlays
+type SubathonState struct {
+	Status            SubathonStatus `json:"status"`
+	RemainingSeconds  int64          `json:"remaining_seconds"`
+	EndsAt            *time.Time     `json:"ends_at,omitempty"`
+	TotalAddedSeconds int64          `json:"total_added_seconds"`
+	AddedSeconds      int64          `json:"added_seconds,omitempty"` // seconds added by the latest donation
+	ServerTime        time.Time      `json:"server_time"`
+}
+
+func (t *SubathonTimer) ToState(now time.Time) SubathonState {
+	state := SubathonState{
+		Status:            t.Status,
+		RemainingSeconds:  t.Remaining(now),
+		TotalAddedSeconds: t.TotalAddedSeconds,
+		ServerTime:        now,
+	}
+	if t.Status == SubathonStatusRunning {
+		state.EndsAt = t.EndsAt
+	}
+	return state
+}
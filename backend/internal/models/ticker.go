@@ -0,0 +1,138 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Ticker placeholders usable in TickerSettings.Format
+const (
+	TickerPlaceholderName    = "{name}"
+	TickerPlaceholderAmount  = "{amount}"
+	TickerPlaceholderItem    = "{item}"
+	TickerPlaceholderMessage = "{message}"
+)
+
+const (
+	DefaultTickerFormat    = "{name} • {amount}"
+	DefaultTickerSeparator = "   ✦   "
+
+	// MaxTickerMessageLength caps messages shown on the ticker (in characters)
+	MaxTickerMessageLength = 100
+)
+
+// TickerSettings stores a creator's recent-supporters ticker preferences
+type TickerSettings struct {
+	UserID          uuid.UUID `gorm:"type:uuid;primary_key" json:"user_id"`
+	Count           int       `gorm:"default:10" json:"count"`         // number of supporters shown
+	WindowMinutes   int       `gorm:"default:0" json:"window_minutes"` // only donations this recent, 0 = no limit
+	IncludeMessages bool      `gorm:"default:false" json:"include_messages"`
+	Format          string    `gorm:"" json:"format"`    // template with {name}, {amount}, {item}, {message}
+	Separator       string    `gorm:"" json:"separator"` // text between entries
+	UpdatedAt       time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// DefaultTickerSettings returns the settings used until the creator saves their own
+func DefaultTickerSettings(userID uuid.UUID) *TickerSettings {
+	return &TickerSettings{
+		UserID:    userID,
+		Count:     10,
+		Format:    DefaultTickerFormat,
+		Separator: DefaultTickerSeparator,
+	}
+}
+
+// Since returns the oldest payment time shown on the ticker (nil = no limit)
+func (s *TickerSettings) Since(now time.Time) *time.Time {
+	if s.WindowMinutes <= 0 {
+		return nil
+	}
+	since := now.Add(-time.Duration(s.WindowMinutes) * time.Minute)
+	return &since
+}
+
+// TickerItem is one supporter entry on the ticker
+type TickerItem struct {
+	DonationID uuid.UUID `json:"donation_id"`
+	Name       string    `json:"name"`
+	Amount     int64     `json:"amount"`
+	Item       string    `json:"item,omitempty"`
+	Message    string    `json:"message,omitempty"` // only when messages are included
+	Text       string    `json:"text"`              // rendered with the creator's format
+	PaidAt     time.Time `json:"paid_at"`
+}
+
+// Ticker is the snapshot pushed to ticker overlays. Overlays prepend
+// ticker_item events, keep at most Count items and drop items older than
+// WindowMinutes.
+type Ticker struct {
+	Count         int          `json:"count"`
+	WindowMinutes int          `json:"window_minutes"`
+	Separator     string       `json:"separator"`
+	Items         []TickerItem `json:"items"`
+}
+
+// FormatRupiah formats an amount the way alerts show it, e.g. "Rp10.000"
+func FormatRupiah(amount int64) string {
+	digits := fmt.Sprintf("%d", amount)
+	if amount < 0 {
+		digits = digits[1:]
+	}
+
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(d)
+	}
+
+	if amount < 0 {
+		return "-Rp" + b.String()
+	}
+	return "Rp" + b.String()
+}
+
+// NewTickerItem renders a paid donation as a ticker entry
+func (s *TickerSettings) NewTickerItem(d *Donation) TickerItem {
+	item := TickerItem{
+		DonationID: d.ID,
+		Name:       strings.TrimSpace(d.BuyerName),
+		Amount:     d.Amount,
+		PaidAt:     d.CreatedAt,
+	}
+	if d.PaidAt != nil {
+		item.PaidAt = *d.PaidAt
+	}
+	if item.Name == "" {
+		item.Name = "Anonymous"
+	}
+	if d.ProductName != "" {
+		item.Item = fmt.Sprintf("%s %dx %s", d.ProductEmoji, max(d.Quantity, 1), d.ProductName)
+		item.Item = strings.TrimSpace(item.Item)
+	}
+	if s.IncludeMessages {
+		item.Message = truncateRunes(strings.TrimSpace(d.Message), MaxTickerMessageLength)
+	}
+
+	text := strings.NewReplacer(
+		TickerPlaceholderName, item.Name,
+		TickerPlaceholderAmount, FormatRupiah(item.Amount),
+		TickerPlaceholderItem, item.Item,
+		TickerPlaceholderMessage, item.Message,
+	).Replace(s.Format)
+	item.Text = strings.Join(strings.Fields(text), " ")
+
+	return item
+}
+
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "…"
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatRupiah(t *testing.T) {
+	tests := map[int64]string{
+		0:        "Rp0",
+		500:      "Rp500",
+		1000:     "Rp1.000",
+		10000:    "Rp10.000",
+		1250000:  "Rp1.250.000",
+		-25000:   "-Rp25.000",
+		12345678: "Rp12.345.678",
+	}
+	for amount, want := range tests {
+		if got := FormatRupiah(amount); got != want {
+			t.Errorf("FormatRupiah(%d) = %q, want %q", amount, got, want)
+		}
+	}
+}
+
+func TestTickerSettings_NewTickerItem(t *testing.T) {
+	paidAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	donation := &Donation{
+		BuyerName:    "  Budi ",
+		Amount:       15000,
+		Message:      "semangat!",
+		ProductName:  "Kopi",
+		ProductEmoji: "☕",
+		Quantity:     3,
+		PaidAt:       &paidAt,
+	}
+
+	settings := DefaultTickerSettings(donation.CreatorID)
+	settings.Format = "{name} {item} {amount} {message}"
+
+	item := settings.NewTickerItem(donation)
+	if item.Text != "Budi ☕ 3x Kopi Rp15.000" {
+		t.Errorf("Unexpected text without messages: %q", item.Text)
+	}
+	if item.Message != "" {
+		t.Error("Expected message to be left out")
+	}
+	if !item.PaidAt.Equal(paidAt) {
+		t.Errorf("Expected paid_at %v, got %v", paidAt, item.PaidAt)
+	}
+
+	settings.IncludeMessages = true
+	item = settings.NewTickerItem(donation)
+	if item.Text != "Budi ☕ 3x Kopi Rp15.000 semangat!" {
+		t.Errorf("Unexpected text with messages: %q", item.Text)
+	}
+
+	donation.BuyerName = ""
+	donation.Message = strings.Repeat("a", MaxTickerMessageLength+10)
+	item = settings.NewTickerItem(donation)
+	if item.Name != "Anonymous" {
+		t.Errorf("Expected Anonymous, got %q", item.Name)
+	}
+	if len([]rune(item.Message)) != MaxTickerMessageLength+1 {
+		t.Errorf("Expected message truncated to %d characters, got %d", MaxTickerMessageLength, len([]rune(item.Message)))
+	}
+}
+
+func TestTickerSettings_Since(t *testing.T) {
+	now := time.Now()
+	settings := &TickerSettings{}
+	if settings.Since(now) != nil {
+		t.Error("Expected no limit without a window")
+	}
+
+	settings.WindowMinutes = 30
+	since := settings.Since(now)
+	if since == nil || !since.Equal(now.Add(-30*time.Minute)) {
+		t.Errorf("Expected 30 minutes ago, got %v", since)
+	}
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TwoFactor is a user's TOTP enrollment. The secret is encrypted at rest;
+// until EnabledAt is set the enrollment is pending confirmation.
+type TwoFactor struct {
+	UserID          uuid.UUID  `gorm:"type:uuid;primary_key" json:"-"`
+	SecretEncrypted string     `gorm:"not null" json:"-"`
+	EnabledAt       *time.Time `gorm:"" json:"enabled_at"`
+	LastUsedStep    int64      `gorm:"not null;default:0" json:"-"` // codes at or before this step are rejected (replay)
+	CreatedAt       time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// IsEnabled returns true once the enrollment was confirmed with a code
+func (t *TwoFactor) IsEnabled() bool {
+	return t != nil && t.EnabledAt != nil
+}
+
+// RecoveryCode is a single-use backup for a lost authenticator. Only the
+// SHA-256 hash is stored.
+type RecoveryCode struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"-"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"-"`
+	CodeHash  string     `gorm:"not null" json:"-"`
+	UsedAt    *time.Time `gorm:"" json:"-"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"-"`
+}
+
+// BeforeCreate hook to generate UUID
+func (r *RecoveryCode) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
@@ -17,7 +17,7 @@ type User struct {
 	ImageURL     string    `gorm:"" json:"image_url"`
 	Bio          string    `gorm:"type:text" json:"bio"`
 	GoogleID     *string   `gorm:"uniqueIndex" json:"-"`
-	Role         string    `gorm:"default:'user'" json:"role"` // "user" or "admin"
+	Role         string    `gorm:"default:'user'" json:"role"` // "user", "admin" or a staff Role
 	BankName     string    `gorm:"" json:"bank_name,omitempty"`
 	BankAccount  string    `gorm:"" json:"bank_account,omitempty"`
 	BankHolder   string    `gorm:"" json:"bank_holder,omitempty"`
@@ -34,6 +34,20 @@ type User struct {
 	// Stream Key for overlay authentication (replaces username in overlay URLs)
 	StreamKey string `gorm:"uniqueIndex" json:"stream_key"`
 
+	// Previous stream key keeps working until PreviousStreamKeyExpiresAt so
+	// OBS scenes don't break the moment the key is regenerated
+	PreviousStreamKey          string     `gorm:"index" json:"-"`
+	PreviousStreamKeyExpiresAt *time.Time `gorm:"" json:"previous_stream_key_expires_at,omitempty"`
+
+	// When enabled, paid donations wait in a review queue before hitting the overlay
+	AlertApprovalEnabled bool `gorm:"default:false" json:"alert_approval_enabled"`
+
+	// Set once the creator opened the verification link (or signed in with Google)
+	EmailVerifiedAt *time.Time `gorm:"" json:"email_verified_at"`
+
+	// False for accounts that only sign in with Google; set when loaded
+	HasPassword bool `gorm:"-" json:"has_password"`
+
 	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
@@ -46,7 +60,17 @@ type User struct {
 
 // IsAdmin returns true if user has admin role
 func (u *User) IsAdmin() bool {
-	return u.Role == "admin"
+	return u.Role == RoleAdmin
+}
+
+// IsStaff returns true for admins and other staff roles
+func (u *User) IsStaff() bool {
+	return u.Role != "" && u.Role != RoleUser
+}
+
+// IsEmailVerified returns true if the user proved they own their email address
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
 
 // PublicProfile returns a safe version of user for public display
@@ -71,3 +95,9 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// AfterFind hook to fill HasPassword
+func (u *User) AfterFind(tx *gorm.DB) error {
+	u.HasPassword = u.PasswordHash != ""
+	return nil
+}
@@ -0,0 +1,144 @@
+package qrcode
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestRSRemainder(t *testing.T) {
+	// "HELLO WORLD" as 1-M (example from the spec annex / thonky.com tutorial)
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	got := rsRemainder(data, rsDivisor(len(want)))
+	if !bytes.Equal(got, want) {
+		t.Errorf("Expected ECC %v, got %v", want, got)
+	}
+}
+
+func TestFormatAndVersionInfo(t *testing.T) {
+	formats := []struct {
+		level Level
+		mask  int
+		want  int
+	}{
+		{Low, 0, 0b111011111000100},
+		{Medium, 0, 0b101010000010010},
+		{Quartile, 0, 0b011010101011111},
+		{High, 0, 0b001011010001001},
+	}
+	for _, f := range formats {
+		if got := formatInfo(f.level, f.mask); got != f.want {
+			t.Errorf("formatInfo(%d, %d) = %015b, want %015b", f.level, f.mask, got, f.want)
+		}
+	}
+
+	if got := versionInfo(7); got != 0x07C94 {
+		t.Errorf("versionInfo(7) = %#x, want 0x07c94", got)
+	}
+	if got := versionInfo(40); got != 0x28C69 {
+		t.Errorf("versionInfo(40) = %#x, want 0x28c69", got)
+	}
+}
+
+func TestEncode_VersionSelection(t *testing.T) {
+	tests := []struct {
+		n       int
+		level   Level
+		version int
+	}{
+		{17, Low, 1},
+		{18, Low, 2},
+		{14, Medium, 1},
+		{7, High, 1},
+		{2953, Low, 40},
+		{1273, High, 40},
+	}
+	for _, tt := range tests {
+		c, err := Encode(bytes.Repeat([]byte("a"), tt.n), tt.level)
+		if err != nil {
+			t.Fatalf("%d bytes at level %d: %v", tt.n, tt.level, err)
+		}
+		if c.Version != tt.version {
+			t.Errorf("%d bytes at level %d: expected version %d, got %d", tt.n, tt.level, tt.version, c.Version)
+		}
+		if c.Size != tt.version*4+17 {
+			t.Errorf("Expected size %d, got %d", tt.version*4+17, c.Size)
+		}
+	}
+
+	if _, err := Encode(bytes.Repeat([]byte("a"), 2954), Low); err != ErrTooLong {
+		t.Errorf("Expected ErrTooLong, got %v", err)
+	}
+}
+
+func TestEncode_FunctionPatterns(t *testing.T) {
+	c, err := Encode([]byte("https://jajan.in/budi"), High)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Finder pattern rings: dark, light, dark 3x3 core
+	for _, origin := range [][2]int{{0, 0}, {c.Size - 7, 0}, {0, c.Size - 7}} {
+		for i := 0; i < 7; i++ {
+			if !c.Dark(origin[0]+i, origin[1]) || !c.Dark(origin[0], origin[1]+i) {
+				t.Fatalf("Expected dark finder border at %v", origin)
+			}
+		}
+		if c.Dark(origin[0]+1, origin[1]+1) || !c.Dark(origin[0]+3, origin[1]+3) {
+			t.Fatalf("Unexpected finder pattern at %v", origin)
+		}
+	}
+
+	for i := 8; i < c.Size-8; i++ {
+		if c.Dark(i, 6) != (i%2 == 0) || c.Dark(6, i) != (i%2 == 0) {
+			t.Fatalf("Broken timing pattern at %d", i)
+		}
+	}
+
+	if !c.Dark(8, c.Size-8) {
+		t.Error("Expected the dark module to be set")
+	}
+}
+
+func TestRender(t *testing.T) {
+	c, err := Encode([]byte("https://jajan.in/budi"), High)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DefaultRenderOptions()
+	opts.Size = 300
+	data, err := c.PNG(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Expected a valid PNG: %v", err)
+	}
+	total := c.Size + 2*opts.QuietZone
+	if w := img.Bounds().Dx(); w != total*(300/total) {
+		t.Errorf("Expected width %d, got %d", total*(300/total), w)
+	}
+
+	svg := string(c.SVG(opts))
+	if !strings.HasPrefix(svg, "<svg") || !strings.HasSuffix(svg, "</svg>") {
+		t.Errorf("Unexpected SVG: %.60s", svg)
+	}
+	if !strings.Contains(svg, `fill="#FFFFFF"`) {
+		t.Error("Expected an opaque background")
+	}
+
+	opts.Background.A = 0
+	opts.Badge = true
+	svg = string(c.SVG(opts))
+	if strings.Contains(svg, `fill="#FFFFFF"`) {
+		t.Error("Expected a transparent background")
+	}
+	if !strings.Contains(svg, `rx=`) {
+		t.Error("Expected a badge")
+	}
+}
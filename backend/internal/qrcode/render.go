@@ -0,0 +1,143 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// RenderOptions controls how a Code is drawn
+type RenderOptions struct {
+	Size       int        // target width in pixels; the PNG is rounded down to whole pixels per module
+	QuietZone  int        // light border in modules (the spec asks for 4)
+	Foreground color.RGBA // dark modules
+	Background color.RGBA // light modules, alpha 0 renders a transparent background
+	Badge      bool       // clear the centre and draw a badge; encode with High so it stays readable
+}
+
+// DefaultRenderOptions renders black on white with the standard quiet zone
+func DefaultRenderOptions() RenderOptions {
+	return RenderOptions{
+		Size:       512,
+		QuietZone:  4,
+		Foreground: color.RGBA{0, 0, 0, 255},
+		Background: color.RGBA{255, 255, 255, 255},
+	}
+}
+
+// badgeBounds returns the module range [from, to) covered by the centre
+// badge: an odd square of about a fifth of the symbol (~4% of its area)
+func (c *Code) badgeBounds() (from, to int) {
+	side := c.Size / 5
+	if side%2 == 0 {
+		side++
+	}
+	from = (c.Size - side) / 2
+	return from, from + side
+}
+
+func (c *Code) inBadge(x, y int, opts RenderOptions) bool {
+	if !opts.Badge {
+		return false
+	}
+	from, to := c.badgeBounds()
+	return x >= from && x < to && y >= from && y < to
+}
+
+// PNG renders the code as a two-color PNG
+func (c *Code) PNG(opts RenderOptions) ([]byte, error) {
+	total := c.Size + 2*opts.QuietZone
+	scale := max(1, opts.Size/total)
+
+	img := image.NewPaletted(image.Rect(0, 0, total*scale, total*scale),
+		color.Palette{opts.Background, opts.Foreground})
+
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.Dark(x, y) || c.inBadge(x, y, opts) {
+				continue
+			}
+			px, py := (x+opts.QuietZone)*scale, (y+opts.QuietZone)*scale
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetColorIndex(px+dx, py+dy, 1)
+				}
+			}
+		}
+	}
+
+	if opts.Badge {
+		// Rounded square inset by half a module from the cleared area
+		from, to := c.badgeBounds()
+		inset := scale / 2
+		x0, x1 := (from+opts.QuietZone)*scale+inset, (to+opts.QuietZone)*scale-inset
+		radius := (x1 - x0) / 4
+		for py := x0; py < x1; py++ {
+			for px := x0; px < x1; px++ {
+				if insideRoundedRect(px-x0, py-x0, x1-x0, radius) {
+					img.SetColorIndex(px, py, 1)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// insideRoundedRect reports whether pixel (x, y) lies in a side x side
+// square with corners rounded by radius
+func insideRoundedRect(x, y, side, radius int) bool {
+	cx := min(max(x, radius), side-1-radius)
+	cy := min(max(y, radius), side-1-radius)
+	dx, dy := x-cx, y-cy
+	return dx*dx+dy*dy <= radius*radius
+}
+
+// SVG renders the code as a scalable SVG document. Each row of dark modules
+// becomes horizontal runs of one path to keep the output small.
+func (c *Code) SVG(opts RenderOptions) []byte {
+	total := c.Size + 2*opts.QuietZone
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d" shape-rendering="crispEdges">`,
+		total, total, opts.Size, opts.Size)
+	if opts.Background.A > 0 {
+		fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="%s"/>`, total, total, hexColor(opts.Background))
+	}
+
+	fmt.Fprintf(&buf, `<path fill="%s" d="`, hexColor(opts.Foreground))
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; {
+			if !c.Dark(x, y) || c.inBadge(x, y, opts) {
+				x++
+				continue
+			}
+			start := x
+			for x < c.Size && c.Dark(x, y) && !c.inBadge(x, y, opts) {
+				x++
+			}
+			fmt.Fprintf(&buf, "M%d %dh%dv1h-%dz", start+opts.QuietZone, y+opts.QuietZone, x-start, x-start)
+		}
+	}
+	buf.WriteString(`"/>`)
+
+	if opts.Badge {
+		from, to := c.badgeBounds()
+		side := float64(to-from) - 1
+		fmt.Fprintf(&buf, `<rect x="%g" y="%g" width="%g" height="%g" rx="%g" fill="%s" shape-rendering="geometricPrecision"/>`,
+			float64(from+opts.QuietZone)+0.5, float64(from+opts.QuietZone)+0.5, side, side, side/4, hexColor(opts.Foreground))
+	}
+
+	buf.WriteString(`</svg>`)
+	return buf.Bytes()
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02X%02X%02X", c.R, c.G, c.B)
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AssetRepository struct {
+	db *gorm.DB
+}
+
+func NewAssetRepository(db *gorm.DB) *AssetRepository {
+	return &AssetRepository{db: db}
+}
+
+func (r *AssetRepository) FindByID(id uuid.UUID) (*models.Asset, error) {
+	var asset models.Asset
+	err := r.db.Where("id = ?", id).First(&asset).Error
+	if err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+// FindByIDAndUserID returns the asset only if it belongs to the user
+func (r *AssetRepository) FindByIDAndUserID(id, userID uuid.UUID) (*models.Asset, error) {
+	var asset models.Asset
+	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&asset).Error
+	if err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+func (r *AssetRepository) FindByUserID(userID uuid.UUID) ([]models.Asset, error) {
+	var assets []models.Asset
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&assets).Error
+	return assets, err
+}
+
+// SumSizeByUserID returns the total bytes uploaded by the user
+func (r *AssetRepository) SumSizeByUserID(userID uuid.UUID) (int64, error) {
+	var total int64
+	err := r.db.Model(&models.Asset{}).
+		Where("user_id = ?", userID).
+		Select("COALESCE(SUM(size), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// CreateWithinQuota inserts the asset unless it would take the user's
+// uploads past quotaBytes. The user's row is locked while checking, so
+// concurrent uploads can't each pass the check. Returns false if over quota.
+func (r *AssetRepository) CreateWithinQuota(asset *models.Asset, quotaBytes int64) (bool, error) {
+	created := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&user, "id = ?", asset.UserID).Error; err != nil {
+			return err
+		}
+
+		var used int64
+		if err := tx.Model(&models.Asset{}).
+			Where("user_id = ?", asset.UserID).
+			Select("COALESCE(SUM(size), 0)").
+			Scan(&used).Error; err != nil {
+			return err
+		}
+		if used+asset.Size > quotaBytes {
+			return nil
+		}
+
+		if err := tx.Create(asset).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+func (r *AssetRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.Asset{}, "id = ?", id).Error
+}
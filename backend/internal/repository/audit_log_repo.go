@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type AuditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+func (r *AuditLogRepository) Create(entry *models.AuditLog) error {
+	return r.db.Create(entry).Error
+}
+
+// AuditLogFilter narrows FindAll; zero values match everything
+type AuditLogFilter struct {
+	UserID *uuid.UUID
+	Action string
+	Email  string
+}
+
+// FindAll returns a page of entries, newest first, and the total count
+func (r *AuditLogRepository) FindAll(filter AuditLogFilter, limit, offset int) ([]models.AuditLog, int64, error) {
+	query := r.db.Model(&models.AuditLog{})
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Email != "" {
+		query = query.Where("email = ?", filter.Email)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []models.AuditLog
+	err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&entries).Error
+	return entries, total, err
+}
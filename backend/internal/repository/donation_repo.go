@@ -83,11 +83,26 @@ func (r *DonationRepository) FindRecentByCreatorID(creatorID uuid.UUID, limit in
 	return donations, err
 }
 
+// FindRecentVisibleByCreatorID is FindRecentByCreatorID without donations
+// held for or rejected in moderation, for listings anyone can read
+func (r *DonationRepository) FindRecentVisibleByCreatorID(creatorID uuid.UUID, limit int) ([]models.Donation, error) {
+	var donations []models.Donation
+	err := r.db.Where("creator_id = ? AND payment_status = ? AND alert_status NOT IN ?", creatorID, models.PaymentStatusPaid,
+		[]models.AlertStatus{models.AlertStatusPendingReview, models.AlertStatusRejected}).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&donations).Error
+	return donations, err
+}
+
 func (r *DonationRepository) Update(donation *models.Donation) error {
 	return r.db.Save(donation).Error
 }
 
-func (r *DonationRepository) UpdatePaymentStatus(id uuid.UUID, status models.PaymentStatus) error {
+// UpdatePaymentStatus moves a donation to status unless it is already paid
+// or in that status. Returns false if another request changed it first, so
+// only one caller acts on a payment.
+func (r *DonationRepository) UpdatePaymentStatus(id uuid.UUID, status models.PaymentStatus) (bool, error) {
 	updates := map[string]interface{}{
 		"payment_status": status,
 	}
@@ -95,7 +110,76 @@ func (r *DonationRepository) UpdatePaymentStatus(id uuid.UUID, status models.Pay
 		now := time.Now()
 		updates["paid_at"] = &now
 	}
-	return r.db.Model(&models.Donation{}).Where("id = ?", id).Updates(updates).Error
+	result := r.db.Model(&models.Donation{}).
+		Where("id = ? AND payment_status NOT IN ?", id, []models.PaymentStatus{models.PaymentStatusPaid, status}).
+		Updates(updates)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *DonationRepository) UpdateAlertStatus(id uuid.UUID, status models.AlertStatus) error {
+	return r.db.Model(&models.Donation{}).Where("id = ?", id).Update("alert_status", status).Error
+}
+
+// FindPendingReviewByCreatorID returns paid donations waiting for the creator's approval
+func (r *DonationRepository) FindPendingReviewByCreatorID(creatorID uuid.UUID, limit, offset int) ([]models.Donation, error) {
+	var donations []models.Donation
+	err := r.db.Where("creator_id = ? AND payment_status = ? AND alert_status = ?",
+		creatorID, models.PaymentStatusPaid, models.AlertStatusPendingReview).
+		Order("paid_at ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&donations).Error
+	return donations, err
+}
+
+func (r *DonationRepository) CountPendingReviewByCreatorID(creatorID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Donation{}).
+		Where("creator_id = ? AND payment_status = ? AND alert_status = ?",
+			creatorID, models.PaymentStatusPaid, models.AlertStatusPendingReview).
+		Count(&count).Error
+	return count, err
+}
+
+// ModerateAlert moves a pending_review donation to approved/rejected.
+// Returns false if the donation was not pending review (already moderated or not found).
+func (r *DonationRepository) ModerateAlert(id, creatorID uuid.UUID, status models.AlertStatus, moderatorID uuid.UUID, note string) (bool, error) {
+	now := time.Now()
+	result := r.db.Model(&models.Donation{}).
+		Where("id = ? AND creator_id = ? AND alert_status = ?", id, creatorID, models.AlertStatusPendingReview).
+		Updates(map[string]interface{}{
+			"alert_status":    status,
+			"moderated_by":    moderatorID,
+			"moderated_at":    &now,
+			"moderation_note": note,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// MarkAlertDelivered records that the alert was pushed to the given number of overlay clients
+func (r *DonationRepository) MarkAlertDelivered(id uuid.UUID, clients int) error {
+	now := time.Now()
+	return r.db.Model(&models.Donation{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"alert_delivered_at": &now,
+			"alert_delivered_to": clients,
+		}).Error
+}
+
+// AckAlert marks the creator's donation alert as displayed. Only the first ack is recorded.
+// Returns false if the donation was not found, not owned by the creator, or already acked.
+func (r *DonationRepository) AckAlert(id, creatorID uuid.UUID) (bool, error) {
+	now := time.Now()
+	result := r.db.Model(&models.Donation{}).
+		Where("id = ? AND creator_id = ? AND alert_acked_at IS NULL", id, creatorID).
+		Update("alert_acked_at", &now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
 }
 
 // Statistics
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type GoalRepository struct {
+	db *gorm.DB
+}
+
+func NewGoalRepository(db *gorm.DB) *GoalRepository {
+	return &GoalRepository{db: db}
+}
+
+func (r *GoalRepository) Create(goal *models.Goal) error {
+	return r.db.Create(goal).Error
+}
+
+func (r *GoalRepository) FindByID(id uuid.UUID) (*models.Goal, error) {
+	var goal models.Goal
+	err := r.db.First(&goal, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &goal, nil
+}
+
+func (r *GoalRepository) FindByUserID(userID uuid.UUID) ([]models.Goal, error) {
+	var goals []models.Goal
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&goals).Error
+	return goals, err
+}
+
+// FindActiveByUserID returns the creator's active goal
+func (r *GoalRepository) FindActiveByUserID(userID uuid.UUID) (*models.Goal, error) {
+	var goal models.Goal
+	err := r.db.Where("user_id = ? AND is_active = ?", userID, true).
+		Order("starts_at DESC").
+		First(&goal).Error
+	if err != nil {
+		return nil, err
+	}
+	return &goal, nil
+}
+
+func (r *GoalRepository) Update(goal *models.Goal) error {
+	return r.db.Save(goal).Error
+}
+
+func (r *GoalRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.Goal{}, "id = ?", id).Error
+}
+
+// Activate marks a goal as the only active goal for its creator
+func (r *GoalRepository) Activate(goal *models.Goal) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Goal{}).
+			Where("user_id = ? AND id != ? AND is_active = ?", goal.UserID, goal.ID, true).
+			Update("is_active", false).Error; err != nil {
+			return err
+		}
+		goal.IsActive = true
+		return tx.Save(goal).Error
+	})
+}
+
+// SumPaidDonations totals paid donations for a creator inside the goal window
+func (r *GoalRepository) SumPaidDonations(userID uuid.UUID, from time.Time, to *time.Time) (int64, error) {
+	var total int64
+	query := r.db.Model(&models.Donation{}).
+		Where("creator_id = ? AND payment_status = ? AND paid_at >= ?", userID, models.PaymentStatusPaid, from)
+	if to != nil {
+		query = query.Where("paid_at <= ?", *to)
+	}
+	err := query.Select("COALESCE(SUM(amount), 0)").Scan(&total).Error
+	return total, err
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type LeaderboardRepository struct {
+	db *gorm.DB
+}
+
+func NewLeaderboardRepository(db *gorm.DB) *LeaderboardRepository {
+	return &LeaderboardRepository{db: db}
+}
+
+// GetSettings returns the creator's leaderboard settings, or defaults if none are saved
+func (r *LeaderboardRepository) GetSettings(userID uuid.UUID) (*models.LeaderboardSettings, error) {
+	var settings models.LeaderboardSettings
+	err := r.db.First(&settings, "user_id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.LeaderboardSettings{UserID: userID, ExcludedNames: []string{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (r *LeaderboardRepository) SaveSettings(settings *models.LeaderboardSettings) error {
+	return r.db.Save(settings).Error
+}
+
+// TopSupporters ranks supporters by total paid amount since the given time.
+// excludedNames must be lowercase. The name shown is the latest one the
+// creator let through moderation, so held and rejected names never appear.
+func (r *LeaderboardRepository) TopSupporters(creatorID uuid.UUID, since *time.Time, excludedNames []string, limit int) ([]models.LeaderboardEntry, error) {
+	var entries []models.LeaderboardEntry
+
+	query := r.db.Model(&models.Donation{}).
+		Select(`COALESCE(CAST(buyer_id AS TEXT), LOWER(buyer_email)) AS supporter_key,
+			COALESCE((ARRAY_AGG(buyer_name ORDER BY paid_at DESC)
+				FILTER (WHERE alert_status NOT IN (?, ?)))[1], ?) AS name,
+			SUM(amount) AS total_amount,
+			COUNT(*) AS donation_count`, models.AlertStatusPendingReview, models.AlertStatusRejected, AnonymizedName).
+		Where("creator_id = ? AND payment_status = ?", creatorID, models.PaymentStatusPaid)
+	if since != nil {
+		query = query.Where("paid_at >= ?", *since)
+	}
+	if len(excludedNames) > 0 {
+		query = query.Where("LOWER(TRIM(buyer_name)) NOT IN ?", excludedNames)
+	}
+
+	err := query.Group("supporter_key").
+		Order("total_amount DESC, MIN(paid_at) ASC").
+		Limit(limit).
+		Scan(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		entries[i].Rank = i + 1
+		entries[i].Name = strings.TrimSpace(entries[i].Name)
+	}
+	return entries, nil
+}
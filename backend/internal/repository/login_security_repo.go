@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoginSecurityRepository stores failed login counters and the devices
+// users have signed in from
+type LoginSecurityRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginSecurityRepository(db *gorm.DB) *LoginSecurityRepository {
+	return &LoginSecurityRepository{db: db}
+}
+
+func (r *LoginSecurityRepository) FindThrottle(email string) (*models.LoginThrottle, error) {
+	var throttle models.LoginThrottle
+	err := r.db.First(&throttle, "email = ?", email).Error
+	if err != nil {
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+// RecordFailure counts a failed login in one statement, so concurrent
+// attempts can't lose updates. A count whose last failure is older than
+// resetBefore starts over.
+func (r *LoginSecurityRepository) RecordFailure(email string, now, resetBefore time.Time) (*models.LoginThrottle, error) {
+	var throttle models.LoginThrottle
+	err := r.db.Raw(`
+		INSERT INTO login_throttles (email, failed_count, last_failed_at, updated_at)
+		VALUES (?, 1, ?, ?)
+		ON CONFLICT (email) DO UPDATE SET
+			failed_count = CASE WHEN login_throttles.last_failed_at < ? THEN 1 ELSE login_throttles.failed_count + 1 END,
+			last_failed_at = EXCLUDED.last_failed_at,
+			updated_at = EXCLUDED.updated_at
+		RETURNING *`, email, now, now, resetBefore).Scan(&throttle).Error
+	return &throttle, err
+}
+
+func (r *LoginSecurityRepository) Lock(email string, until time.Time) error {
+	return r.db.Model(&models.LoginThrottle{}).
+		Where("email = ?", email).
+		Update("locked_until", until).Error
+}
+
+// ClearThrottle forgets the failures for email, e.g. after a successful
+// login or an admin unlock
+func (r *LoginSecurityRepository) ClearThrottle(email string) error {
+	return r.db.Where("email = ?", email).Delete(&models.LoginThrottle{}).Error
+}
+
+// DeviceHistory reports whether the user has any recorded devices and
+// whether ip and userAgent were seen before
+func (r *LoginSecurityRepository) DeviceHistory(userID uuid.UUID, ip, userAgent string) (hasAny, knownIP, knownAgent bool, err error) {
+	var row struct {
+		Total  int64
+		IPs    int64
+		Agents int64
+	}
+	err = r.db.Model(&models.LoginDevice{}).
+		Select("COUNT(*) AS total, COUNT(*) FILTER (WHERE ip_address = ?) AS ips, COUNT(*) FILTER (WHERE user_agent = ?) AS agents", ip, userAgent).
+		Where("user_id = ?", userID).
+		Scan(&row).Error
+	return row.Total > 0, row.IPs > 0, row.Agents > 0, err
+}
+
+// TouchDevice records a login from ip and userAgent
+func (r *LoginSecurityRepository) TouchDevice(userID uuid.UUID, ip, userAgent string, at time.Time) error {
+	device := &models.LoginDevice{
+		UserID:      userID,
+		IPAddress:   ip,
+		UserAgent:   userAgent,
+		FirstSeenAt: at,
+		LastSeenAt:  at,
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "ip_address"}, {Name: "user_agent"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_seen_at"}),
+	}).Create(device).Error
+}
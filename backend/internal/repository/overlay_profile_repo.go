@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type OverlayProfileRepository struct {
+	db *gorm.DB
+}
+
+func NewOverlayProfileRepository(db *gorm.DB) *OverlayProfileRepository {
+	return &OverlayProfileRepository{db: db}
+}
+
+func (r *OverlayProfileRepository) Create(profile *models.OverlayProfile) error {
+	return r.db.Create(profile).Error
+}
+
+// FindByID returns the profile only if it belongs to the user
+func (r *OverlayProfileRepository) FindByID(id, userID uuid.UUID) (*models.OverlayProfile, error) {
+	var profile models.OverlayProfile
+	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&profile).Error
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (r *OverlayProfileRepository) FindByUserID(userID uuid.UUID) ([]models.OverlayProfile, error) {
+	var profiles []models.OverlayProfile
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&profiles).Error
+	return profiles, err
+}
+
+func (r *OverlayProfileRepository) CountByUserID(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.OverlayProfile{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+func (r *OverlayProfileRepository) Update(profile *models.OverlayProfile) error {
+	return r.db.Save(profile).Error
+}
+
+// Delete removes the profile. Tokens bound to it fall back to the user's alert settings.
+func (r *OverlayProfileRepository) Delete(id, userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.OverlayToken{}).
+			Where("profile_id = ? AND user_id = ?", id, userID).
+			Update("profile_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ? AND user_id = ?", id, userID).Delete(&models.OverlayProfile{}).Error
+	})
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type OverlayTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewOverlayTokenRepository(db *gorm.DB) *OverlayTokenRepository {
+	return &OverlayTokenRepository{db: db}
+}
+
+func (r *OverlayTokenRepository) Create(token *models.OverlayToken) error {
+	return r.db.Create(token).Error
+}
+
+// FindActiveByHash returns a non-revoked token by its hash
+func (r *OverlayTokenRepository) FindActiveByHash(hash string) (*models.OverlayToken, error) {
+	var token models.OverlayToken
+	err := r.db.First(&token, "token_hash = ? AND revoked_at IS NULL", hash).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *OverlayTokenRepository) FindByUserID(userID uuid.UUID) ([]models.OverlayToken, error) {
+	var tokens []models.OverlayToken
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// Revoke revokes a token owned by the user. Returns false if not found.
+func (r *OverlayTokenRepository) Revoke(id, userID uuid.UUID) (bool, error) {
+	result := r.db.Model(&models.OverlayToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *OverlayTokenRepository) TouchLastUsed(id uuid.UUID) error {
+	return r.db.Model(&models.OverlayToken{}).Where("id = ?", id).Update("last_used_at", time.Now()).Error
+}
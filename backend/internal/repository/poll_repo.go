@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PollRepository struct {
+	db *gorm.DB
+}
+
+func NewPollRepository(db *gorm.DB) *PollRepository {
+	return &PollRepository{db: db}
+}
+
+func optionsOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("sort_order ASC")
+}
+
+// Create saves a poll together with its options
+func (r *PollRepository) Create(poll *models.Poll) error {
+	return r.db.Create(poll).Error
+}
+
+func (r *PollRepository) FindByID(id uuid.UUID) (*models.Poll, error) {
+	var poll models.Poll
+	err := r.db.Preload("Options", optionsOrder).First(&poll, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &poll, nil
+}
+
+func (r *PollRepository) FindByUserID(userID uuid.UUID, limit int) ([]models.Poll, error) {
+	var polls []models.Poll
+	err := r.db.Preload("Options", optionsOrder).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&polls).Error
+	return polls, err
+}
+
+// FindOpenByUserID returns the creator's open poll
+func (r *PollRepository) FindOpenByUserID(userID uuid.UUID) (*models.Poll, error) {
+	var poll models.Poll
+	err := r.db.Preload("Options", optionsOrder).
+		Where("user_id = ? AND status = ?", userID, models.PollStatusOpen).
+		Order("created_at DESC").
+		First(&poll).Error
+	if err != nil {
+		return nil, err
+	}
+	return &poll, nil
+}
+
+// FindOpenOption returns an option if it belongs to an open poll of the creator
+func (r *PollRepository) FindOpenOption(optionID, userID uuid.UUID) (*models.PollOption, error) {
+	var option models.PollOption
+	err := r.db.Joins("JOIN polls ON polls.id = poll_options.poll_id").
+		Where("poll_options.id = ? AND polls.user_id = ? AND polls.status = ?", optionID, userID, models.PollStatusOpen).
+		First(&option).Error
+	if err != nil {
+		return nil, err
+	}
+	return &option, nil
+}
+
+// Close freezes the poll results. Returns false if the poll was not open.
+func (r *PollRepository) Close(id uuid.UUID) (bool, error) {
+	result := r.db.Model(&models.Poll{}).
+		Where("id = ? AND status = ?", id, models.PollStatusOpen).
+		Updates(map[string]interface{}{
+			"status":    models.PollStatusClosed,
+			"closed_at": time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// CreateClosingOpen closes the creator's open polls and creates poll in one
+// transaction, so the creator is never left without an open poll. Returns
+// the polls it closed.
+func (r *PollRepository) CreateClosingOpen(poll *models.Poll) ([]models.Poll, error) {
+	var closed []models.Poll
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("Options", optionsOrder).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND status = ?", poll.UserID, models.PollStatusOpen).
+			Find(&closed).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for i := range closed {
+			if err := tx.Model(&models.Poll{}).Where("id = ?", closed[i].ID).
+				Updates(map[string]interface{}{
+					"status":    models.PollStatusClosed,
+					"closed_at": now,
+				}).Error; err != nil {
+				return err
+			}
+			closed[i].Status = models.PollStatusClosed
+			closed[i].ClosedAt = &now
+		}
+		return tx.Create(poll).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return closed, nil
+}
+
+// AddVote adds a paid donation to an option. Votes for closed polls are
+// ignored so closing the poll freezes its results.
+func (r *PollRepository) AddVote(optionID uuid.UUID, amount int64) (bool, error) {
+	result := r.db.Model(&models.PollOption{}).
+		Where("id = ? AND poll_id IN (?)", optionID,
+			r.db.Model(&models.Poll{}).Select("id").Where("status = ?", models.PollStatusOpen)).
+		Updates(map[string]interface{}{
+			"total_amount": gorm.Expr("total_amount + ?", amount),
+			"vote_count":   gorm.Expr("vote_count + 1"),
+		})
+	return result.RowsAffected > 0, result.Error
+}
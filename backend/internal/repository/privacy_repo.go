@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// PrivacyRepository reads everything held about a user for a data export
+// and erases it when they delete their account
+type PrivacyRepository struct {
+	db *gorm.DB
+}
+
+func NewPrivacyRepository(db *gorm.DB) *PrivacyRepository {
+	return &PrivacyRepository{db: db}
+}
+
+// FindDonationsReceived returns every donation to the creator, oldest first
+func (r *PrivacyRepository) FindDonationsReceived(userID uuid.UUID) ([]models.Donation, error) {
+	var donations []models.Donation
+	err := r.db.Where("creator_id = ?", userID).Order("created_at ASC").Find(&donations).Error
+	return donations, err
+}
+
+// FindPurchases returns the donations the user made while signed in
+func (r *PrivacyRepository) FindPurchases(userID uuid.UUID) ([]models.Donation, error) {
+	var donations []models.Donation
+	err := r.db.Preload("Creator").Where("buyer_id = ?", userID).Order("created_at ASC").Find(&donations).Error
+	return donations, err
+}
+
+func (r *PrivacyRepository) FindWithdrawals(userID uuid.UUID) ([]models.Withdrawal, error) {
+	var withdrawals []models.Withdrawal
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&withdrawals).Error
+	return withdrawals, err
+}
+
+// UserSettings are the creator's overlay and widget settings
+type UserSettings struct {
+	OverlayProfiles []models.OverlayProfile     `json:"overlay_profiles"`
+	Goals           []models.Goal               `json:"goals"`
+	Polls           []models.Poll               `json:"polls"`
+	Leaderboard     *models.LeaderboardSettings `json:"leaderboard,omitempty"`
+	Ticker          *models.TickerSettings      `json:"ticker,omitempty"`
+	Subathon        *models.SubathonTimer       `json:"subathon,omitempty"`
+	Assets          []models.Asset              `json:"assets"`
+}
+
+func (r *PrivacyRepository) FindSettings(userID uuid.UUID) (*UserSettings, error) {
+	settings := &UserSettings{}
+	queries := []*gorm.DB{
+		r.db.Where("user_id = ?", userID).Find(&settings.OverlayProfiles),
+		r.db.Where("user_id = ?", userID).Find(&settings.Goals),
+		r.db.Preload("Options").Where("user_id = ?", userID).Find(&settings.Polls),
+		r.db.Where("user_id = ?", userID).Find(&settings.Assets),
+	}
+	for _, q := range queries {
+		if q.Error != nil {
+			return nil, q.Error
+		}
+	}
+
+	var leaderboard models.LeaderboardSettings
+	if err := r.db.Where("user_id = ?", userID).Limit(1).Find(&leaderboard).Error; err != nil {
+		return nil, err
+	} else if leaderboard.UserID != uuid.Nil {
+		settings.Leaderboard = &leaderboard
+	}
+	var ticker models.TickerSettings
+	if err := r.db.Where("user_id = ?", userID).Limit(1).Find(&ticker).Error; err != nil {
+		return nil, err
+	} else if ticker.UserID != uuid.Nil {
+		settings.Ticker = &ticker
+	}
+	var subathon models.SubathonTimer
+	if err := r.db.Where("user_id = ?", userID).Limit(1).Find(&subathon).Error; err != nil {
+		return nil, err
+	} else if subathon.UserID != uuid.Nil {
+		settings.Subathon = &subathon
+	}
+
+	return settings, nil
+}
+
+// AnonymizedName replaces supporter names on erased donations
+const AnonymizedName = "Anonim"
+
+// EraseUser removes a user's personal data in one transaction:
+//   - donations they received or made keep amounts, status and payment
+//     references but lose names, emails and messages
+//   - withdrawals are financial records and are kept unchanged
+//   - credentials, devices, settings and uploads are deleted
+//   - the user row is scrubbed and soft deleted. The username stays
+//     reserved until UserRepository.ReleaseUsername frees it.
+func (r *PrivacyRepository) EraseUser(userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, "id = ?", userID).Error; err != nil {
+			return err
+		}
+
+		erased := map[string]interface{}{
+			"buyer_name":      AnonymizedName,
+			"buyer_email":     "",
+			"message":         "",
+			"moderation_note": "",
+		}
+		if err := tx.Model(&models.Donation{}).Where("creator_id = ?", userID).Updates(erased).Error; err != nil {
+			return err
+		}
+		erased["buyer_id"] = nil
+		if err := tx.Model(&models.Donation{}).Where("buyer_id = ?", userID).Updates(erased).Error; err != nil {
+			return err
+		}
+
+		for _, model := range []interface{}{
+			&models.Session{},
+			&models.UserToken{},
+			&models.TwoFactor{},
+			&models.RecoveryCode{},
+			&models.LoginDevice{},
+			&models.PersonalAccessToken{},
+			&models.OverlayToken{},
+			&models.OverlayProfile{},
+			&models.Goal{},
+			&models.LeaderboardSettings{},
+			&models.TickerSettings{},
+			&models.SubathonTimer{},
+			&models.Asset{},
+		} {
+			if err := tx.Unscoped().Where("user_id = ?", userID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("poll_id IN (?)", tx.Model(&models.Poll{}).Select("id").Where("user_id = ?", userID)).
+			Delete(&models.PollOption{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.Poll{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("email = ?", strings.ToLower(user.Email)).Delete(&models.LoginThrottle{}).Error; err != nil {
+			return err
+		}
+
+		// Email, Google ID and stream key are unique, so they are replaced
+		// rather than kept; the email address can sign up again right away
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"email":                          "deleted-" + userID.String() + "@deleted.invalid",
+			"password_hash":                  "",
+			"name":                           "",
+			"image_url":                      "",
+			"bio":                            "",
+			"google_id":                      nil,
+			"bank_name":                      "",
+			"bank_account":                   "",
+			"bank_holder":                    "",
+			"twitter_url":                    "",
+			"instagram_url":                  "",
+			"youtube_url":                    "",
+			"website_url":                    "",
+			"alert_settings":                 "{}",
+			"stream_key":                     uuid.New().String(),
+			"previous_stream_key":            "",
+			"previous_stream_key_expires_at": nil,
+			"email_verified_at":              nil,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&user).Error
+	})
+}
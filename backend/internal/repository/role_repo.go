@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"github.com/jajanin/backend/internal/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type RoleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+func (r *RoleRepository) FindAll() ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.Order("name ASC").Find(&roles).Error
+	return roles, err
+}
+
+func (r *RoleRepository) FindByName(name string) (*models.Role, error) {
+	var role models.Role
+	err := r.db.First(&role, "name = ?", name).Error
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *RoleRepository) UpdatePermissions(name string, permissions []string) error {
+	return r.db.Model(&models.Role{}).Where("name = ?", name).
+		Update("permissions", datatypes.JSONSlice[string](permissions)).Error
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+func (r *SessionRepository) Create(session *models.Session) error {
+	return r.db.Create(session).Error
+}
+
+func (r *SessionRepository) FindByID(id uuid.UUID) (*models.Session, error) {
+	var session models.Session
+	err := r.db.First(&session, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// FindActiveByUserID returns the user's sessions that are neither revoked nor expired
+func (r *SessionRepository) FindActiveByUserID(userID uuid.UUID) ([]models.Session, error) {
+	var sessions []models.Session
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// Rotate swaps the refresh token hash only if it still matches oldHash, so
+// two requests racing with the same refresh token can't both succeed.
+// Returns false if the session was already rotated or revoked.
+func (r *SessionRepository) Rotate(session *models.Session, oldHash string) (bool, error) {
+	result := r.db.Model(&models.Session{}).
+		Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", session.ID, oldHash).
+		Updates(map[string]interface{}{
+			"refresh_token_hash": session.RefreshTokenHash,
+			"user_agent":         session.UserAgent,
+			"device_name":        session.DeviceName,
+			"ip_address":         session.IPAddress,
+			"last_used_at":       session.LastUsedAt,
+			"expires_at":         session.ExpiresAt,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// MarkStepUp records a two-factor verification in the session
+func (r *SessionRepository) MarkStepUp(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&models.Session{}).Where("id = ?", id).Update("step_up_at", at).Error
+}
+
+// Revoke revokes a session owned by the user. Returns false if not found.
+func (r *SessionRepository) Revoke(id, userID uuid.UUID, reason string) (bool, error) {
+	result := r.db.Model(&models.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason})
+	return result.RowsAffected > 0, result.Error
+}
+
+// RevokeAllByUserID revokes every active session of the user and returns how many
+func (r *SessionRepository) RevokeAllByUserID(userID uuid.UUID, reason string) (int64, error) {
+	result := r.db.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason})
+	return result.RowsAffected, result.Error
+}
+
+// RevokeOthersByUserID revokes the user's active sessions except keepID
+func (r *SessionRepository) RevokeOthersByUserID(userID, keepID uuid.UUID, reason string) (int64, error) {
+	result := r.db.Model(&models.Session{}).
+		Where("user_id = ? AND id != ? AND revoked_at IS NULL", userID, keepID).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason})
+	return result.RowsAffected, result.Error
+}
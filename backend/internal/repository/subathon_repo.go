@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SubathonRepository stores subathon timers. State transitions are done with
+// conditional UPDATEs so concurrent donations and creator actions never
+// overwrite each other.
+type SubathonRepository struct {
+	db *gorm.DB
+}
+
+func NewSubathonRepository(db *gorm.DB) *SubathonRepository {
+	return &SubathonRepository{db: db}
+}
+
+// FindOrCreate returns the creator's timer, creating an idle one with default rules
+func (r *SubathonRepository) FindOrCreate(userID uuid.UUID) (*models.SubathonTimer, error) {
+	timer, err := r.FindByUserID(userID)
+	if err == nil {
+		return timer, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	timer = &models.SubathonTimer{
+		UserID:         userID,
+		Status:         models.SubathonStatusIdle,
+		SecondsPerUnit: 60,
+		UnitAmount:     10000,
+		ProductBonuses: []models.SubathonProductBonus{},
+	}
+	if err := r.db.Create(timer).Error; err != nil {
+		return nil, err
+	}
+	return timer, nil
+}
+
+func (r *SubathonRepository) FindByUserID(userID uuid.UUID) (*models.SubathonTimer, error) {
+	var timer models.SubathonTimer
+	err := r.db.First(&timer, "user_id = ?", userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &timer, nil
+}
+
+// UpdateRules saves the timing rules without touching the timer state
+func (r *SubathonRepository) UpdateRules(timer *models.SubathonTimer) error {
+	return r.db.Model(timer).Select("seconds_per_unit", "unit_amount", "max_seconds", "product_bonuses").
+		Updates(timer).Error
+}
+
+// Start starts an idle or ended timer with the given duration
+func (r *SubathonRepository) Start(userID uuid.UUID, seconds int64) (bool, error) {
+	now := time.Now()
+	endsAt := now.Add(time.Duration(seconds) * time.Second)
+	result := r.db.Model(&models.SubathonTimer{}).
+		Where("user_id = ? AND status IN ?", userID,
+			[]models.SubathonStatus{models.SubathonStatusIdle, models.SubathonStatusEnded}).
+		Updates(map[string]interface{}{
+			"status":              models.SubathonStatusRunning,
+			"ends_at":             endsAt,
+			"remaining_seconds":   0,
+			"started_at":          now,
+			"ended_at":            nil,
+			"total_added_seconds": 0,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Pause freezes the remaining time of a running timer
+func (r *SubathonRepository) Pause(userID uuid.UUID) (bool, error) {
+	result := r.db.Model(&models.SubathonTimer{}).
+		Where("user_id = ? AND status = ? AND ends_at > NOW()", userID, models.SubathonStatusRunning).
+		Updates(map[string]interface{}{
+			"status":            models.SubathonStatusPaused,
+			"remaining_seconds": gorm.Expr("CAST(EXTRACT(EPOCH FROM ends_at - NOW()) AS BIGINT)"),
+			"ends_at":           nil,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Resume restarts a paused timer from its frozen remaining time
+func (r *SubathonRepository) Resume(userID uuid.UUID) (bool, error) {
+	result := r.db.Model(&models.SubathonTimer{}).
+		Where("user_id = ? AND status = ?", userID, models.SubathonStatusPaused).
+		Updates(map[string]interface{}{
+			"status":            models.SubathonStatusRunning,
+			"ends_at":           gorm.Expr("NOW() + make_interval(secs => remaining_seconds)"),
+			"remaining_seconds": 0,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// End stops a running or paused timer
+func (r *SubathonRepository) End(userID uuid.UUID) (bool, error) {
+	result := r.db.Model(&models.SubathonTimer{}).
+		Where("user_id = ? AND status IN ?", userID,
+			[]models.SubathonStatus{models.SubathonStatusRunning, models.SubathonStatusPaused}).
+		Updates(map[string]interface{}{
+			"status":            models.SubathonStatusEnded,
+			"ends_at":           nil,
+			"remaining_seconds": 0,
+			"ended_at":          time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Extend adds seconds to a running or paused timer in a single statement.
+// maxSeconds caps the remaining time (0 = no cap); total_added_seconds only
+// grows by what was actually added under the cap, and that amount is
+// returned along with whether a timer was extended.
+func (r *SubathonRepository) Extend(userID uuid.UUID, seconds, maxSeconds int64) (int64, bool, error) {
+	runningEndsAt := gorm.Expr("ends_at + make_interval(secs => ?)", seconds)
+	pausedRemaining := gorm.Expr("remaining_seconds + ?", seconds)
+	runningAdded := gorm.Expr("?", seconds)
+	pausedAdded := gorm.Expr("?", seconds)
+	if maxSeconds > 0 {
+		runningEndsAt = gorm.Expr("LEAST(ends_at + make_interval(secs => ?), NOW() + make_interval(secs => ?))", seconds, maxSeconds)
+		pausedRemaining = gorm.Expr("LEAST(remaining_seconds + ?, ?)", seconds, maxSeconds)
+		// SET expressions see the row as it was, so this is new minus old
+		runningAdded = gorm.Expr("GREATEST(CAST(EXTRACT(EPOCH FROM ? - ends_at) AS bigint), 0)", runningEndsAt)
+		pausedAdded = gorm.Expr("GREATEST(? - remaining_seconds, 0)", pausedRemaining)
+	}
+
+	var affected, added int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the row so the total read back differs only by this extension
+		var before models.SubathonTimer
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("total_added_seconds").
+			First(&before, "user_id = ?", userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		result := tx.Model(&models.SubathonTimer{}).
+			Where("user_id = ? AND status = ? AND ends_at > NOW()", userID, models.SubathonStatusRunning).
+			Updates(map[string]interface{}{
+				"ends_at":             runningEndsAt,
+				"total_added_seconds": gorm.Expr("total_added_seconds + ?", runningAdded),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		affected += result.RowsAffected
+
+		result = tx.Model(&models.SubathonTimer{}).
+			Where("user_id = ? AND status = ?", userID, models.SubathonStatusPaused).
+			Updates(map[string]interface{}{
+				"remaining_seconds":   pausedRemaining,
+				"total_added_seconds": gorm.Expr("total_added_seconds + ?", pausedAdded),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		affected += result.RowsAffected
+		if affected == 0 {
+			return nil
+		}
+
+		var after models.SubathonTimer
+		if err := tx.Select("total_added_seconds").First(&after, "user_id = ?", userID).Error; err != nil {
+			return err
+		}
+		added = after.TotalAddedSeconds - before.TotalAddedSeconds
+		return nil
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	return added, affected > 0, nil
+}
+
+// MarkExpired ends a running timer whose end time has passed
+func (r *SubathonRepository) MarkExpired(userID uuid.UUID) error {
+	return r.db.Model(&models.SubathonTimer{}).
+		Where("user_id = ? AND status = ? AND ends_at <= NOW()", userID, models.SubathonStatusRunning).
+		Updates(map[string]interface{}{
+			"status":   models.SubathonStatusEnded,
+			"ended_at": gorm.Expr("ends_at"),
+		}).Error
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type TickerRepository struct {
+	db *gorm.DB
+}
+
+func NewTickerRepository(db *gorm.DB) *TickerRepository {
+	return &TickerRepository{db: db}
+}
+
+// GetSettings returns the creator's ticker settings, or defaults if none are saved
+func (r *TickerRepository) GetSettings(userID uuid.UUID) (*models.TickerSettings, error) {
+	var settings models.TickerSettings
+	err := r.db.First(&settings, "user_id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultTickerSettings(userID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (r *TickerRepository) SaveSettings(settings *models.TickerSettings) error {
+	return r.db.Save(settings).Error
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type TwoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+func (r *TwoFactorRepository) FindByUserID(userID uuid.UUID) (*models.TwoFactor, error) {
+	var tf models.TwoFactor
+	err := r.db.First(&tf, "user_id = ?", userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tf, nil
+}
+
+// Save creates or replaces the enrollment
+func (r *TwoFactorRepository) Save(tf *models.TwoFactor) error {
+	return r.db.Save(tf).Error
+}
+
+// Delete removes the enrollment and its recovery codes
+func (r *TwoFactorRepository) Delete(userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.TwoFactor{}).Error
+	})
+}
+
+// UpdateSecret replaces the sealed secret, e.g. after a key rotation
+func (r *TwoFactorRepository) UpdateSecret(userID uuid.UUID, sealed string) error {
+	return r.db.Model(&models.TwoFactor{}).Where("user_id = ?", userID).Update("secret_encrypted", sealed).Error
+}
+
+// UseStep records that the code for step was used. Returns false if that
+// step (or a later one) was already used, i.e. the code is a replay.
+func (r *TwoFactorRepository) UseStep(userID uuid.UUID, step int64) (bool, error) {
+	result := r.db.Model(&models.TwoFactor{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ReplaceRecoveryCodes swaps all of the user's recovery codes for new ones
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(userID uuid.UUID, hashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]models.RecoveryCode, len(hashes))
+		for i, hash := range hashes {
+			codes[i] = models.RecoveryCode{UserID: userID, CodeHash: hash}
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode marks an unused code as used. Returns false if there is none.
+func (r *TwoFactorRepository) UseRecoveryCode(userID uuid.UUID, hash string) (bool, error) {
+	result := r.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *TwoFactorRepository) CountUnusedRecoveryCodes(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
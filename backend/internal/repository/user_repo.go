@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/jajanin/backend/internal/models"
 	"gorm.io/gorm"
@@ -58,12 +60,21 @@ func (r *UserRepository) Update(user *models.User) error {
 	return r.db.Save(user).Error
 }
 
+// CheckUsernameExists includes deleted accounts, which keep their username
+// until ReleaseUsername frees it
 func (r *UserRepository) CheckUsernameExists(username string, excludeID uuid.UUID) bool {
 	var count int64
-	r.db.Model(&models.User{}).Where("username = ? AND id != ?", username, excludeID).Count(&count)
+	r.db.Unscoped().Model(&models.User{}).Where("username = ? AND id != ?", username, excludeID).Count(&count)
 	return count > 0
 }
 
+// ReleaseUsername frees a username held by an account deleted before cutoff
+func (r *UserRepository) ReleaseUsername(username string, cutoff time.Time) error {
+	return r.db.Unscoped().Model(&models.User{}).
+		Where("username = ? AND deleted_at IS NOT NULL AND deleted_at < ?", username, cutoff).
+		Update("username", nil).Error
+}
+
 func (r *UserRepository) FindByStreamKey(streamKey string) (*models.User, error) {
 	var user models.User
 	err := r.db.First(&user, "stream_key = ?", streamKey).Error
@@ -72,3 +83,17 @@ func (r *UserRepository) FindByStreamKey(streamKey string) (*models.User, error)
 	}
 	return &user, nil
 }
+
+// FindByPreviousStreamKey finds a user whose regenerated stream key is still in its grace period
+func (r *UserRepository) FindByPreviousStreamKey(streamKey string) (*models.User, error) {
+	var user models.User
+	err := r.db.First(&user, "previous_stream_key = ? AND previous_stream_key_expires_at > ?", streamKey, time.Now()).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) UpdateRole(id uuid.UUID, role string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Update("role", role).Error
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/repository"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+// Personal access tokens are prefixed so they can be told apart from JWTs
+// (and spotted by secret scanners)
+const AccessTokenPrefix = "jjn_pat_"
+
+const (
+	maxAccessTokensPerUser   = 20
+	defaultAccessTokenDays   = 90
+	maxAccessTokenDays       = 365
+	accessTokenTouchInterval = time.Minute // last-used is written at most this often
+)
+
+var (
+	ErrInvalidAccessToken  = errors.New("invalid or expired access token")
+	ErrAccessTokenNotFound = errors.New("access token not found")
+	ErrAccessTokenLimit    = errors.New("too many access tokens, revoke one first")
+	ErrInvalidAccessScope  = errors.New("invalid scope, use any of: " + strings.Join(models.AccessTokenScopes, ", "))
+	ErrAccessTokenNoScopes = errors.New("choose at least one scope")
+	ErrAccessTokenLifetime = errors.New("expires_in_days must be between 1 and 365")
+)
+
+type AccessTokenService struct {
+	repo  *repository.AccessTokenRepository
+	audit *AuditService
+}
+
+func NewAccessTokenService(repo *repository.AccessTokenRepository, audit *AuditService) *AccessTokenService {
+	return &AccessTokenService{repo: repo, audit: audit}
+}
+
+type CreateAccessTokenInput struct {
+	Name          string   `json:"name" binding:"required,min=1,max=100"`
+	Scopes        []string `json:"scopes" binding:"required"`
+	ExpiresInDays *int     `json:"expires_in_days"` // defaults to 90
+}
+
+// CreateAccessTokenResponse includes the plain token, which is only shown once
+type CreateAccessTokenResponse struct {
+	Token       string                      `json:"token"`
+	AccessToken *models.PersonalAccessToken `json:"access_token"`
+}
+
+func hashAccessToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateAccessToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return AccessTokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// normalizeAccessScopes checks the requested scopes and drops duplicates
+func normalizeAccessScopes(scopes []string) ([]string, error) {
+	seen := map[string]bool{}
+	var result []string
+	for _, scope := range scopes {
+		if !models.IsValidAccessTokenScope(scope) {
+			return nil, ErrInvalidAccessScope
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+	if len(result) == 0 {
+		return nil, ErrAccessTokenNoScopes
+	}
+	return result, nil
+}
+
+// accessTokenExpiry turns the requested lifetime into an expiry date
+func accessTokenExpiry(days *int, now time.Time) (time.Time, error) {
+	lifetime := defaultAccessTokenDays
+	if days != nil {
+		lifetime = *days
+	}
+	if lifetime < 1 || lifetime > maxAccessTokenDays {
+		return time.Time{}, ErrAccessTokenLifetime
+	}
+	return now.AddDate(0, 0, lifetime), nil
+}
+
+func (s *AccessTokenService) Create(log *utils.RequestLogger, userID uuid.UUID, input *CreateAccessTokenInput) (*CreateAccessTokenResponse, error) {
+	scopes, err := normalizeAccessScopes(input.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt, err := accessTokenExpiry(input.ExpiresInDays, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.repo.CountActive(userID)
+	if err != nil {
+		return nil, errors.New("failed to count access tokens")
+	}
+	if count >= maxAccessTokensPerUser {
+		return nil, ErrAccessTokenLimit
+	}
+
+	plain, err := generateAccessToken()
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	token := &models.PersonalAccessToken{
+		UserID:      userID,
+		Name:        strings.TrimSpace(input.Name),
+		Scopes:      scopes,
+		TokenHash:   hashAccessToken(plain),
+		TokenPrefix: plain[:len(AccessTokenPrefix)+6],
+		ExpiresAt:   &expiresAt,
+	}
+	if err := s.repo.Create(token); err != nil {
+		return nil, errors.New("failed to create access token")
+	}
+
+	s.audit.Record(log, &models.AuditLog{
+		Action:   models.AuditAccessTokenCreated,
+		UserID:   &userID,
+		Metadata: map[string]interface{}{"token_id": token.ID, "name": token.Name, "scopes": scopes},
+	})
+
+	return &CreateAccessTokenResponse{Token: plain, AccessToken: token}, nil
+}
+
+func (s *AccessTokenService) GetAll(userID uuid.UUID) ([]models.PersonalAccessToken, error) {
+	return s.repo.FindByUserID(userID)
+}
+
+func (s *AccessTokenService) Revoke(log *utils.RequestLogger, userID, tokenID uuid.UUID) error {
+	ok, err := s.repo.Revoke(tokenID, userID)
+	if err != nil {
+		return errors.New("failed to revoke access token")
+	}
+	if !ok {
+		return ErrAccessTokenNotFound
+	}
+
+	s.audit.Record(log, &models.AuditLog{
+		Action:   models.AuditAccessTokenRevoked,
+		UserID:   &userID,
+		Metadata: map[string]interface{}{"token_id": tokenID},
+	})
+	return nil
+}
+
+// RevokeAll revokes every token of the user
+func (s *AccessTokenService) RevokeAll(userID uuid.UUID) error {
+	if err := s.repo.RevokeAllByUserID(userID); err != nil {
+		return errors.New("failed to revoke access tokens")
+	}
+	return nil
+}
+
+// IsAccessToken reports whether a bearer credential looks like a personal
+// access token rather than a JWT
+func IsAccessToken(credential string) bool {
+	return strings.HasPrefix(credential, AccessTokenPrefix)
+}
+
+// Authenticate returns the active, unexpired token for a plain credential
+// and records when and from where it was last used
+func (s *AccessTokenService) Authenticate(credential, ip string) (*models.PersonalAccessToken, error) {
+	if !IsAccessToken(credential) {
+		return nil, ErrInvalidAccessToken
+	}
+	token, err := s.repo.FindActiveByHash(hashAccessToken(credential))
+	if err != nil {
+		return nil, ErrInvalidAccessToken
+	}
+
+	now := time.Now()
+	if token.IsExpired(now) {
+		return nil, ErrInvalidAccessToken
+	}
+
+	// Bots poll often; don't write on every request
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > accessTokenTouchInterval || token.LastUsedIP != ip {
+		_ = s.repo.TouchLastUsed(token.ID, ip) // Not critical
+	}
+	return token, nil
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jajanin/backend/internal/models"
+)
+
+func TestGenerateAccessToken(t *testing.T) {
+	a, err := generateAccessToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := generateAccessToken()
+	if a == b {
+		t.Error("Expected unique tokens")
+	}
+	if !IsAccessToken(a) || !strings.HasPrefix(a, AccessTokenPrefix) {
+		t.Errorf("Expected the %s prefix, got %s", AccessTokenPrefix, a)
+	}
+	if hashAccessToken(a) == hashAccessToken(b) || len(hashAccessToken(a)) != 64 {
+		t.Error("Expected distinct SHA-256 hex hashes")
+	}
+	if IsAccessToken("eyJhbGciOiJIUzI1NiJ9.e30.sig") {
+		t.Error("Expected a JWT not to look like an access token")
+	}
+}
+
+func TestNormalizeAccessScopes(t *testing.T) {
+	scopes, err := normalizeAccessScopes([]string{models.ScopeStatsRead, models.ScopeDonationsRead, models.ScopeStatsRead})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scopes) != 2 || scopes[0] != models.ScopeStatsRead || scopes[1] != models.ScopeDonationsRead {
+		t.Errorf("Expected duplicates dropped in order, got %v", scopes)
+	}
+
+	if _, err := normalizeAccessScopes([]string{"withdrawals:write"}); !errors.Is(err, ErrInvalidAccessScope) {
+		t.Errorf("Expected ErrInvalidAccessScope, got %v", err)
+	}
+	if _, err := normalizeAccessScopes(nil); !errors.Is(err, ErrAccessTokenNoScopes) {
+		t.Errorf("Expected ErrAccessTokenNoScopes, got %v", err)
+	}
+}
+
+func TestAccessTokenExpiry(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	expires, err := accessTokenExpiry(nil, now)
+	if err != nil || !expires.Equal(now.AddDate(0, 0, defaultAccessTokenDays)) {
+		t.Errorf("Expected the default lifetime, got %s (%v)", expires, err)
+	}
+
+	days := 7
+	if expires, _ := accessTokenExpiry(&days, now); !expires.Equal(now.AddDate(0, 0, 7)) {
+		t.Errorf("Expected 7 days, got %s", expires)
+	}
+
+	for _, days := range []int{0, -1, maxAccessTokenDays + 1} {
+		if _, err := accessTokenExpiry(&days, now); !errors.Is(err, ErrAccessTokenLifetime) {
+			t.Errorf("Expected %d days to be rejected, got %v", days, err)
+		}
+	}
+
+	token := &models.PersonalAccessToken{ExpiresAt: &expires}
+	if token.IsExpired(now) || !token.IsExpired(expires) {
+		t.Error("Expected the token to expire exactly at ExpiresAt")
+	}
+}
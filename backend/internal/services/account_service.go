@@ -0,0 +1,429 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/mailer"
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/repository"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+var (
+	ErrInvalidUserToken     = errors.New("link is invalid or has expired")
+	ErrEmailAlreadyVerified = errors.New("email is already verified")
+	ErrWrongPassword        = errors.New("current password is incorrect")
+	ErrPasswordNotSet       = errors.New("set a password for your account first")
+	ErrSameEmail            = errors.New("this is already your email address")
+	ErrEmailTaken           = errors.New("email already registered")
+	ErrGoogleReauthRequired = errors.New("sign in with Google again to set a password")
+)
+
+// accountUserStore is the UserRepository as AccountService uses it, an
+// interface so tests can run without a database
+type accountUserStore interface {
+	FindByID(id uuid.UUID) (*models.User, error)
+	FindByEmail(email string) (*models.User, error)
+	Update(user *models.User) error
+}
+
+// userTokenStore is the UserTokenRepository as AccountService uses it
+type userTokenStore interface {
+	Create(token *models.UserToken) error
+	Consume(hash, purpose string) (*models.UserToken, error)
+	InvalidateByUserID(userID uuid.UUID, purpose string) error
+}
+
+// AccountService handles the email based account flows: verifying the
+// address, resetting a forgotten password and changing the email address
+// or password of a signed-in user
+type AccountService struct {
+	userRepo    accountUserStore
+	tokenRepo   userTokenStore
+	sessions    *SessionService
+	audit       *AuditService
+	google      *GoogleTokenVerifier
+	mail        mailer.Mailer
+	frontendURL string
+	verifyTTL   time.Duration
+	resetTTL    time.Duration
+}
+
+func NewAccountService(
+	userRepo *repository.UserRepository,
+	tokenRepo *repository.UserTokenRepository,
+	sessions *SessionService,
+	audit *AuditService,
+	google *GoogleTokenVerifier,
+	mail mailer.Mailer,
+	frontendURL string,
+	verifyTTL, resetTTL time.Duration,
+) *AccountService {
+	return &AccountService{
+		userRepo:    userRepo,
+		tokenRepo:   tokenRepo,
+		sessions:    sessions,
+		audit:       audit,
+		google:      google,
+		mail:        mail,
+		frontendURL: strings.TrimRight(frontendURL, "/"),
+		verifyTTL:   verifyTTL,
+		resetTTL:    resetTTL,
+	}
+}
+
+type VerifyEmailInput struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ForgotPasswordInput struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordInput struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+// ChangePasswordInput needs the current password. An account that only
+// signs in with Google proves it is the owner with a fresh Google ID token
+// instead when setting its first password.
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"current_password"`
+	GoogleIDToken   string `json:"google_id_token"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+type ChangeEmailInput struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+type ConfirmEmailChangeInput struct {
+	Token string `json:"token" binding:"required"`
+}
+
+func hashUserToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// issueToken replaces the user's outstanding tokens for purpose with a new
+// one for the given address
+func (s *AccountService) issueToken(user *models.User, email, purpose string, ttl time.Duration) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	plain := base64.RawURLEncoding.EncodeToString(b)
+
+	if err := s.tokenRepo.InvalidateByUserID(user.ID, purpose); err != nil {
+		return "", err
+	}
+	err := s.tokenRepo.Create(&models.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: hashUserToken(plain),
+		Email:     email,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	return plain, err
+}
+
+// formatTTL renders a duration for email copy, e.g. "48 jam" or "60 menit"
+func formatTTL(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		return fmt.Sprintf("%d jam", int(d.Hours()))
+	}
+	return fmt.Sprintf("%d menit", int(d.Minutes()))
+}
+
+// send renders and delivers an email in the background, so responses don't
+// wait on the mail server and don't reveal whether an address exists
+func (s *AccountService) send(log *utils.RequestLogger, template string, user *models.User, data map[string]string) {
+	s.sendTo(log, template, user.Email, user, data)
+}
+
+// sendTo is send to an address other than the user's current one
+func (s *AccountService) sendTo(log *utils.RequestLogger, template, to string, user *models.User, data map[string]string) {
+	data["Name"] = user.Name
+	msg, err := mailer.Render(template, to, data)
+	if err != nil {
+		log.LogError("AccountService.send", err, "Failed to render "+template+" email")
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if err := s.mail.Send(ctx, msg); err != nil {
+			log.LogError("AccountService.send", err, "Failed to send "+template+" email")
+		}
+	}()
+}
+
+// sendLink sends an email whose main content is a link that expires
+func (s *AccountService) sendLink(log *utils.RequestLogger, template string, user *models.User, url string, ttl time.Duration) {
+	s.send(log, template, user, map[string]string{
+		"URL":       url,
+		"ExpiresIn": formatTTL(ttl),
+	})
+}
+
+// SendVerificationEmail emails the user a link to verify their address
+func (s *AccountService) SendVerificationEmail(log *utils.RequestLogger, user *models.User) error {
+	if user.IsEmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+
+	token, err := s.issueToken(user, user.Email, models.UserTokenVerifyEmail, s.verifyTTL)
+	if err != nil {
+		return errors.New("failed to create verification link")
+	}
+	s.sendLink(log, mailer.TemplateVerifyEmail, user, s.frontendURL+"/verify-email?token="+token, s.verifyTTL)
+	return nil
+}
+
+// ResendVerificationEmail is SendVerificationEmail for the signed-in user
+func (s *AccountService) ResendVerificationEmail(log *utils.RequestLogger, userID uuid.UUID) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	return s.SendVerificationEmail(log, user)
+}
+
+// VerifyEmail marks the user's email as verified. The link only counts for
+// the address it was sent to.
+func (s *AccountService) VerifyEmail(input *VerifyEmailInput) (*models.User, error) {
+	token, err := s.tokenRepo.Consume(hashUserToken(input.Token), models.UserTokenVerifyEmail)
+	if err != nil {
+		return nil, ErrInvalidUserToken
+	}
+
+	user, err := s.userRepo.FindByID(token.UserID)
+	if err != nil || !strings.EqualFold(user.Email, token.Email) {
+		return nil, ErrInvalidUserToken
+	}
+
+	if !user.IsEmailVerified() {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+		if err := s.userRepo.Update(user); err != nil {
+			return nil, errors.New("failed to verify email")
+		}
+	}
+	return user, nil
+}
+
+// RequestPasswordReset emails a reset link if the address belongs to an
+// account. Unknown addresses are ignored without telling the caller.
+func (s *AccountService) RequestPasswordReset(log *utils.RequestLogger, input *ForgotPasswordInput) error {
+	user, err := s.userRepo.FindByEmail(strings.TrimSpace(input.Email))
+	if err != nil {
+		return nil
+	}
+
+	token, err := s.issueToken(user, user.Email, models.UserTokenResetPassword, s.resetTTL)
+	if err != nil {
+		return errors.New("failed to create reset link")
+	}
+	s.sendLink(log, mailer.TemplateResetPassword, user, s.frontendURL+"/reset-password?token="+token, s.resetTTL)
+	return nil
+}
+
+// ResetPassword sets a new password and signs the user out everywhere.
+// Opening the emailed link also proves the address, so it gets verified.
+func (s *AccountService) ResetPassword(input *ResetPasswordInput) error {
+	token, err := s.tokenRepo.Consume(hashUserToken(input.Token), models.UserTokenResetPassword)
+	if err != nil {
+		return ErrInvalidUserToken
+	}
+
+	user, err := s.userRepo.FindByID(token.UserID)
+	if err != nil || !strings.EqualFold(user.Email, token.Email) {
+		return ErrInvalidUserToken
+	}
+
+	hashedPassword, err := utils.HashPassword(input.Password)
+	if err != nil {
+		return errors.New("failed to hash password")
+	}
+	user.PasswordHash = hashedPassword
+	if !user.IsEmailVerified() {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	if err := s.userRepo.Update(user); err != nil {
+		return errors.New("failed to reset password")
+	}
+
+	if _, err := s.sessions.RevokeAll(user.ID, models.SessionRevokedPasswordReset); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ChangePassword sets a new password for the signed-in user and signs out
+// every other device. Accounts that only sign in with Google can use it to
+// set their first password.
+func (s *AccountService) ChangePassword(log *utils.RequestLogger, userID, sessionID uuid.UUID, input *ChangePasswordInput) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	firstPassword := user.PasswordHash == ""
+	if firstPassword {
+		// A session alone isn't enough: a stolen access token would
+		// otherwise become a password login that outlives the session
+		if err := s.reauthenticateGoogle(user, input.GoogleIDToken); err != nil {
+			return err
+		}
+	} else if !utils.CheckPassword(input.CurrentPassword, user.PasswordHash) {
+		return ErrWrongPassword
+	}
+
+	hashedPassword, err := utils.HashPassword(input.NewPassword)
+	if err != nil {
+		return errors.New("failed to hash password")
+	}
+	user.PasswordHash = hashedPassword
+	if err := s.userRepo.Update(user); err != nil {
+		return errors.New("failed to change password")
+	}
+
+	revoked, err := s.sessions.RevokeOthers(user.ID, sessionID, models.SessionRevokedPasswordChange)
+	if err != nil {
+		return err
+	}
+
+	s.audit.Record(log, &models.AuditLog{
+		Action:   models.AuditPasswordChanged,
+		UserID:   &user.ID,
+		Metadata: map[string]interface{}{"first_password": firstPassword, "sessions_revoked": revoked},
+	})
+	s.send(log, mailer.TemplatePasswordChanged, user, map[string]string{
+		"Time":     time.Now().In(jakarta).Format("02 Jan 2006 15:04 MST"),
+		"ResetURL": s.frontendURL + "/forgot-password",
+	})
+	return nil
+}
+
+// reauthenticateGoogle checks that idToken was issued to the Google
+// account linked to user
+func (s *AccountService) reauthenticateGoogle(user *models.User, idToken string) error {
+	if idToken == "" || user.GoogleID == nil {
+		return ErrGoogleReauthRequired
+	}
+	identity, err := s.google.Verify(idToken)
+	if err != nil || identity.Subject != *user.GoogleID {
+		return ErrGoogleReauthRequired
+	}
+	return nil
+}
+
+// RequestEmailChange sends a confirmation link to the new address and a
+// notice to the current one. The email only changes once the link is used.
+func (s *AccountService) RequestEmailChange(log *utils.RequestLogger, userID uuid.UUID, input *ChangeEmailInput) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if user.PasswordHash == "" {
+		return ErrPasswordNotSet
+	}
+	if !utils.CheckPassword(input.Password, user.PasswordHash) {
+		return ErrWrongPassword
+	}
+
+	email := strings.TrimSpace(input.Email)
+	if strings.EqualFold(email, user.Email) {
+		return ErrSameEmail
+	}
+	if _, err := s.userRepo.FindByEmail(email); err == nil {
+		return ErrEmailTaken
+	}
+
+	token, err := s.issueToken(user, email, models.UserTokenChangeEmail, s.verifyTTL)
+	if err != nil {
+		return errors.New("failed to create confirmation link")
+	}
+
+	s.audit.Record(log, &models.AuditLog{
+		Action:   models.AuditEmailChangeRequested,
+		UserID:   &user.ID,
+		Metadata: map[string]interface{}{"new_email": email},
+	})
+	s.sendTo(log, mailer.TemplateChangeEmail, email, user, map[string]string{
+		"URL":       s.frontendURL + "/confirm-email?token=" + token,
+		"ExpiresIn": formatTTL(s.verifyTTL),
+	})
+	s.send(log, mailer.TemplateEmailChangeRequested, user, map[string]string{
+		"NewEmail": email,
+		"URL":      s.frontendURL + "/dashboard/settings?tab=security",
+		"ResetURL": s.frontendURL + "/forgot-password",
+	})
+	return nil
+}
+
+// ConfirmEmailChange switches the account to the address the link was sent
+// to. Opening the link proves the new address, so it counts as verified.
+func (s *AccountService) ConfirmEmailChange(log *utils.RequestLogger, input *ConfirmEmailChangeInput) (*models.User, error) {
+	token, err := s.tokenRepo.Consume(hashUserToken(input.Token), models.UserTokenChangeEmail)
+	if err != nil {
+		return nil, ErrInvalidUserToken
+	}
+
+	user, err := s.userRepo.FindByID(token.UserID)
+	if err != nil {
+		return nil, ErrInvalidUserToken
+	}
+	// Someone may have registered the address since the link was sent
+	if existing, err := s.userRepo.FindByEmail(token.Email); err == nil && existing.ID != user.ID {
+		return nil, ErrEmailTaken
+	}
+
+	oldEmail := user.Email
+	now := time.Now()
+	user.Email = token.Email
+	user.EmailVerifiedAt = &now
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, errors.New("failed to change email")
+	}
+
+	// Links sent to the old address must not work any more
+	for _, purpose := range []string{models.UserTokenVerifyEmail, models.UserTokenResetPassword} {
+		_ = s.tokenRepo.InvalidateByUserID(user.ID, purpose) // They are checked against the email too
+	}
+
+	s.audit.Record(log, &models.AuditLog{
+		Action:   models.AuditEmailChanged,
+		UserID:   &user.ID,
+		Metadata: map[string]interface{}{"old_email": oldEmail, "new_email": user.Email},
+	})
+	return user, nil
+}
+
+// jakarta is the time zone used in emails
+var jakarta = time.FixedZone("WIB", 7*60*60)
+
+// NotifyNewLogin tells the user their account was signed in to from a new
+// device or network, with links to review sessions and reset the password
+func (s *AccountService) NotifyNewLogin(log *utils.RequestLogger, user *models.User, meta SessionMeta, at time.Time) {
+	s.send(log, mailer.TemplateNewLogin, user, map[string]string{
+		"Device":    models.DeviceNameFromUserAgent(meta.UserAgent),
+		"IPAddress": meta.IPAddress,
+		"Time":      at.In(jakarta).Format("02 Jan 2006 15:04 MST"),
+		"URL":       s.frontendURL + "/dashboard/settings?tab=security",
+		"ResetURL":  s.frontendURL + "/forgot-password",
+	})
+}
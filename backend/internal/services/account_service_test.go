@@ -0,0 +1,172 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// MockUserTokenRepository keeps tokens in memory. Like the repository, a
+// token is consumed only while it is unused and unexpired.
+type MockUserTokenRepository struct {
+	tokens map[string]*models.UserToken
+}
+
+var (
+	_ userTokenStore   = (*MockUserTokenRepository)(nil)
+	_ accountUserStore = (*MockUserRepository)(nil)
+)
+
+func NewMockUserTokenRepository() *MockUserTokenRepository {
+	return &MockUserTokenRepository{tokens: make(map[string]*models.UserToken)}
+}
+
+func (m *MockUserTokenRepository) Create(token *models.UserToken) error {
+	token.ID = uuid.New()
+	copied := *token
+	m.tokens[token.TokenHash] = &copied
+	return nil
+}
+
+func (m *MockUserTokenRepository) Consume(hash, purpose string) (*models.UserToken, error) {
+	token, ok := m.tokens[hash]
+	if !ok || token.Purpose != purpose || token.UsedAt != nil || !token.ExpiresAt.After(time.Now()) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	now := time.Now()
+	token.UsedAt = &now
+	copied := *token
+	return &copied, nil
+}
+
+func (m *MockUserTokenRepository) InvalidateByUserID(userID uuid.UUID, purpose string) error {
+	now := time.Now()
+	for _, token := range m.tokens {
+		if token.UserID == userID && token.Purpose == purpose && token.UsedAt == nil {
+			token.UsedAt = &now
+		}
+	}
+	return nil
+}
+
+func TestFormatTTL(t *testing.T) {
+	tests := []struct {
+		ttl  time.Duration
+		want string
+	}{
+		{48 * time.Hour, "48 jam"},
+		{time.Hour, "1 jam"},
+		{60 * time.Minute, "1 jam"},
+		{90 * time.Minute, "90 menit"},
+		{15 * time.Minute, "15 menit"},
+	}
+	for _, tt := range tests {
+		if got := formatTTL(tt.ttl); got != tt.want {
+			t.Errorf("formatTTL(%v) = %q, want %q", tt.ttl, got, tt.want)
+		}
+	}
+}
+
+func TestAccountService_ReauthenticateGoogle(t *testing.T) {
+	jwks := newTestJWKS(t, "key-1")
+	s := &AccountService{google: NewGoogleTokenVerifier(testGoogleClientID, jwks.server.URL)}
+
+	googleID := "1234567890"
+	user := &models.User{GoogleID: &googleID}
+	token := signGoogleToken(t, jwks.keys["key-1"], "key-1", validGoogleClaims())
+	if err := s.reauthenticateGoogle(user, token); err != nil {
+		t.Fatalf("Expected the linked Google account to pass, got %v", err)
+	}
+
+	otherID := "999"
+	for name, tt := range map[string]struct {
+		user  *models.User
+		token string
+	}{
+		"no token":        {user, ""},
+		"no Google link":  {&models.User{}, token},
+		"another account": {&models.User{GoogleID: &otherID}, token},
+		"invalid token":   {user, "not-a-token"},
+	} {
+		if err := s.reauthenticateGoogle(tt.user, tt.token); !errors.Is(err, ErrGoogleReauthRequired) {
+			t.Errorf("%s: expected ErrGoogleReauthRequired, got %v", name, err)
+		}
+	}
+}
+
+func TestAccountService_VerifyEmailTokenWorksOnce(t *testing.T) {
+	mockUserRepo := NewMockUserRepository()
+	s := &AccountService{userRepo: mockUserRepo, tokenRepo: NewMockUserTokenRepository(), verifyTTL: time.Hour}
+
+	user := &models.User{Email: "budi@example.com"}
+	mockUserRepo.AddUser(user)
+
+	stale, err := s.issueToken(user, user.Email, models.UserTokenVerifyEmail, s.verifyTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := s.issueToken(user, user.Email, models.UserTokenVerifyEmail, s.verifyTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.VerifyEmail(&VerifyEmailInput{Token: stale}); !errors.Is(err, ErrInvalidUserToken) {
+		t.Errorf("Expected a replaced token to be refused, got %v", err)
+	}
+
+	verified, err := s.VerifyEmail(&VerifyEmailInput{Token: token})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verified.IsEmailVerified() {
+		t.Error("Expected the email to be verified")
+	}
+
+	if _, err := s.VerifyEmail(&VerifyEmailInput{Token: token}); !errors.Is(err, ErrInvalidUserToken) {
+		t.Errorf("Expected a used token to be refused, got %v", err)
+	}
+}
+
+func TestAccountService_VerifyEmailAfterEmailChange(t *testing.T) {
+	mockUserRepo := NewMockUserRepository()
+	s := &AccountService{userRepo: mockUserRepo, tokenRepo: NewMockUserTokenRepository(), verifyTTL: time.Hour}
+
+	user := &models.User{Email: "budi@example.com"}
+	mockUserRepo.AddUser(user)
+	token, err := s.issueToken(user, user.Email, models.UserTokenVerifyEmail, s.verifyTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user.Email = "budi@baru.example.com"
+	if err := mockUserRepo.Update(user); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.VerifyEmail(&VerifyEmailInput{Token: token}); !errors.Is(err, ErrInvalidUserToken) {
+		t.Errorf("Expected a token for the old address to be refused, got %v", err)
+	}
+	if user.IsEmailVerified() {
+		t.Error("Expected the new address to stay unverified")
+	}
+}
+
+func TestCreateWithdrawal_RefusesUnverifiedEmail(t *testing.T) {
+	users := NewMockUserRepository()
+	user := &models.User{
+		Email:       "budi@example.com",
+		BankName:    "BCA",
+		BankAccount: "1234567890",
+		BankHolder:  "Budi",
+	}
+	users.AddUser(user)
+	s := &WithdrawalService{userRepo: users}
+
+	_, err := s.CreateWithdrawal(user.ID, &CreateWithdrawalInput{Amount: MinWithdrawalAmount})
+	if !errors.Is(err, ErrEmailNotVerified) {
+		t.Errorf("Expected ErrEmailNotVerified, got %v", err)
+	}
+}
@@ -1,73 +1,67 @@
 package services
 
 import (
-	"sync"
-
+	"github.com/google/uuid"
 	"github.com/jajanin/backend/internal/utils"
 )
 
+// AlertData is the payload of donation and test events
 type AlertData struct {
-	SupporterName string `json:"supporter_name"`
-	Amount        int64  `json:"amount"`
-	Message       string `json:"message"`
-	CreatorName   string `json:"creator_name"`
-	ProductName   string `json:"product_name,omitempty"`
-	ProductEmoji  string `json:"product_emoji,omitempty"`
-	Quantity      int    `json:"quantity"`
+	DonationID    *uuid.UUID `json:"donation_id,omitempty"` // nil for test alerts
+	SupporterName string     `json:"supporter_name"`
+	Amount        int64      `json:"amount"`
+	Message       string     `json:"message"`
+	CreatorName   string     `json:"creator_name"`
+	ProductName   string     `json:"product_name,omitempty"`
+	ProductEmoji  string     `json:"product_emoji,omitempty"`
+	Quantity      int        `json:"quantity"`
+	PollOption    string     `json:"poll_option,omitempty"`
 }
 
+// AlertService fans out overlay events to the creator's alert overlays
 type AlertService struct {
-	clients map[string][]chan *AlertData
-	mu      sync.RWMutex
+	subs *Subscribers
 }
 
-func NewAlertService() *AlertService {
-	return &AlertService{
-		clients: make(map[string][]chan *AlertData),
-	}
+func NewAlertService(subs *Subscribers) *AlertService {
+	return &AlertService{subs: subs}
 }
 
-func (s *AlertService) Register(username string, ch chan *AlertData) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.clients[username] = append(s.clients[username], ch)
+func alertKey(userID string) string {
+	return "alert:" + userID
 }
 
-func (s *AlertService) Unregister(username string, ch chan *AlertData) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	channels := s.clients[username]
-	for i, c := range channels {
-		if c == ch {
-			s.clients[username] = append(channels[:i], channels[i+1:]...)
-			close(ch)
-			return
-		}
-	}
+// Subscribe registers an alert overlay connection for the creator
+func (s *AlertService) Subscribe(userID uuid.UUID) *Subscriber {
+	return s.subs.Subscribe(userID, alertKey(userID.String()))
 }
 
-func (s *AlertService) Broadcast(log *utils.RequestLogger, username string, alert *AlertData) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+func (s *AlertService) Unsubscribe(sub *Subscriber) {
+	s.subs.Unsubscribe(sub)
+}
 
-	channels := s.clients[username]
-	if len(channels) == 0 {
-		return
+// Publish pushes the event to every alert overlay connected for the user and
+// returns how many clients received it
+func (s *AlertService) Publish(log *utils.RequestLogger, userID string, event *OverlayEvent) int {
+	clients, delivered := s.subs.Publish(alertKey(userID), event)
+	if clients == 0 {
+		return 0
 	}
 
-	for _, ch := range channels {
-		select {
-		case ch <- alert:
-		default:
-		}
+	log.Info().Str("user_id", userID).Str("event", event.Type).Int("clients", clients).
+		Int("delivered", delivered).Msg("Overlay event published")
+	if delivered < clients {
+		log.Warn().Str("user_id", userID).Str("event", event.Type).Int("dropped", clients-delivered).
+			Msg("Overlay event dropped, client buffer full")
 	}
+	return delivered
+}
 
-	log.Info().Str("user", username).Int("clients", len(channels)).Msg("Alert broadcast")
+// Stats returns the creator's connection counters (alert overlays and widgets)
+func (s *AlertService) Stats(userID uuid.UUID) StreamStats {
+	return s.subs.CreatorStats(userID)
 }
 
-func (s *AlertService) GetClientCount(username string) int {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return len(s.clients[username])
+func (s *AlertService) GetClientCount(userID string) int {
+	return s.subs.Count(alertKey(userID))
 }
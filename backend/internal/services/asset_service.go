@@ -0,0 +1,230 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/config"
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/repository"
+	"github.com/jajanin/backend/internal/storage"
+)
+
+// Per-file upload limits
+const (
+	MaxSoundAssetSize = 2 << 20 // 2 MB
+	MaxImageAssetSize = 5 << 20 // 5 MB
+	MaxAssetSize      = MaxImageAssetSize
+)
+
+var (
+	ErrUnsupportedAssetType = errors.New("unsupported file type, allowed: mp3, ogg, wav, gif, webp")
+	ErrAssetTooLarge        = errors.New("file is too large")
+	ErrAssetQuotaExceeded   = errors.New("upload quota exceeded")
+	ErrInvalidAssetURL      = errors.New("invalid or expired asset URL")
+)
+
+type AssetService struct {
+	assetRepo  *repository.AssetRepository
+	store      storage.Storage
+	quotaBytes int64
+	urlSecret  []byte
+	urlTTL     time.Duration
+	baseURL    string
+	now        func() time.Time
+}
+
+func NewAssetService(assetRepo *repository.AssetRepository, store storage.Storage, cfg *config.Config) *AssetService {
+	return &AssetService{
+		assetRepo:  assetRepo,
+		store:      store,
+		quotaBytes: int64(cfg.UploadQuotaMB) << 20,
+		urlSecret:  []byte(cfg.AssetURLSecret),
+		urlTTL:     time.Duration(cfg.AssetURLTTLHours) * time.Hour,
+		baseURL:    strings.TrimRight(cfg.AppURL, "/"),
+		now:        time.Now,
+	}
+}
+
+// DetectAssetType sniffs the file header. The client-supplied Content-Type
+// and extension are ignored so a renamed file can't slip through.
+func DetectAssetType(data []byte) (contentType, kind string, ok bool) {
+	switch {
+	case bytes.HasPrefix(data, []byte("ID3")),
+		len(data) >= 2 && data[0] == 0xFF && data[1]&0xE0 == 0xE0: // MPEG frame sync
+		return "audio/mpeg", models.AssetKindSound, true
+	case bytes.HasPrefix(data, []byte("OggS")):
+		return "audio/ogg", models.AssetKindSound, true
+	case len(data) >= 12 && bytes.HasPrefix(data, []byte("RIFF")) && string(data[8:12]) == "WAVE":
+		return "audio/wav", models.AssetKindSound, true
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return "image/gif", models.AssetKindImage, true
+	case len(data) >= 12 && bytes.HasPrefix(data, []byte("RIFF")) && string(data[8:12]) == "WEBP":
+		return "image/webp", models.AssetKindImage, true
+	}
+	return "", "", false
+}
+
+func maxSizeFor(kind string) int64 {
+	if kind == models.AssetKindSound {
+		return MaxSoundAssetSize
+	}
+	return MaxImageAssetSize
+}
+
+// Upload validates and stores a new asset for the creator
+func (s *AssetService) Upload(ctx context.Context, userID uuid.UUID, filename string, data []byte) (*models.Asset, error) {
+	contentType, kind, ok := DetectAssetType(data)
+	if !ok {
+		return nil, ErrUnsupportedAssetType
+	}
+	if int64(len(data)) > maxSizeFor(kind) {
+		return nil, fmt.Errorf("%w (max %d MB for %s)", ErrAssetTooLarge, maxSizeFor(kind)>>20, kind)
+	}
+
+	// Checked again when saving; this only avoids storing a file that
+	// obviously won't fit
+	used, err := s.assetRepo.SumSizeByUserID(userID)
+	if err != nil {
+		return nil, errors.New("failed to check upload quota")
+	}
+	if used+int64(len(data)) > s.quotaBytes {
+		return nil, ErrAssetQuotaExceeded
+	}
+
+	asset := &models.Asset{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Kind:        kind,
+		Filename:    filepath.Base(filename),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+	}
+	asset.StorageKey = "assets/" + userID.String() + "/" + asset.ID.String()
+
+	if err := s.store.Put(ctx, asset.StorageKey, contentType, data); err != nil {
+		return nil, errors.New("failed to store file")
+	}
+	created, err := s.assetRepo.CreateWithinQuota(asset, s.quotaBytes)
+	if err != nil {
+		s.store.Delete(ctx, asset.StorageKey)
+		return nil, errors.New("failed to save asset")
+	}
+	if !created {
+		s.store.Delete(ctx, asset.StorageKey)
+		return nil, ErrAssetQuotaExceeded
+	}
+
+	asset.URL = s.SignedURL(asset.ID)
+	return asset, nil
+}
+
+// GetAll returns the creator's assets with signed URLs and quota usage
+func (s *AssetService) GetAll(userID uuid.UUID) ([]models.Asset, *models.AssetUsage, error) {
+	assets, err := s.assetRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	usage := &models.AssetUsage{QuotaBytes: s.quotaBytes}
+	for i := range assets {
+		assets[i].URL = s.SignedURL(assets[i].ID)
+		usage.UsedBytes += assets[i].Size
+	}
+	return assets, usage, nil
+}
+
+// Delete removes the asset and its stored file
+func (s *AssetService) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	asset, err := s.assetRepo.FindByIDAndUserID(id, userID)
+	if err != nil {
+		return errors.New("asset not found")
+	}
+
+	if err := s.store.Delete(ctx, asset.StorageKey); err != nil {
+		return errors.New("failed to delete file")
+	}
+	return s.assetRepo.Delete(asset.ID)
+}
+
+// SignedURL returns a time-limited public URL for the asset. Expiry is
+// rounded up to the hour so the URL stays stable (and cacheable) for a while.
+func (s *AssetService) SignedURL(id uuid.UUID) string {
+	exp := s.now().Add(s.urlTTL).Truncate(time.Hour).Add(time.Hour).Unix()
+	expStr := strconv.FormatInt(exp, 10)
+
+	query := url.Values{}
+	query.Set("exp", expStr)
+	query.Set("sig", s.sign(id, expStr))
+	return s.baseURL + "/assets/" + id.String() + "?" + query.Encode()
+}
+
+func (s *AssetService) sign(id uuid.UUID, exp string) string {
+	mac := hmac.New(sha256.New, s.urlSecret)
+	mac.Write([]byte(id.String() + "." + exp))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Open verifies a signed URL and returns the asset with its content.
+// The caller must close the reader.
+func (s *AssetService) Open(ctx context.Context, id uuid.UUID, exp, sig string) (*models.Asset, io.ReadCloser, time.Time, error) {
+	expUnix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return nil, nil, time.Time{}, ErrInvalidAssetURL
+	}
+	expiresAt := time.Unix(expUnix, 0)
+	if s.now().After(expiresAt) || !hmac.Equal([]byte(sig), []byte(s.sign(id, exp))) {
+		return nil, nil, time.Time{}, ErrInvalidAssetURL
+	}
+
+	asset, err := s.assetRepo.FindByID(id)
+	if err != nil {
+		return nil, nil, time.Time{}, errors.New("asset not found")
+	}
+
+	rc, err := s.store.Get(ctx, asset.StorageKey)
+	if err != nil {
+		return nil, nil, time.Time{}, errors.New("asset not found")
+	}
+	return asset, rc, expiresAt, nil
+}
+
+// ResolveSettingsURLs fills in signed URLs for the assets referenced by the
+// creator's alert settings. Missing or deleted assets are skipped so the
+// overlay falls back to the preset sound.
+func (s *AssetService) ResolveSettingsURLs(userID uuid.UUID, settings *models.AlertSettings) {
+	settings.SoundURL = s.resolveURL(userID, settings.SoundAssetID, models.AssetKindSound)
+	settings.ImageURL = s.resolveURL(userID, settings.ImageAssetID, models.AssetKindImage)
+}
+
+func (s *AssetService) resolveURL(userID uuid.UUID, id *uuid.UUID, kind string) string {
+	if id == nil {
+		return ""
+	}
+	asset, err := s.assetRepo.FindByIDAndUserID(*id, userID)
+	if err != nil || asset.Kind != kind {
+		return ""
+	}
+	return s.SignedURL(asset.ID)
+}
+
+// Origin is the scheme://host asset URLs are served from (for CSP headers)
+func (s *AssetService) Origin() string {
+	u, err := url.Parse(s.baseURL)
+	if err != nil {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
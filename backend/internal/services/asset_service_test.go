@@ -0,0 +1,73 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+)
+
+func TestDetectAssetType(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		contentType string
+		kind        string
+	}{
+		{"mp3 with ID3", []byte("ID3\x04\x00rest"), "audio/mpeg", models.AssetKindSound},
+		{"mp3 frame sync", []byte{0xFF, 0xFB, 0x90, 0x00}, "audio/mpeg", models.AssetKindSound},
+		{"ogg", []byte("OggS\x00\x02"), "audio/ogg", models.AssetKindSound},
+		{"wav", []byte("RIFF\x24\x08\x00\x00WAVEfmt "), "audio/wav", models.AssetKindSound},
+		{"gif", []byte("GIF89a\x01\x00"), "image/gif", models.AssetKindImage},
+		{"webp", []byte("RIFF\x24\x08\x00\x00WEBPVP8 "), "image/webp", models.AssetKindImage},
+	}
+
+	for _, tt := range tests {
+		contentType, kind, ok := DetectAssetType(tt.data)
+		if !ok || contentType != tt.contentType || kind != tt.kind {
+			t.Errorf("%s: got (%s, %s, %v)", tt.name, contentType, kind, ok)
+		}
+	}
+
+	rejected := [][]byte{
+		[]byte("\x89PNG\r\n\x1a\n"),
+		[]byte("<svg xmlns="),
+		[]byte("RIFF\x24\x08\x00\x00AVI "),
+		{},
+	}
+	for _, data := range rejected {
+		if _, _, ok := DetectAssetType(data); ok {
+			t.Errorf("Expected %q to be rejected", data)
+		}
+	}
+}
+
+func TestAssetService_SignedURL(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	s := &AssetService{
+		urlSecret: []byte("secret"),
+		urlTTL:    time.Hour,
+		baseURL:   "https://api.jajanin.test",
+		now:       func() time.Time { return now },
+	}
+	id := uuid.New()
+
+	first := s.SignedURL(id)
+	now = now.Add(10 * time.Minute)
+	if second := s.SignedURL(id); second != first {
+		t.Errorf("Expected URL to be stable within the hour:\n%s\n%s", first, second)
+	}
+
+	exp := "1714564800" // 12:00 UTC: 10:30 + 1h, rounded up to the hour
+	if !strings.Contains(first, "exp="+exp) {
+		t.Errorf("Expected expiry %s in %s", exp, first)
+	}
+	if s.sign(id, exp) == s.sign(uuid.New(), exp) {
+		t.Error("Expected signature to depend on asset ID")
+	}
+	if s.sign(id, exp) == s.sign(id, "1714568400") {
+		t.Error("Expected signature to depend on expiry")
+	}
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/repository"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+type AuditService struct {
+	repo *repository.AuditLogRepository
+}
+
+func NewAuditService(repo *repository.AuditLogRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// Record stores an audit entry. A failure is logged but not returned, since
+// the event being recorded has already happened.
+func (s *AuditService) Record(log *utils.RequestLogger, entry *models.AuditLog) {
+	if err := s.repo.Create(entry); err != nil {
+		log.LogError("AuditService.Record", err, "Failed to write audit log: "+entry.Action)
+	}
+}
+
+// AuditLogPage is one page of the admin audit log
+type AuditLogPage struct {
+	Entries    []models.AuditLog `json:"entries"`
+	Total      int64             `json:"total"`
+	Page       int               `json:"page"`
+	Limit      int               `json:"limit"`
+	TotalPages int64             `json:"total_pages"`
+}
+
+func (s *AuditService) List(filter repository.AuditLogFilter, page, limit int) (*AuditLogPage, error) {
+	entries, total, err := s.repo.FindAll(filter, limit, (page-1)*limit)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditLogPage{
+		Entries:    entries,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: (total + int64(limit) - 1) / int64(limit),
+	}, nil
+}
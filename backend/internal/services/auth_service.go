@@ -2,6 +2,7 @@ package services
 
 import (
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jajanin/backend/internal/models"
@@ -11,11 +12,16 @@ import (
 )
 
 type AuthService struct {
-	userRepo *repository.UserRepository
+	userRepo     *repository.UserRepository
+	sessions     *SessionService
+	google       *GoogleTokenVerifier
+	twoFactor    *TwoFactorService
+	guard        *LoginGuardService
+	accessTokens *AccessTokenService
 }
 
-func NewAuthService(userRepo *repository.UserRepository) *AuthService {
-	return &AuthService{userRepo: userRepo}
+func NewAuthService(userRepo *repository.UserRepository, sessions *SessionService, google *GoogleTokenVerifier, twoFactor *TwoFactorService, guard *LoginGuardService, accessTokens *AccessTokenService) *AuthService {
+	return &AuthService{userRepo: userRepo, sessions: sessions, google: google, twoFactor: twoFactor, guard: guard, accessTokens: accessTokens}
 }
 
 type RegisterInput struct {
@@ -37,12 +43,85 @@ func derefString(s *string) string {
 	return *s
 }
 
+// AuthResponse either signs the user in or, when two-factor authentication
+// is enabled, asks for a code with ChallengeToken and no tokens yet
 type AuthResponse struct {
-	Token string       `json:"token"`
-	User  *models.User `json:"user"`
+	*TokenPair
+	TwoFactorRequired bool         `json:"two_factor_required,omitempty"`
+	ChallengeToken    string       `json:"challenge_token,omitempty"`
+	User              *models.User `json:"user,omitempty"`
 }
 
-func (s *AuthService) Register(input *RegisterInput) (*AuthResponse, error) {
+var ErrInvalidChallenge = errors.New("login challenge is invalid or has expired, please sign in again")
+
+type TwoFactorLoginInput struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// signIn starts a session, or returns a challenge if the user has
+// two-factor authentication enabled
+func (s *AuthService) signIn(log *utils.RequestLogger, user *models.User, meta SessionMeta, method string) (*AuthResponse, error) {
+	enabled, err := s.twoFactor.IsEnabled(user.ID)
+	if err != nil {
+		return nil, errors.New("failed to check two-factor status")
+	}
+	if enabled {
+		challenge, err := s.twoFactor.NewChallenge(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &AuthResponse{TwoFactorRequired: true, ChallengeToken: challenge}, nil
+	}
+
+	// Start a session on this device
+	tokens, err := s.sessions.Start(user, meta)
+	if err != nil {
+		return nil, err
+	}
+	s.guard.Succeeded(log, user, meta, method)
+
+	return &AuthResponse{
+		TokenPair: tokens,
+		User:      user,
+	}, nil
+}
+
+// CompleteTwoFactorLogin finishes a login that returned a challenge. Wrong
+// codes count towards the lockout like wrong passwords.
+func (s *AuthService) CompleteTwoFactorLogin(log *utils.RequestLogger, input *TwoFactorLoginInput, meta SessionMeta) (*AuthResponse, error) {
+	userID, err := utils.ValidateChallengeToken(input.ChallengeToken)
+	if err != nil {
+		return nil, ErrInvalidChallenge
+	}
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, ErrInvalidChallenge
+	}
+	if err := s.guard.Check(user.Email); err != nil {
+		return nil, err
+	}
+	if err := s.twoFactor.Verify(user.ID, input.Code); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			s.guard.Failed(log, user.Email, &user.ID, meta, "wrong_two_factor_code")
+		}
+		return nil, err
+	}
+
+	meta.TwoFactorVerified = true
+	tokens, err := s.sessions.Start(user, meta)
+	if err != nil {
+		return nil, err
+	}
+	s.guard.Succeeded(log, user, meta, "two_factor")
+
+	return &AuthResponse{
+		TokenPair: tokens,
+		User:      user,
+	}, nil
+}
+
+func (s *AuthService) Register(log *utils.RequestLogger, input *RegisterInput, meta SessionMeta) (*AuthResponse, error) {
 	// Check if email exists
 	existingUser, err := s.userRepo.FindByEmail(input.Email)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
@@ -70,23 +149,30 @@ func (s *AuthService) Register(input *RegisterInput) (*AuthResponse, error) {
 		return nil, errors.New("failed to create user")
 	}
 
-	// Generate token
-	token, err := utils.GenerateToken(user.ID, user.Email, derefString(user.Username))
+	// Start a session on this device
+	tokens, err := s.sessions.Start(user, meta)
 	if err != nil {
-		return nil, errors.New("failed to generate token")
+		return nil, err
 	}
+	s.guard.RecordDevice(log, user.ID, meta)
 
 	return &AuthResponse{
-		Token: token,
-		User:  user,
+		TokenPair: tokens,
+		User:      user,
 	}, nil
 }
 
-func (s *AuthService) Login(input *LoginInput) (*AuthResponse, error) {
+func (s *AuthService) Login(log *utils.RequestLogger, input *LoginInput, meta SessionMeta) (*AuthResponse, error) {
+	// Refuse locked addresses before looking at the password
+	if err := s.guard.Check(input.Email); err != nil {
+		return nil, err
+	}
+
 	// Find user by email
 	user, err := s.userRepo.FindByEmail(input.Email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.guard.Failed(log, input.Email, nil, meta, "unknown_email")
 			return nil, errors.New("invalid email or password")
 		}
 		return nil, errors.New("failed to find user")
@@ -94,53 +180,57 @@ func (s *AuthService) Login(input *LoginInput) (*AuthResponse, error) {
 
 	// Check password
 	if !utils.CheckPassword(input.Password, user.PasswordHash) {
+		s.guard.Failed(log, input.Email, &user.ID, meta, "wrong_password")
 		return nil, errors.New("invalid email or password")
 	}
 
-	// Generate token
-	token, err := utils.GenerateToken(user.ID, user.Email, derefString(user.Username))
-	if err != nil {
-		return nil, errors.New("failed to generate token")
-	}
-
-	return &AuthResponse{
-		Token: token,
-		User:  user,
-	}, nil
+	return s.signIn(log, user, meta, "password")
 }
 
 type GoogleAuthInput struct {
-	GoogleID string `json:"google_id" binding:"required"`
-	Email    string `json:"email" binding:"required,email"`
-	Name     string `json:"name"`
-	ImageURL string `json:"image_url"`
+	// IDToken is the credential returned by Google Identity Services
+	IDToken string `json:"id_token" binding:"required"`
 }
 
-func (s *AuthService) GoogleAuth(input *GoogleAuthInput) (*AuthResponse, error) {
+func (s *AuthService) GoogleAuth(log *utils.RequestLogger, input *GoogleAuthInput, meta SessionMeta) (*AuthResponse, error) {
+	// The identity comes only from the verified token, never from the client
+	identity, err := s.google.Verify(input.IDToken)
+	if err != nil {
+		return nil, err
+	}
+
 	// Try to find existing user by Google ID
-	user, err := s.userRepo.FindByGoogleID(input.GoogleID)
+	user, err := s.userRepo.FindByGoogleID(identity.Subject)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, errors.New("failed to find user")
 	}
 
 	// If user exists, update their image URL from Google (in case it changed)
-	if user != nil && input.ImageURL != "" && user.ImageURL != input.ImageURL {
-		user.ImageURL = input.ImageURL
+	if user != nil && identity.Picture != "" && user.ImageURL != identity.Picture {
+		user.ImageURL = identity.Picture
 		_ = s.userRepo.Update(user) // Ignore error, not critical
 	}
 
 	// If user doesn't exist, create new one
 	if user == nil {
+		now := time.Now()
 		// Check if email is already used
-		existingUser, findErr := s.userRepo.FindByEmail(input.Email)
+		existingUser, findErr := s.userRepo.FindByEmail(identity.Email)
 		if findErr != nil && !errors.Is(findErr, gorm.ErrRecordNotFound) {
 			return nil, errors.New("failed to check email availability")
 		}
 		if existingUser != nil {
-			// Link Google account to existing user
-			existingUser.GoogleID = &input.GoogleID
-			if existingUser.ImageURL == "" && input.ImageURL != "" {
-				existingUser.ImageURL = input.ImageURL
+			// Link Google account to existing user. Google verified the
+			// address, which also verifies it for us.
+			existingUser.GoogleID = &identity.Subject
+			if !existingUser.IsEmailVerified() {
+				if err := s.releaseUnverified(existingUser); err != nil {
+					return nil, errors.New("failed to link Google account")
+				}
+				existingUser.EmailVerifiedAt = &now
+			}
+			if existingUser.ImageURL == "" && identity.Picture != "" {
+				existingUser.ImageURL = identity.Picture
 			}
 			// Generate stream key if not exists
 			if existingUser.StreamKey == "" {
@@ -153,11 +243,12 @@ func (s *AuthService) GoogleAuth(input *GoogleAuthInput) (*AuthResponse, error)
 		} else {
 			// Create new user with stream key
 			user = &models.User{
-				Email:     input.Email,
-				Name:      input.Name,
-				GoogleID:  &input.GoogleID,
-				ImageURL:  input.ImageURL,
-				StreamKey: uuid.New().String(),
+				Email:           identity.Email,
+				EmailVerifiedAt: &now,
+				Name:            identity.Name,
+				GoogleID:        &identity.Subject,
+				ImageURL:        identity.Picture,
+				StreamKey:       uuid.New().String(),
 			}
 			if err := s.userRepo.Create(user); err != nil {
 				return nil, errors.New("failed to create user")
@@ -165,16 +256,22 @@ func (s *AuthService) GoogleAuth(input *GoogleAuthInput) (*AuthResponse, error)
 		}
 	}
 
-	// Generate token
-	token, err := utils.GenerateToken(user.ID, user.Email, derefString(user.Username))
-	if err != nil {
-		return nil, errors.New("failed to generate token")
-	}
+	return s.signIn(log, user, meta, "google")
+}
 
-	return &AuthResponse{
-		Token: token,
-		User:  user,
-	}, nil
+// releaseUnverified prepares an unverified account for the Google user who
+// proved they own its address. Whoever registered it never did, so their
+// password, sessions, two-factor enrollment and access tokens must not
+// carry over. The caller saves the cleared password.
+func (s *AuthService) releaseUnverified(user *models.User) error {
+	user.PasswordHash = ""
+	if _, err := s.sessions.RevokeAll(user.ID, models.SessionRevokedAccountClaimed); err != nil {
+		return err
+	}
+	if err := s.twoFactor.Reset(user.ID); err != nil {
+		return err
+	}
+	return s.accessTokens.RevokeAll(user.ID)
 }
 
 func (s *AuthService) GetCurrentUser(userID uuid.UUID) (*models.User, error) {
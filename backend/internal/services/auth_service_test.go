@@ -3,6 +3,7 @@ package services
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jajanin/backend/internal/models"
@@ -18,6 +19,8 @@ type MockUserRepository struct {
 	updateErr   error
 }
 
+var _ userStore = (*MockUserRepository)(nil)
+
 func NewMockUserRepository() *MockUserRepository {
 	return &MockUserRepository{
 		users:       make(map[string]*models.User),
@@ -90,6 +93,23 @@ func (m *MockUserRepository) CheckUsernameExists(username string, excludeID uuid
 	return false
 }
 
+func (m *MockUserRepository) FindByStreamKey(streamKey string) (*models.User, error) {
+	for _, user := range m.users {
+		if user.StreamKey == streamKey {
+			return user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MockUserRepository) FindByPreviousStreamKey(streamKey string) (*models.User, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MockUserRepository) ReleaseUsername(username string, cutoff time.Time) error {
+	return nil
+}
+
 // AddUser helper to seed test data
 func (m *MockUserRepository) AddUser(user *models.User) {
 	if user.ID == uuid.Nil {
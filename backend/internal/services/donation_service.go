@@ -10,22 +10,62 @@ import (
 	"github.com/jajanin/backend/internal/utils"
 )
 
+// PaidDonationListener is notified after a donation is marked as paid
+// (goal progress, leaderboards, ...)
+type PaidDonationListener interface {
+	OnDonationPaid(log *utils.RequestLogger, donation *models.Donation)
+}
+
+// ApprovedAlertListener is notified after a held alert is approved
+type ApprovedAlertListener interface {
+	OnAlertApproved(log *utils.RequestLogger, donation *models.Donation)
+}
+
+// donationStore is the DonationRepository as the service uses it, an
+// interface so tests can run without a database
+type donationStore interface {
+	Create(donation *models.Donation) error
+	FindProductByID(id uuid.UUID) (*models.QuickItem, error)
+	FindByID(id uuid.UUID) (*models.Donation, error)
+	FindByPaymentID(paymentID string) (*models.Donation, error)
+	FindByCreatorID(creatorID uuid.UUID, limit, offset int) ([]models.Donation, error)
+	CountByCreatorID(creatorID uuid.UUID) (int64, error)
+	FindRecentByCreatorID(creatorID uuid.UUID, limit int) ([]models.Donation, error)
+	FindRecentVisibleByCreatorID(creatorID uuid.UUID, limit int) ([]models.Donation, error)
+	Update(donation *models.Donation) error
+	UpdatePaymentStatus(id uuid.UUID, status models.PaymentStatus) (bool, error)
+	UpdateAlertStatus(id uuid.UUID, status models.AlertStatus) error
+	FindPendingReviewByCreatorID(creatorID uuid.UUID, limit, offset int) ([]models.Donation, error)
+	CountPendingReviewByCreatorID(creatorID uuid.UUID) (int64, error)
+	ModerateAlert(id, creatorID uuid.UUID, status models.AlertStatus, moderatorID uuid.UUID, note string) (bool, error)
+	MarkAlertDelivered(id uuid.UUID, clients int) error
+	AckAlert(id, creatorID uuid.UUID) (bool, error)
+	GetStats(creatorID uuid.UUID) (*repository.DonationStats, error)
+	GetStatsByPeriod(creatorID uuid.UUID, from, to time.Time) (*repository.DonationStats, error)
+	GetDailyStats(creatorID uuid.UUID, days int) ([]repository.DailyStats, error)
+}
+
 type DonationService struct {
-	donationRepo *repository.DonationRepository
-	userRepo     *repository.UserRepository
-	paylabs      *PaylabsService
-	alertService *AlertService
+	donationRepo      donationStore
+	userRepo          userStore
+	pollRepo          pollStore
+	paylabs           *PaylabsService
+	alertService      *AlertService
+	paidListeners     []PaidDonationListener
+	approvedListeners []ApprovedAlertListener
 }
 
 func NewDonationService(
 	donationRepo *repository.DonationRepository,
 	userRepo *repository.UserRepository,
+	pollRepo *repository.PollRepository,
 	paylabs *PaylabsService,
 	alertService *AlertService,
 ) *DonationService {
 	return &DonationService{
 		donationRepo: donationRepo,
 		userRepo:     userRepo,
+		pollRepo:     pollRepo,
 		paylabs:      paylabs,
 		alertService: alertService,
 	}
@@ -41,6 +81,7 @@ type CreateDonationInput struct {
 	Message         string     `json:"message"`
 	PaymentMethod   string     `json:"payment_method"` // qris, gopay, dana, shopee, ovo, linkaja
 	RedirectUrl     string     `json:"redirect_url"`   // For e-wallet redirect after payment
+	PollOptionID    *uuid.UUID `json:"poll_option_id"` // Vote in the creator's open poll
 }
 
 type CreateDonationResponse struct {
@@ -63,6 +104,16 @@ var ewalletPaymentTypes = map[string]string{
 	"linkaja": "LINKAJABALANCE",
 }
 
+// AddPaidListener registers a listener for paid donations
+func (s *DonationService) AddPaidListener(listener PaidDonationListener) {
+	s.paidListeners = append(s.paidListeners, listener)
+}
+
+// AddApprovedListener registers a listener for approved alerts
+func (s *DonationService) AddApprovedListener(listener ApprovedAlertListener) {
+	s.approvedListeners = append(s.approvedListeners, listener)
+}
+
 func (s *DonationService) CreateDonation(log *utils.RequestLogger, input *CreateDonationInput, buyerID *uuid.UUID) (*CreateDonationResponse, error) {
 	// Find creator
 	creator, err := s.userRepo.FindByUsername(input.CreatorUsername)
@@ -91,6 +142,16 @@ func (s *DonationService) CreateDonation(log *utils.RequestLogger, input *Create
 		}
 	}
 
+	// Validate poll vote against the creator's open poll
+	var pollOption string
+	if input.PollOptionID != nil {
+		option, err := s.pollRepo.FindOpenOption(*input.PollOptionID, creator.ID)
+		if err != nil {
+			return nil, errors.New("poll option not found or poll is closed")
+		}
+		pollOption = option.Label
+	}
+
 	// Create donation
 	donation := &models.Donation{
 		CreatorID:     creator.ID,
@@ -105,6 +166,8 @@ func (s *DonationService) CreateDonation(log *utils.RequestLogger, input *Create
 		PaymentMethod: input.PaymentMethod,
 		ProductName:   productName,  // Denormalized
 		ProductEmoji:  productEmoji, // Denormalized
+		PollOptionID:  input.PollOptionID,
+		PollOption:    pollOption, // Denormalized
 	}
 
 	if err := s.donationRepo.Create(donation); err != nil {
@@ -123,6 +186,7 @@ func (s *DonationService) CreateDonation(log *utils.RequestLogger, input *Create
 
 		// Update donation with payment ID
 		donation.PaymentID = paymentResp.OrderID
+		donation.QRISPayload = paymentResp.QRCode
 		if err := s.donationRepo.Update(donation); err != nil {
 			log.LogError("DonationService", err, "Failed to update donation")
 			return nil, errors.New("failed to update donation")
@@ -193,12 +257,14 @@ func (s *DonationService) GetCreatorDonationsWithCount(creatorID uuid.UUID, page
 	return donations, total, nil
 }
 
+// GetRecentDonations lists a creator's latest donations publicly, so held
+// and rejected alerts are left out
 func (s *DonationService) GetRecentDonations(creatorUsername string, limit int) ([]models.Donation, error) {
 	creator, err := s.userRepo.FindByUsername(creatorUsername)
 	if err != nil {
 		return nil, errors.New("creator not found")
 	}
-	return s.donationRepo.FindRecentByCreatorID(creator.ID, limit)
+	return s.donationRepo.FindRecentVisibleByCreatorID(creator.ID, limit)
 }
 
 func (s *DonationService) GetStats(creatorID uuid.UUID) (*repository.DonationStats, error) {
@@ -238,29 +304,170 @@ func (s *DonationService) UpdatePaymentStatus(log *utils.RequestLogger, paymentI
 		return nil
 	}
 
-	if err := s.donationRepo.UpdatePaymentStatus(donation.ID, status); err != nil {
+	// The webhook and the status poll can both get here for one payment;
+	// only the request that changes the row runs the listeners and alert
+	updated, err := s.donationRepo.UpdatePaymentStatus(donation.ID, status)
+	if err != nil {
 		log.LogError("DonationService", err, "Failed to update payment status")
 		return err
 	}
+	if !updated {
+		log.Info().Str("payment_id", paymentID).Msg("Payment status changed by another request, skipping")
+		return nil
+	}
 
-	// Broadcast alert if payment is successful
-	if status == models.PaymentStatusPaid && s.alertService != nil {
-		creator, err := s.userRepo.FindByID(donation.CreatorID)
-		if err == nil {
-			alert := &AlertData{
-				SupporterName: donation.BuyerName,
-				Amount:        donation.Amount,
-				Message:       donation.Message,
-				CreatorName:   creator.Name,
-				Quantity:      donation.Quantity,
-				ProductName:   donation.ProductName,  // Use denormalized
-				ProductEmoji:  donation.ProductEmoji, // Use denormalized
-			}
-
-			// Broadcast using user ID (overlay now registers by user ID)
-			s.alertService.Broadcast(log, creator.ID.String(), alert)
+	if status != models.PaymentStatusPaid {
+		return nil
+	}
+	now := time.Now()
+	donation.PaymentStatus = status
+	donation.PaidAt = &now
+
+	// Hold the alert for review if the creator moderates messages. This is
+	// saved before the listeners run, so what they read back from the
+	// database (e.g. leaderboard names) leaves the donation out.
+	creator, creatorErr := s.userRepo.FindByID(donation.CreatorID)
+	held := creatorErr == nil && creator.AlertApprovalEnabled && s.alertService != nil
+	if held {
+		if err := s.donationRepo.UpdateAlertStatus(donation.ID, models.AlertStatusPendingReview); err != nil {
+			log.LogError("DonationService", err, "Failed to queue alert for review")
 		}
+		donation.AlertStatus = models.AlertStatusPendingReview
+	}
+
+	for _, listener := range s.paidListeners {
+		listener.OnDonationPaid(log, donation)
+	}
+
+	if s.alertService == nil || creatorErr != nil {
+		return nil
+	}
+	if held {
+		s.publishQueueState(log, creator.ID)
+		return nil
+	}
+
+	// Broadcast using user ID (overlay now registers by user ID)
+	clients := s.alertService.Publish(log, creator.ID.String(), NewOverlayEvent(EventDonation, buildAlert(donation, creator)))
+	if err := s.donationRepo.UpdateAlertStatus(donation.ID, models.AlertStatusSent); err != nil {
+		log.LogError("DonationService", err, "Failed to update alert status")
+	}
+	s.recordDelivery(log, donation.ID, clients)
+	return nil
+}
+
+// buildAlert converts a donation into the overlay alert payload
+func buildAlert(donation *models.Donation, creator *models.User) *AlertData {
+	return &AlertData{
+		DonationID:    &donation.ID,
+		SupporterName: donation.BuyerName,
+		Amount:        donation.Amount,
+		Message:       donation.Message,
+		CreatorName:   creator.Name,
+		Quantity:      donation.Quantity,
+		ProductName:   donation.ProductName,  // Use denormalized
+		ProductEmoji:  donation.ProductEmoji, // Use denormalized
+		PollOption:    donation.PollOption,
+	}
+}
+
+// GetPendingAlerts returns paid donations waiting in the creator's review queue
+func (s *DonationService) GetPendingAlerts(creatorID uuid.UUID, page, limit int) ([]models.Donation, int64, error) {
+	offset := (page - 1) * limit
+	donations, err := s.donationRepo.FindPendingReviewByCreatorID(creatorID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.donationRepo.CountPendingReviewByCreatorID(creatorID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return donations, total, nil
+}
+
+// ApproveAlert approves a queued donation and broadcasts it to the overlay
+func (s *DonationService) ApproveAlert(log *utils.RequestLogger, creatorID, donationID, moderatorID uuid.UUID) (*models.Donation, error) {
+	ok, err := s.donationRepo.ModerateAlert(donationID, creatorID, models.AlertStatusApproved, moderatorID, "")
+	if err != nil {
+		log.LogError("DonationService", err, "Failed to approve alert")
+		return nil, errors.New("failed to approve alert")
+	}
+	if !ok {
+		return nil, errors.New("donation not found or already reviewed")
+	}
+
+	donation, err := s.donationRepo.FindByID(donationID)
+	if err != nil {
+		return nil, errors.New("donation not found")
+	}
+
+	if s.alertService != nil {
+		clients := s.alertService.Publish(log, creatorID.String(), NewOverlayEvent(EventDonation, buildAlert(donation, &donation.Creator)))
+		s.recordDelivery(log, donation.ID, clients)
+		s.publishQueueState(log, creatorID)
+	}
+	for _, listener := range s.approvedListeners {
+		listener.OnAlertApproved(log, donation)
+	}
+
+	return donation, nil
+}
+
+// RejectAlert rejects a queued donation. The alert is never shown but the
+// donation stays paid, so the amount still counts toward the creator's balance.
+func (s *DonationService) RejectAlert(log *utils.RequestLogger, creatorID, donationID, moderatorID uuid.UUID, reason string) (*models.Donation, error) {
+	ok, err := s.donationRepo.ModerateAlert(donationID, creatorID, models.AlertStatusRejected, moderatorID, reason)
+	if err != nil {
+		log.LogError("DonationService", err, "Failed to reject alert")
+		return nil, errors.New("failed to reject alert")
 	}
+	if !ok {
+		return nil, errors.New("donation not found or already reviewed")
+	}
+
+	if s.alertService != nil {
+		s.publishQueueState(log, creatorID)
+	}
+
+	return s.donationRepo.FindByID(donationID)
+}
+
+// publishQueueState tells overlays how many alerts are waiting for review
+func (s *DonationService) publishQueueState(log *utils.RequestLogger, creatorID uuid.UUID) {
+	count, err := s.donationRepo.CountPendingReviewByCreatorID(creatorID)
+	if err != nil {
+		log.LogError("DonationService", err, "Failed to count pending alerts")
+		return
+	}
+	s.alertService.Publish(log, creatorID.String(), NewOverlayEvent(EventQueueState, QueueState{PendingReview: count}))
+}
 
+// recordDelivery stores how many overlays an alert was pushed to
+func (s *DonationService) recordDelivery(log *utils.RequestLogger, donationID uuid.UUID, clients int) {
+	if err := s.donationRepo.MarkAlertDelivered(donationID, clients); err != nil {
+		log.LogError("DonationService", err, "Failed to record alert delivery")
+	}
+}
+
+// AckAlert records that an overlay displayed the donation's alert.
+// Repeated acks (several overlays, retries) are ignored.
+func (s *DonationService) AckAlert(creatorID, donationID uuid.UUID) error {
+	if _, err := s.donationRepo.AckAlert(donationID, creatorID); err != nil {
+		return errors.New("failed to acknowledge alert")
+	}
 	return nil
 }
+
+// GetRecentDeliveries returns the alert delivery state of the creator's latest paid donations
+func (s *DonationService) GetRecentDeliveries(creatorID uuid.UUID, limit int) ([]models.AlertDelivery, error) {
+	donations, err := s.donationRepo.FindRecentByCreatorID(creatorID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]models.AlertDelivery, len(donations))
+	for i := range donations {
+		deliveries[i] = donations[i].ToDelivery()
+	}
+	return deliveries, nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jajanin/backend/internal/models"
 	"github.com/jajanin/backend/internal/repository"
+	"github.com/jajanin/backend/internal/utils"
 	"gorm.io/gorm"
 )
 
@@ -19,6 +20,8 @@ type MockDonationRepository struct {
 	updateErr   error
 }
 
+var _ donationStore = (*MockDonationRepository)(nil)
+
 func NewMockDonationRepository() *MockDonationRepository {
 	return &MockDonationRepository{
 		donations:   make(map[uuid.UUID]*models.Donation),
@@ -72,6 +75,17 @@ func (m *MockDonationRepository) FindRecentByCreatorID(creatorID uuid.UUID, limi
 	return m.FindByCreatorID(creatorID, limit, 0)
 }
 
+func (m *MockDonationRepository) FindRecentVisibleByCreatorID(creatorID uuid.UUID, limit int) ([]models.Donation, error) {
+	var result []models.Donation
+	for _, d := range m.donations {
+		if d.CreatorID == creatorID && d.PaymentStatus == models.PaymentStatusPaid &&
+			d.AlertStatus != models.AlertStatusPendingReview && d.AlertStatus != models.AlertStatusRejected {
+			result = append(result, *d)
+		}
+	}
+	return result[:min(limit, len(result))], nil
+}
+
 func (m *MockDonationRepository) Update(donation *models.Donation) error {
 	if m.updateErr != nil {
 		return m.updateErr
@@ -83,16 +97,90 @@ func (m *MockDonationRepository) Update(donation *models.Donation) error {
 	return nil
 }
 
-func (m *MockDonationRepository) UpdatePaymentStatus(id uuid.UUID, status models.PaymentStatus) error {
+// UpdatePaymentStatus mirrors the repository's conditional update: paid
+// donations and donations already in status are left alone
+func (m *MockDonationRepository) UpdatePaymentStatus(id uuid.UUID, status models.PaymentStatus) (bool, error) {
+	donation, ok := m.donations[id]
+	if !ok {
+		return false, gorm.ErrRecordNotFound
+	}
+	if donation.PaymentStatus == models.PaymentStatusPaid || donation.PaymentStatus == status {
+		return false, nil
+	}
+	donation.PaymentStatus = status
+	if status == models.PaymentStatusPaid {
+		now := time.Now()
+		donation.PaidAt = &now
+	}
+	return true, nil
+}
+
+func (m *MockDonationRepository) FindProductByID(id uuid.UUID) (*models.QuickItem, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MockDonationRepository) CountByCreatorID(creatorID uuid.UUID) (int64, error) {
+	donations, err := m.FindByCreatorID(creatorID, len(m.donations), 0)
+	return int64(len(donations)), err
+}
+
+func (m *MockDonationRepository) UpdateAlertStatus(id uuid.UUID, status models.AlertStatus) error {
 	if donation, ok := m.donations[id]; ok {
-		donation.PaymentStatus = status
-		if status == models.PaymentStatusPaid {
-			now := time.Now()
-			donation.PaidAt = &now
+		donation.AlertStatus = status
+	}
+	return nil
+}
+
+func (m *MockDonationRepository) FindPendingReviewByCreatorID(creatorID uuid.UUID, limit, offset int) ([]models.Donation, error) {
+	var result []models.Donation
+	for _, d := range m.donations {
+		if d.CreatorID == creatorID && d.PaymentStatus == models.PaymentStatusPaid && d.AlertStatus == models.AlertStatusPendingReview {
+			result = append(result, *d)
 		}
-		return nil
 	}
-	return gorm.ErrRecordNotFound
+	if offset >= len(result) {
+		return []models.Donation{}, nil
+	}
+	return result[offset:min(offset+limit, len(result))], nil
+}
+
+func (m *MockDonationRepository) CountPendingReviewByCreatorID(creatorID uuid.UUID) (int64, error) {
+	pending, err := m.FindPendingReviewByCreatorID(creatorID, len(m.donations), 0)
+	return int64(len(pending)), err
+}
+
+// ModerateAlert mirrors the repository's conditional update: only the
+// creator's donations still pending review change
+func (m *MockDonationRepository) ModerateAlert(id, creatorID uuid.UUID, status models.AlertStatus, moderatorID uuid.UUID, note string) (bool, error) {
+	donation, ok := m.donations[id]
+	if !ok || donation.CreatorID != creatorID || donation.AlertStatus != models.AlertStatusPendingReview {
+		return false, nil
+	}
+	now := time.Now()
+	donation.AlertStatus = status
+	donation.ModeratedBy = &moderatorID
+	donation.ModeratedAt = &now
+	donation.ModerationNote = note
+	return true, nil
+}
+
+func (m *MockDonationRepository) MarkAlertDelivered(id uuid.UUID, clients int) error {
+	if donation, ok := m.donations[id]; ok {
+		now := time.Now()
+		donation.AlertDeliveredAt = &now
+		donation.AlertDeliveredTo = clients
+	}
+	return nil
+}
+
+func (m *MockDonationRepository) AckAlert(id, creatorID uuid.UUID) (bool, error) {
+	donation, ok := m.donations[id]
+	if !ok || donation.CreatorID != creatorID || donation.AlertAckedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	donation.AlertAckedAt = &now
+	return true, nil
 }
 
 func (m *MockDonationRepository) GetStats(creatorID uuid.UUID) (*repository.DonationStats, error) {
@@ -273,17 +361,17 @@ func TestUpdatePaymentStatus_Success(t *testing.T) {
 	mockRepo.AddDonation(donation)
 
 	// Update payment status
-	err := mockRepo.UpdatePaymentStatus(donation.ID, models.PaymentStatusPaid)
-	if err != nil {
-		t.Fatalf("Expected no error, got: %v", err)
+	updated, err := mockRepo.UpdatePaymentStatus(donation.ID, models.PaymentStatusPaid)
+	if err != nil || !updated {
+		t.Fatalf("Expected the status to change, got %v, %v", updated, err)
 	}
 
 	// Verify status updated
-	updated, _ := mockRepo.FindByID(donation.ID)
-	if updated.PaymentStatus != models.PaymentStatusPaid {
-		t.Errorf("Expected status Paid, got %s", updated.PaymentStatus)
+	found, _ := mockRepo.FindByID(donation.ID)
+	if found.PaymentStatus != models.PaymentStatusPaid {
+		t.Errorf("Expected status Paid, got %s", found.PaymentStatus)
 	}
-	if updated.PaidAt == nil {
+	if found.PaidAt == nil {
 		t.Error("Expected PaidAt to be set")
 	}
 }
@@ -292,7 +380,7 @@ func TestUpdatePaymentStatus_NotFound(t *testing.T) {
 	mockRepo := NewMockDonationRepository()
 
 	// Try to update non-existing donation
-	err := mockRepo.UpdatePaymentStatus(uuid.New(), models.PaymentStatusPaid)
+	_, err := mockRepo.UpdatePaymentStatus(uuid.New(), models.PaymentStatusPaid)
 	if !errors.Is(err, gorm.ErrRecordNotFound) {
 		t.Errorf("Expected ErrRecordNotFound, got %v", err)
 	}
@@ -358,3 +446,253 @@ func TestGetStats_Empty(t *testing.T) {
 		t.Errorf("Expected 0 donations, got %d", stats.TotalDonations)
 	}
 }
+
+// === Alert moderation ===
+
+type recordingApprovedListener struct {
+	approved []uuid.UUID
+}
+
+func (l *recordingApprovedListener) OnAlertApproved(log *utils.RequestLogger, donation *models.Donation) {
+	l.approved = append(l.approved, donation.ID)
+}
+
+type recordingPaidListener struct {
+	statuses []models.AlertStatus
+}
+
+func (l *recordingPaidListener) OnDonationPaid(log *utils.RequestLogger, donation *models.Donation) {
+	l.statuses = append(l.statuses, donation.AlertStatus)
+}
+
+// nextEvent returns the overlay's next queued event, or nil if there is none
+func nextEvent(sub *Subscriber) *OverlayEvent {
+	select {
+	case event := <-sub.Events:
+		return event
+	default:
+		return nil
+	}
+}
+
+func expectQueueState(t *testing.T, sub *Subscriber, pending int64) {
+	t.Helper()
+	event := nextEvent(sub)
+	if event == nil || event.Type != EventQueueState {
+		t.Fatalf("Expected a queue_state event, got %+v", event)
+	}
+	if state := event.Data.(QueueState); state.PendingReview != pending {
+		t.Errorf("Expected %d alerts pending review, got %d", pending, state.PendingReview)
+	}
+}
+
+func TestUpdatePaymentStatus_HoldsAlertForReview(t *testing.T) {
+	mockRepo := NewMockDonationRepository()
+	mockUserRepo := NewMockUserRepository()
+	alerts := NewAlertService(NewSubscribers(StreamLimits{}))
+	s := &DonationService{donationRepo: mockRepo, userRepo: mockUserRepo, alertService: alerts}
+	log := utils.NewRequestLogger("test")
+
+	listener := &recordingPaidListener{}
+	s.AddPaidListener(listener)
+
+	creator := &models.User{Email: "creator@example.com", Name: "Budi", AlertApprovalEnabled: true}
+	mockUserRepo.AddUser(creator)
+	overlay := alerts.Subscribe(creator.ID)
+	defer alerts.Unsubscribe(overlay)
+
+	held := &models.Donation{
+		CreatorID:     creator.ID,
+		PaymentID:     "pay-held",
+		BuyerName:     "Sari",
+		Amount:        25000,
+		PaymentStatus: models.PaymentStatusPending,
+	}
+	mockRepo.AddDonation(held)
+	if err := s.UpdatePaymentStatus(log, held.PaymentID, models.PaymentStatusPaid); err != nil {
+		t.Fatal(err)
+	}
+	if held.AlertStatus != models.AlertStatusPendingReview {
+		t.Errorf("Expected the alert to be held, got %q", held.AlertStatus)
+	}
+	if held.AlertDeliveredAt != nil {
+		t.Error("Expected a held alert not to be delivered")
+	}
+	// Paid listeners already see the alert as held
+	if len(listener.statuses) != 1 || listener.statuses[0] != models.AlertStatusPendingReview {
+		t.Errorf("Expected listeners to see a held alert, got %v", listener.statuses)
+	}
+	// Overlays only hear that the queue grew, not the donation itself
+	expectQueueState(t, overlay, 1)
+	if event := nextEvent(overlay); event != nil {
+		t.Errorf("Expected no further events, got %+v", event)
+	}
+
+	// Once approval is turned off alerts go straight to the overlay
+	creator.AlertApprovalEnabled = false
+	sent := &models.Donation{
+		CreatorID:     creator.ID,
+		PaymentID:     "pay-sent",
+		BuyerName:     "Sari",
+		Amount:        25000,
+		PaymentStatus: models.PaymentStatusPending,
+	}
+	mockRepo.AddDonation(sent)
+	if err := s.UpdatePaymentStatus(log, sent.PaymentID, models.PaymentStatusPaid); err != nil {
+		t.Fatal(err)
+	}
+	if sent.AlertStatus != models.AlertStatusSent || sent.AlertDeliveredTo != 1 {
+		t.Errorf("Expected the alert to be sent to 1 overlay, got %q to %d", sent.AlertStatus, sent.AlertDeliveredTo)
+	}
+	if event := nextEvent(overlay); event == nil || event.Type != EventDonation {
+		t.Errorf("Expected a donation event, got %+v", event)
+	}
+}
+
+func TestApproveAlert(t *testing.T) {
+	mockRepo := NewMockDonationRepository()
+	mockUserRepo := NewMockUserRepository()
+	alerts := NewAlertService(NewSubscribers(StreamLimits{}))
+	s := &DonationService{donationRepo: mockRepo, userRepo: mockUserRepo, alertService: alerts}
+	log := utils.NewRequestLogger("test")
+	listener := &recordingApprovedListener{}
+	s.AddApprovedListener(listener)
+
+	creator := &models.User{Email: "creator@example.com", Name: "Budi", AlertApprovalEnabled: true}
+	mockUserRepo.AddUser(creator)
+	overlay := alerts.Subscribe(creator.ID)
+	defer alerts.Unsubscribe(overlay)
+
+	first := &models.Donation{CreatorID: creator.ID, PaymentID: "pay-1", BuyerName: "Sari", Amount: 25000, PaymentStatus: models.PaymentStatusPending}
+	second := &models.Donation{CreatorID: creator.ID, PaymentID: "pay-2", BuyerName: "Joko", Amount: 10000, PaymentStatus: models.PaymentStatusPending}
+	for _, donation := range []*models.Donation{first, second} {
+		mockRepo.AddDonation(donation)
+		if err := s.UpdatePaymentStatus(log, donation.PaymentID, models.PaymentStatusPaid); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectQueueState(t, overlay, 1)
+	expectQueueState(t, overlay, 2)
+
+	// Another creator can't approve it
+	if _, err := s.ApproveAlert(log, uuid.New(), first.ID, uuid.New()); err == nil {
+		t.Error("Expected another creator's approval to fail")
+	}
+	if first.AlertStatus != models.AlertStatusPendingReview {
+		t.Errorf("Expected the alert to stay held, got %q", first.AlertStatus)
+	}
+
+	moderatorID := uuid.New()
+	if _, err := s.ApproveAlert(log, creator.ID, first.ID, moderatorID); err != nil {
+		t.Fatal(err)
+	}
+	if first.AlertStatus != models.AlertStatusApproved || first.ModeratedBy == nil || *first.ModeratedBy != moderatorID {
+		t.Errorf("Expected the alert approved by the moderator, got %q by %v", first.AlertStatus, first.ModeratedBy)
+	}
+	if first.AlertDeliveredTo != 1 {
+		t.Errorf("Expected delivery to 1 overlay, got %d", first.AlertDeliveredTo)
+	}
+	if event := nextEvent(overlay); event == nil || event.Type != EventDonation {
+		t.Fatalf("Expected a donation event, got %+v", event)
+	}
+	expectQueueState(t, overlay, 1)
+
+	// An alert is only reviewed once
+	if _, err := s.ApproveAlert(log, creator.ID, first.ID, moderatorID); err == nil {
+		t.Error("Expected a second approval to fail")
+	}
+	if _, err := s.RejectAlert(log, creator.ID, first.ID, moderatorID, "spam"); err == nil {
+		t.Error("Expected rejecting an approved alert to fail")
+	}
+	if event := nextEvent(overlay); event != nil {
+		t.Errorf("Expected no events for a failed review, got %+v", event)
+	}
+	if len(listener.approved) != 1 || listener.approved[0] != first.ID {
+		t.Errorf("Expected listeners to hear about one approval, got %v", listener.approved)
+	}
+	if second.AlertStatus != models.AlertStatusPendingReview {
+		t.Errorf("Expected the other alert to stay held, got %q", second.AlertStatus)
+	}
+}
+
+func TestRejectAlert(t *testing.T) {
+	mockRepo := NewMockDonationRepository()
+	mockUserRepo := NewMockUserRepository()
+	alerts := NewAlertService(NewSubscribers(StreamLimits{}))
+	s := &DonationService{donationRepo: mockRepo, userRepo: mockUserRepo, alertService: alerts}
+	log := utils.NewRequestLogger("test")
+
+	creator := &models.User{Email: "creator@example.com", Name: "Budi", AlertApprovalEnabled: true}
+	mockUserRepo.AddUser(creator)
+	overlay := alerts.Subscribe(creator.ID)
+	defer alerts.Unsubscribe(overlay)
+
+	donation := &models.Donation{CreatorID: creator.ID, PaymentID: "pay-1", BuyerName: "Sari", Amount: 25000, PaymentStatus: models.PaymentStatusPending}
+	mockRepo.AddDonation(donation)
+	if err := s.UpdatePaymentStatus(log, donation.PaymentID, models.PaymentStatusPaid); err != nil {
+		t.Fatal(err)
+	}
+	expectQueueState(t, overlay, 1)
+
+	if _, err := s.RejectAlert(log, uuid.New(), donation.ID, uuid.New(), "spam"); err == nil {
+		t.Error("Expected another creator's rejection to fail")
+	}
+
+	if _, err := s.RejectAlert(log, creator.ID, donation.ID, creator.ID, "spam"); err != nil {
+		t.Fatal(err)
+	}
+	if donation.AlertStatus != models.AlertStatusRejected || donation.ModerationNote != "spam" {
+		t.Errorf("Expected the alert rejected as spam, got %q (%q)", donation.AlertStatus, donation.ModerationNote)
+	}
+	if donation.PaymentStatus != models.PaymentStatusPaid {
+		t.Errorf("Expected the donation to stay paid, got %q", donation.PaymentStatus)
+	}
+	// Rejected alerts are never shown
+	expectQueueState(t, overlay, 0)
+	if event := nextEvent(overlay); event != nil {
+		t.Errorf("Expected no donation event, got %+v", event)
+	}
+
+	if _, err := s.ApproveAlert(log, creator.ID, donation.ID, creator.ID); err == nil {
+		t.Error("Expected approving a rejected alert to fail")
+	}
+}
+
+func TestGetRecentDonations_LeavesOutModeratedAlerts(t *testing.T) {
+	mockRepo := NewMockDonationRepository()
+	mockUserRepo := NewMockUserRepository()
+	s := &DonationService{donationRepo: mockRepo, userRepo: mockUserRepo}
+
+	username := "budi"
+	creator := &models.User{Email: "creator@example.com", Username: &username}
+	mockUserRepo.AddUser(creator)
+
+	for status, message := range map[models.AlertStatus]string{
+		models.AlertStatusSent:          "semangat",
+		models.AlertStatusApproved:      "mantap",
+		models.AlertStatusPendingReview: "belum dicek",
+		models.AlertStatusRejected:      "spam",
+	} {
+		mockRepo.AddDonation(&models.Donation{
+			CreatorID:     creator.ID,
+			BuyerName:     "Sari",
+			Amount:        10000,
+			Message:       message,
+			PaymentStatus: models.PaymentStatusPaid,
+			AlertStatus:   status,
+		})
+	}
+
+	donations, err := s.GetRecentDonations(username, 10)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(donations) != 2 {
+		t.Fatalf("Expected 2 donations, got %d", len(donations))
+	}
+	for _, d := range donations {
+		if d.AlertStatus == models.AlertStatusPendingReview || d.AlertStatus == models.AlertStatusRejected {
+			t.Errorf("Expected %q donations to be left out, got %q", d.AlertStatus, d.Message)
+		}
+	}
+}
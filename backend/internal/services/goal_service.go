@@ -0,0 +1,240 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/repository"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+// goalStore is the GoalRepository as GoalService uses it, an interface so
+// tests can run without a database
+type goalStore interface {
+	Create(goal *models.Goal) error
+	FindByID(id uuid.UUID) (*models.Goal, error)
+	FindByUserID(userID uuid.UUID) ([]models.Goal, error)
+	FindActiveByUserID(userID uuid.UUID) (*models.Goal, error)
+	Update(goal *models.Goal) error
+	Delete(id uuid.UUID) error
+	Activate(goal *models.Goal) error
+	SumPaidDonations(userID uuid.UUID, from time.Time, to *time.Time) (int64, error)
+}
+
+type GoalService struct {
+	goalRepo     goalStore
+	userRepo     userStore
+	hub          *StreamHub
+	alertService *AlertService // goal_reached / milestone are also shown by alert overlays
+}
+
+func NewGoalService(goalRepo *repository.GoalRepository, userRepo *repository.UserRepository, hub *StreamHub, alertService *AlertService) *GoalService {
+	return &GoalService{
+		goalRepo:     goalRepo,
+		userRepo:     userRepo,
+		hub:          hub,
+		alertService: alertService,
+	}
+}
+
+type CreateGoalInput struct {
+	Title        string     `json:"title" binding:"required,min=1,max=100"`
+	TargetAmount int64      `json:"target_amount" binding:"required,min=1000"`
+	StartsAt     *time.Time `json:"starts_at"` // defaults to now
+	EndsAt       *time.Time `json:"ends_at"`
+	IsActive     bool       `json:"is_active"`
+}
+
+type UpdateGoalInput struct {
+	Title        *string    `json:"title" binding:"omitempty,min=1,max=100"`
+	TargetAmount *int64     `json:"target_amount" binding:"omitempty,min=1000"`
+	StartsAt     *time.Time `json:"starts_at"`
+	EndsAt       *time.Time `json:"ends_at"`
+	ClearEndsAt  bool       `json:"clear_ends_at"` // remove the end time, so the goal runs until deactivated
+	IsActive     *bool      `json:"is_active"`
+}
+
+func (s *GoalService) Create(userID uuid.UUID, input *CreateGoalInput) (*models.Goal, error) {
+	goal := &models.Goal{
+		UserID:       userID,
+		Title:        input.Title,
+		TargetAmount: input.TargetAmount,
+		StartsAt:     time.Now(),
+		EndsAt:       input.EndsAt,
+	}
+	if input.StartsAt != nil {
+		goal.StartsAt = *input.StartsAt
+	}
+	if goal.EndsAt != nil && !goal.EndsAt.After(goal.StartsAt) {
+		return nil, errors.New("end time must be after start time")
+	}
+
+	if err := s.goalRepo.Create(goal); err != nil {
+		return nil, errors.New("failed to create goal")
+	}
+
+	if input.IsActive {
+		if err := s.goalRepo.Activate(goal); err != nil {
+			return nil, errors.New("failed to activate goal")
+		}
+	}
+
+	return s.withProgress(goal)
+}
+
+func (s *GoalService) GetAll(userID uuid.UUID) ([]models.Goal, error) {
+	goals, err := s.goalRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range goals {
+		total, err := s.goalRepo.SumPaidDonations(goals[i].UserID, goals[i].StartsAt, goals[i].EndsAt)
+		if err != nil {
+			return nil, err
+		}
+		goals[i].CurrentAmount = total
+	}
+	return goals, nil
+}
+
+// Update edits a goal and pushes its new progress to goal overlays if it is
+// the active goal
+func (s *GoalService) Update(log *utils.RequestLogger, userID, goalID uuid.UUID, input *UpdateGoalInput) (*models.Goal, error) {
+	if input.ClearEndsAt && input.EndsAt != nil {
+		return nil, errors.New("set either ends_at or clear_ends_at, not both")
+	}
+
+	goal, err := s.findOwned(userID, goalID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Title != nil {
+		goal.Title = *input.Title
+	}
+	if input.TargetAmount != nil {
+		goal.TargetAmount = *input.TargetAmount
+	}
+	if input.StartsAt != nil {
+		goal.StartsAt = *input.StartsAt
+	}
+	if input.EndsAt != nil {
+		goal.EndsAt = input.EndsAt
+	}
+	if input.ClearEndsAt {
+		goal.EndsAt = nil
+	}
+	if goal.EndsAt != nil && !goal.EndsAt.After(goal.StartsAt) {
+		return nil, errors.New("end time must be after start time")
+	}
+
+	if input.IsActive != nil && *input.IsActive {
+		err = s.goalRepo.Activate(goal)
+	} else {
+		if input.IsActive != nil {
+			goal.IsActive = false
+		}
+		err = s.goalRepo.Update(goal)
+	}
+	if err != nil {
+		return nil, errors.New("failed to update goal")
+	}
+
+	goal, err = s.withProgress(goal)
+	if err != nil {
+		return nil, err
+	}
+	if goal.IsActive {
+		s.hub.Broadcast(log, TopicGoal, goal.UserID, NewOverlayEvent(EventGoalProgress, goal.ToProgress()))
+	}
+	return goal, nil
+}
+
+func (s *GoalService) Delete(userID, goalID uuid.UUID) error {
+	if _, err := s.findOwned(userID, goalID); err != nil {
+		return err
+	}
+	return s.goalRepo.Delete(goalID)
+}
+
+// GetActive returns the creator's active goal with its current progress. A
+// goal whose end time has passed no longer counts as active.
+func (s *GoalService) GetActive(userID uuid.UUID) (*models.Goal, error) {
+	goal, err := s.goalRepo.FindActiveByUserID(userID)
+	if err != nil || (goal.EndsAt != nil && time.Now().After(*goal.EndsAt)) {
+		return nil, errors.New("no active goal")
+	}
+	return s.withProgress(goal)
+}
+
+// GetActiveByUsername returns the active goal shown on the public creator page
+func (s *GoalService) GetActiveByUsername(username string) (*models.Goal, error) {
+	user, err := s.userRepo.FindByUsername(username)
+	if err != nil {
+		return nil, errors.New("creator not found")
+	}
+	return s.GetActive(user.ID)
+}
+
+// OnDonationPaid pushes updated progress to goal overlays when a paid
+// donation falls inside the active goal window
+func (s *GoalService) OnDonationPaid(log *utils.RequestLogger, donation *models.Donation) {
+	goal, err := s.goalRepo.FindActiveByUserID(donation.CreatorID)
+	if err != nil {
+		return
+	}
+
+	paidAt := time.Now()
+	if donation.PaidAt != nil {
+		paidAt = *donation.PaidAt
+	}
+	if !goal.IsRunning(paidAt) {
+		return
+	}
+
+	total, err := s.goalRepo.SumPaidDonations(goal.UserID, goal.StartsAt, goal.EndsAt)
+	if err != nil {
+		log.LogError("GoalService", err, "Failed to calculate goal progress")
+		return
+	}
+
+	// Detect the donation that pushed the goal over its target / a milestone
+	before := total - donation.Amount
+	wasReached := before >= goal.TargetAmount
+	goal.CurrentAmount = total
+	progress := goal.ToProgress()
+
+	s.hub.Broadcast(log, TopicGoal, goal.UserID, NewOverlayEvent(EventGoalProgress, progress))
+
+	var highlight *OverlayEvent
+	if progress.Reached && !wasReached {
+		highlight = NewOverlayEvent(EventGoalReached, progress)
+	} else if milestone := goal.CrossedMilestone(before, total); milestone > 0 {
+		highlight = NewOverlayEvent(EventMilestone, models.GoalMilestone{GoalProgress: progress, Milestone: milestone})
+	}
+	if highlight != nil {
+		s.hub.Broadcast(log, TopicGoal, goal.UserID, highlight)
+		if s.alertService != nil {
+			s.alertService.Publish(log, goal.UserID.String(), highlight)
+		}
+	}
+}
+
+func (s *GoalService) findOwned(userID, goalID uuid.UUID) (*models.Goal, error) {
+	goal, err := s.goalRepo.FindByID(goalID)
+	if err != nil || goal.UserID != userID {
+		return nil, errors.New("goal not found")
+	}
+	return goal, nil
+}
+
+func (s *GoalService) withProgress(goal *models.Goal) (*models.Goal, error) {
+	total, err := s.goalRepo.SumPaidDonations(goal.UserID, goal.StartsAt, goal.EndsAt)
+	if err != nil {
+		return nil, errors.New("failed to calculate goal progress")
+	}
+	goal.CurrentAmount = total
+	return goal, nil
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// MockGoalRepository keeps goals and paid donations in memory
+type MockGoalRepository struct {
+	goals     map[uuid.UUID]*models.Goal
+	donations []models.Donation
+}
+
+var _ goalStore = (*MockGoalRepository)(nil)
+
+func NewMockGoalRepository() *MockGoalRepository {
+	return &MockGoalRepository{goals: make(map[uuid.UUID]*models.Goal)}
+}
+
+func (m *MockGoalRepository) Create(goal *models.Goal) error {
+	goal.ID = uuid.New()
+	copied := *goal
+	m.goals[goal.ID] = &copied
+	return nil
+}
+
+func (m *MockGoalRepository) FindByID(id uuid.UUID) (*models.Goal, error) {
+	if goal, ok := m.goals[id]; ok {
+		copied := *goal
+		return &copied, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MockGoalRepository) FindByUserID(userID uuid.UUID) ([]models.Goal, error) {
+	var goals []models.Goal
+	for _, goal := range m.goals {
+		if goal.UserID == userID {
+			goals = append(goals, *goal)
+		}
+	}
+	return goals, nil
+}
+
+func (m *MockGoalRepository) FindActiveByUserID(userID uuid.UUID) (*models.Goal, error) {
+	for _, goal := range m.goals {
+		if goal.UserID == userID && goal.IsActive {
+			copied := *goal
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MockGoalRepository) Update(goal *models.Goal) error {
+	copied := *goal
+	m.goals[goal.ID] = &copied
+	return nil
+}
+
+func (m *MockGoalRepository) Delete(id uuid.UUID) error {
+	delete(m.goals, id)
+	return nil
+}
+
+func (m *MockGoalRepository) Activate(goal *models.Goal) error {
+	for _, other := range m.goals {
+		if other.UserID == goal.UserID {
+			other.IsActive = false
+		}
+	}
+	goal.IsActive = true
+	return m.Update(goal)
+}
+
+func (m *MockGoalRepository) SumPaidDonations(userID uuid.UUID, from time.Time, to *time.Time) (int64, error) {
+	var total int64
+	for _, d := range m.donations {
+		if d.CreatorID != userID || d.PaidAt == nil || d.PaidAt.Before(from) || (to != nil && d.PaidAt.After(*to)) {
+			continue
+		}
+		total += d.Amount
+	}
+	return total, nil
+}
+
+func TestGoalService_Update(t *testing.T) {
+	mockRepo := NewMockGoalRepository()
+	hub := NewStreamHub(NewSubscribers(StreamLimits{}))
+	s := &GoalService{goalRepo: mockRepo, hub: hub}
+	log := utils.NewRequestLogger("test")
+
+	userID := uuid.New()
+	startsAt := time.Now().Add(-time.Hour)
+	endsAt := time.Now().Add(time.Hour)
+	goal := &models.Goal{UserID: userID, Title: "PC baru", TargetAmount: 100000, StartsAt: startsAt, EndsAt: &endsAt}
+	mockRepo.Create(goal)
+	paidAt := time.Now().Add(-time.Minute)
+	mockRepo.donations = append(mockRepo.donations, models.Donation{CreatorID: userID, Amount: 30000, PaidAt: &paidAt})
+
+	overlay := hub.Subscribe(TopicGoal, userID)
+	defer hub.Unsubscribe(overlay)
+
+	// An inactive goal is not on any overlay
+	target := int64(50000)
+	if _, err := s.Update(log, userID, goal.ID, &UpdateGoalInput{TargetAmount: &target}); err != nil {
+		t.Fatal(err)
+	}
+	if event := nextEvent(overlay); event != nil {
+		t.Errorf("Expected no event for an inactive goal, got %+v", event)
+	}
+
+	active := true
+	updated, err := s.Update(log, userID, goal.ID, &UpdateGoalInput{IsActive: &active, ClearEndsAt: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.EndsAt != nil || mockRepo.goals[goal.ID].EndsAt != nil {
+		t.Errorf("Expected the end time to be cleared, got %v", updated.EndsAt)
+	}
+	event := nextEvent(overlay)
+	if event == nil || event.Type != EventGoalProgress {
+		t.Fatalf("Expected a goal_progress event, got %+v", event)
+	}
+	if progress := event.Data.(models.GoalProgress); progress.TargetAmount != 50000 || progress.CurrentAmount != 30000 {
+		t.Errorf("Expected 30000 of 50000, got %d of %d", progress.CurrentAmount, progress.TargetAmount)
+	}
+
+	// Setting and clearing the end time at once is refused
+	if _, err := s.Update(log, userID, goal.ID, &UpdateGoalInput{EndsAt: &endsAt, ClearEndsAt: true}); err == nil {
+		t.Error("Expected ends_at with clear_ends_at to fail")
+	}
+	before := startsAt.Add(-time.Minute)
+	if _, err := s.Update(log, userID, goal.ID, &UpdateGoalInput{EndsAt: &before}); err == nil {
+		t.Error("Expected an end time before the start to fail")
+	}
+	if _, err := s.Update(log, uuid.New(), goal.ID, &UpdateGoalInput{TargetAmount: &target}); err == nil {
+		t.Error("Expected another creator's update to fail")
+	}
+	if event := nextEvent(overlay); event != nil {
+		t.Errorf("Expected no event for a failed update, got %+v", event)
+	}
+}
+
+func TestGoalService_GetActive(t *testing.T) {
+	mockRepo := NewMockGoalRepository()
+	s := &GoalService{goalRepo: mockRepo}
+	userID := uuid.New()
+
+	if _, err := s.GetActive(userID); err == nil {
+		t.Error("Expected no active goal")
+	}
+
+	endsAt := time.Now().Add(time.Hour)
+	goal := &models.Goal{UserID: userID, TargetAmount: 100000, StartsAt: time.Now().Add(-time.Hour), EndsAt: &endsAt, IsActive: true}
+	mockRepo.Create(goal)
+	if _, err := s.GetActive(userID); err != nil {
+		t.Errorf("Expected the running goal, got %v", err)
+	}
+
+	ended := time.Now().Add(-time.Minute)
+	mockRepo.goals[goal.ID].EndsAt = &ended
+	if _, err := s.GetActive(userID); err == nil {
+		t.Error("Expected a goal past its end time not to be active")
+	}
+}
+
+func TestGoalService_OnDonationPaid(t *testing.T) {
+	mockRepo := NewMockGoalRepository()
+	hub := NewStreamHub(NewSubscribers(StreamLimits{}))
+	s := &GoalService{goalRepo: mockRepo, hub: hub}
+	log := utils.NewRequestLogger("test")
+
+	userID := uuid.New()
+	goal := &models.Goal{UserID: userID, TargetAmount: 100000, StartsAt: time.Now().Add(-time.Hour), IsActive: true}
+	mockRepo.Create(goal)
+	overlay := hub.Subscribe(TopicGoal, userID)
+	defer hub.Unsubscribe(overlay)
+
+	tests := []struct {
+		amount    int64
+		highlight string
+	}{
+		{20000, ""},
+		{40000, EventMilestone}, // 60%
+		{10000, ""},
+		{30000, EventGoalReached},
+		{10000, ""}, // already reached
+	}
+	for _, tt := range tests {
+		paidAt := time.Now()
+		donation := models.Donation{CreatorID: userID, Amount: tt.amount, PaidAt: &paidAt}
+		mockRepo.donations = append(mockRepo.donations, donation)
+		s.OnDonationPaid(log, &donation)
+
+		if event := nextEvent(overlay); event == nil || event.Type != EventGoalProgress {
+			t.Fatalf("Amount %d: expected a goal_progress event, got %+v", tt.amount, event)
+		}
+		event := nextEvent(overlay)
+		if tt.highlight == "" && event != nil {
+			t.Errorf("Amount %d: expected no highlight, got %s", tt.amount, event.Type)
+		}
+		if tt.highlight != "" && (event == nil || event.Type != tt.highlight) {
+			t.Errorf("Amount %d: expected %s, got %+v", tt.amount, tt.highlight, event)
+		}
+	}
+
+	// Donations paid before the goal started are not counted
+	early := time.Now().Add(-2 * time.Hour)
+	s.OnDonationPaid(log, &models.Donation{CreatorID: userID, Amount: 50000, PaidAt: &early})
+	if event := nextEvent(overlay); event != nil {
+		t.Errorf("Expected no event outside the goal window, got %+v", event)
+	}
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	googleJWKSDefaultTTL = time.Hour
+	// An unknown kid or a failed fetch triggers a refetch, but not more often than this
+	googleJWKSMinRefresh = time.Minute
+	// While Google is unreachable, expired keys are still trusted this long
+	googleJWKSMaxStale = 6 * time.Hour
+)
+
+var (
+	ErrInvalidGoogleToken     = errors.New("invalid google token")
+	ErrGoogleEmailNotVerified = errors.New("google account email is not verified")
+	ErrGoogleNotConfigured    = errors.New("google sign-in is not configured")
+)
+
+var googleIssuers = []string{"accounts.google.com", "https://accounts.google.com"}
+
+// GoogleIdentity is the account an ID token was issued for
+type GoogleIdentity struct {
+	Subject string
+	Email   string
+	Name    string
+	Picture string
+}
+
+// googleBool accepts email_verified as a bool or as the string "true"/"false"
+type googleBool bool
+
+func (b *googleBool) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		v, err := strconv.ParseBool(s)
+		*b = googleBool(v)
+		return err
+	}
+	var v bool
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*b = googleBool(v)
+	return nil
+}
+
+type googleClaims struct {
+	Email         string     `json:"email"`
+	EmailVerified googleBool `json:"email_verified"`
+	Name          string     `json:"name"`
+	Picture       string     `json:"picture"`
+	jwt.RegisteredClaims
+}
+
+// GoogleTokenVerifier checks Google ID tokens against Google's published
+// signing keys, which are cached for as long as the JWKS response allows
+type GoogleTokenVerifier struct {
+	clientID   string
+	jwksURL    string
+	httpClient *http.Client
+
+	fetchMu sync.Mutex // one fetch at a time; held without mu during the request
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	expiresAt time.Time
+	fetchedAt time.Time // last fetch attempt, successful or not
+	fetchErr  error     // why the last fetch failed
+}
+
+func NewGoogleTokenVerifier(clientID, jwksURL string) *GoogleTokenVerifier {
+	return &GoogleTokenVerifier{
+		clientID:   clientID,
+		jwksURL:    jwksURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Verify checks the token's signature, audience, issuer and expiry and
+// returns the identity it was issued for. Only verified emails are accepted.
+func (v *GoogleTokenVerifier) Verify(idToken string) (*GoogleIdentity, error) {
+	if v.clientID == "" {
+		return nil, ErrGoogleNotConfigured
+	}
+
+	claims := &googleClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, v.keyFunc,
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithAudience(v.clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(30*time.Second),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidGoogleToken, err)
+	}
+
+	validIssuer := false
+	for _, iss := range googleIssuers {
+		if claims.Issuer == iss {
+			validIssuer = true
+			break
+		}
+	}
+	if !validIssuer {
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidGoogleToken, claims.Issuer)
+	}
+	if claims.Subject == "" || claims.Email == "" {
+		return nil, fmt.Errorf("%w: missing subject or email", ErrInvalidGoogleToken)
+	}
+	if !claims.EmailVerified {
+		return nil, ErrGoogleEmailNotVerified
+	}
+
+	return &GoogleIdentity{
+		Subject: claims.Subject,
+		Email:   strings.ToLower(claims.Email),
+		Name:    claims.Name,
+		Picture: claims.Picture,
+	}, nil
+}
+
+func (v *GoogleTokenVerifier) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("missing kid")
+	}
+	return v.key(kid)
+}
+
+// key returns the signing key for kid, refreshing the cache when it has
+// expired or doesn't know the key yet
+func (v *GoogleTokenVerifier) key(kid string) (*rsa.PublicKey, error) {
+	if key, settled, err := v.cachedKey(kid, time.Now()); settled {
+		return key, err
+	}
+
+	// Requests that arrive during a fetch wait for it and use its result
+	v.fetchMu.Lock()
+	defer v.fetchMu.Unlock()
+	now := time.Now()
+	if key, settled, err := v.cachedKey(kid, now); settled {
+		return key, err
+	}
+
+	keys, ttl, err := v.fetch()
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.fetchedAt = now
+	v.fetchErr = err
+	if err == nil {
+		v.keys = keys
+		v.expiresAt = now.Add(ttl)
+	}
+	if key, ok := v.usableKey(kid, now); ok {
+		return key, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("unknown kid %q", kid)
+}
+
+// cachedKey answers from the cache while it is fresh, or while the last
+// fetch attempt is too recent to try again. settled is false when a fetch
+// is due.
+func (v *GoogleTokenVerifier) cachedKey(kid string, now time.Time) (*rsa.PublicKey, bool, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if key, ok := v.keys[kid]; ok && now.Before(v.expiresAt) {
+		return key, true, nil
+	}
+	if now.Sub(v.fetchedAt) >= googleJWKSMinRefresh {
+		return nil, false, nil
+	}
+	if key, ok := v.usableKey(kid, now); ok {
+		return key, true, nil
+	}
+	if v.fetchErr != nil {
+		return nil, true, v.fetchErr
+	}
+	return nil, true, fmt.Errorf("unknown kid %q", kid)
+}
+
+// usableKey returns a cached key that is fresh or, if Google can't be
+// reached, not yet too stale to trust. Caller holds mu.
+func (v *GoogleTokenVerifier) usableKey(kid string, now time.Time) (*rsa.PublicKey, bool) {
+	key, ok := v.keys[kid]
+	if !ok || !now.Before(v.expiresAt.Add(googleJWKSMaxStale)) {
+		return nil, false
+	}
+	return key, true
+}
+
+type jwks struct {
+	Keys []struct {
+		Kid string `json:"kid"`
+		Kty string `json:"kty"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+// fetch downloads the key set and how long it may be cached
+func (v *GoogleTokenVerifier) fetch() (map[string]*rsa.PublicKey, time.Duration, error) {
+	resp, err := v.httpClient.Get(v.jwksURL)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch google keys: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("failed to fetch google keys: status %d", resp.StatusCode)
+	}
+
+	var set jwks
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode google keys: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || k.Kid == "" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if errN != nil || errE != nil || len(e) == 0 || len(e) > 4 {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	if len(keys) == 0 {
+		return nil, 0, errors.New("google key set is empty")
+	}
+	return keys, maxAge(resp.Header.Get("Cache-Control"), googleJWKSDefaultTTL), nil
+}
+
+// maxAge reads max-age from a Cache-Control header
+func maxAge(cacheControl string, fallback time.Duration) time.Duration {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(directive), "=")
+		if !found || !strings.EqualFold(name, "max-age") {
+			continue
+		}
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testGoogleClientID = "client-123.apps.googleusercontent.com"
+
+type testJWKS struct {
+	server *httptest.Server
+	keys   map[string]*rsa.PrivateKey
+	hits   atomic.Int32
+	down   atomic.Bool // answer 503 as if Google were unreachable
+}
+
+func newTestJWKS(t *testing.T, kids ...string) *testJWKS {
+	t.Helper()
+	j := &testJWKS{keys: make(map[string]*rsa.PrivateKey)}
+	for _, kid := range kids {
+		j.addKey(t, kid)
+	}
+	j.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		j.hits.Add(1)
+		if j.down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var set struct {
+			Keys []map[string]string `json:"keys"`
+		}
+		for kid, key := range j.keys {
+			set.Keys = append(set.Keys, map[string]string{
+				"kid": kid,
+				"kty": "RSA",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		_ = json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(j.server.Close)
+	return j
+}
+
+func (j *testJWKS) addKey(t *testing.T, kid string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.keys[kid] = key
+}
+
+func validGoogleClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            "https://accounts.google.com",
+		"aud":            testGoogleClientID,
+		"sub":            "1234567890",
+		"email":          "Budi@Example.com",
+		"email_verified": true,
+		"name":           "Budi",
+		"picture":        "https://lh3.googleusercontent.com/a/budi",
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+	}
+}
+
+func signGoogleToken(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestGoogleTokenVerifier_Valid(t *testing.T) {
+	jwks := newTestJWKS(t, "k1")
+	v := NewGoogleTokenVerifier(testGoogleClientID, jwks.server.URL)
+
+	identity, err := v.Verify(signGoogleToken(t, jwks.keys["k1"], "k1", validGoogleClaims()))
+	if err != nil {
+		t.Fatalf("Expected valid token, got %v", err)
+	}
+	if identity.Subject != "1234567890" || identity.Email != "budi@example.com" || identity.Name != "Budi" {
+		t.Errorf("Unexpected identity %+v", identity)
+	}
+
+	// Second verification is served from the cache
+	if _, err := v.Verify(signGoogleToken(t, jwks.keys["k1"], "k1", validGoogleClaims())); err != nil {
+		t.Fatal(err)
+	}
+	if hits := jwks.hits.Load(); hits != 1 {
+		t.Errorf("Expected 1 JWKS fetch, got %d", hits)
+	}
+}
+
+func TestGoogleTokenVerifier_Rejects(t *testing.T) {
+	jwks := newTestJWKS(t, "k1")
+	v := NewGoogleTokenVerifier(testGoogleClientID, jwks.server.URL)
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	tests := []struct {
+		name   string
+		modify func(jwt.MapClaims)
+		key    *rsa.PrivateKey
+		want   error
+	}{
+		{"wrong audience", func(c jwt.MapClaims) { c["aud"] = "someone-else" }, nil, ErrInvalidGoogleToken},
+		{"wrong issuer", func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }, nil, ErrInvalidGoogleToken},
+		{"expired", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }, nil, ErrInvalidGoogleToken},
+		{"missing expiry", func(c jwt.MapClaims) { delete(c, "exp") }, nil, ErrInvalidGoogleToken},
+		{"unverified email", func(c jwt.MapClaims) { c["email_verified"] = false }, nil, ErrGoogleEmailNotVerified},
+		{"unverified email string", func(c jwt.MapClaims) { c["email_verified"] = "false" }, nil, ErrGoogleEmailNotVerified},
+		{"wrong signing key", func(c jwt.MapClaims) {}, other, ErrInvalidGoogleToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validGoogleClaims()
+			tt.modify(claims)
+			key := tt.key
+			if key == nil {
+				key = jwks.keys["k1"]
+			}
+			_, err := v.Verify(signGoogleToken(t, key, "k1", claims))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestGoogleTokenVerifier_RejectsHMAC(t *testing.T) {
+	jwks := newTestJWKS(t, "k1")
+	v := NewGoogleTokenVerifier(testGoogleClientID, jwks.server.URL)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, validGoogleClaims())
+	token.Header["kid"] = "k1"
+	signed, _ := token.SignedString([]byte("secret"))
+
+	if _, err := v.Verify(signed); !errors.Is(err, ErrInvalidGoogleToken) {
+		t.Errorf("Expected ErrInvalidGoogleToken, got %v", err)
+	}
+}
+
+func TestGoogleTokenVerifier_KeyRotation(t *testing.T) {
+	jwks := newTestJWKS(t, "k1")
+	v := NewGoogleTokenVerifier(testGoogleClientID, jwks.server.URL)
+
+	if _, err := v.Verify(signGoogleToken(t, jwks.keys["k1"], "k1", validGoogleClaims())); err != nil {
+		t.Fatal(err)
+	}
+
+	// A token signed with a new key refetches the set even though the cache is fresh
+	jwks.addKey(t, "k2")
+	v.fetchedAt = v.fetchedAt.Add(-googleJWKSMinRefresh)
+	if _, err := v.Verify(signGoogleToken(t, jwks.keys["k2"], "k2", validGoogleClaims())); err != nil {
+		t.Fatalf("Expected rotated key to verify, got %v", err)
+	}
+
+	// Unknown kids don't trigger a fetch again right away
+	before := jwks.hits.Load()
+	if _, err := v.Verify(signGoogleToken(t, jwks.keys["k2"], "k3", validGoogleClaims())); !errors.Is(err, ErrInvalidGoogleToken) {
+		t.Errorf("Expected ErrInvalidGoogleToken, got %v", err)
+	}
+	if jwks.hits.Load() != before {
+		t.Error("Expected no refetch within the minimum refresh interval")
+	}
+}
+
+func TestGoogleTokenVerifier_FetchFailure(t *testing.T) {
+	jwks := newTestJWKS(t, "k1")
+	v := NewGoogleTokenVerifier(testGoogleClientID, jwks.server.URL)
+	token := signGoogleToken(t, jwks.keys["k1"], "k1", validGoogleClaims())
+	if _, err := v.Verify(token); err != nil {
+		t.Fatal(err)
+	}
+
+	// The cache expired and Google is down: the cached key is still used
+	jwks.down.Store(true)
+	v.expiresAt = time.Now().Add(-time.Minute)
+	v.fetchedAt = v.fetchedAt.Add(-googleJWKSMinRefresh)
+	before := jwks.hits.Load()
+	if _, err := v.Verify(token); err != nil {
+		t.Fatalf("Expected the stale key to verify, got %v", err)
+	}
+	if jwks.hits.Load() != before+1 {
+		t.Fatalf("Expected one fetch, got %d", jwks.hits.Load()-before)
+	}
+
+	// A failed fetch isn't retried within the minimum refresh interval
+	if _, err := v.Verify(token); err != nil {
+		t.Fatalf("Expected the stale key to verify, got %v", err)
+	}
+	if jwks.hits.Load() != before+1 {
+		t.Error("Expected no refetch after a failed fetch within the minimum refresh interval")
+	}
+
+	// Keys that expired too long ago are no longer trusted
+	v.expiresAt = time.Now().Add(-googleJWKSMaxStale)
+	v.fetchedAt = v.fetchedAt.Add(-googleJWKSMinRefresh)
+	if _, err := v.Verify(token); !errors.Is(err, ErrInvalidGoogleToken) {
+		t.Errorf("Expected ErrInvalidGoogleToken, got %v", err)
+	}
+}
+
+func TestGoogleTokenVerifier_NotConfigured(t *testing.T) {
+	v := NewGoogleTokenVerifier("", "http://127.0.0.1:0")
+	if _, err := v.Verify("token"); !errors.Is(err, ErrGoogleNotConfigured) {
+		t.Errorf("Expected ErrGoogleNotConfigured, got %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/repository"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+// Size of the board pushed to leaderboard overlays
+const LeaderboardOverlaySize = 10
+
+var leaderboardPeriods = []string{
+	models.LeaderboardAllTime,
+	models.LeaderboardMonthly,
+	models.LeaderboardWeekly,
+	models.LeaderboardSession,
+}
+
+type LeaderboardService struct {
+	repo     *repository.LeaderboardRepository
+	userRepo *repository.UserRepository
+	hub      *StreamHub
+}
+
+func NewLeaderboardService(repo *repository.LeaderboardRepository, userRepo *repository.UserRepository, hub *StreamHub) *LeaderboardService {
+	return &LeaderboardService{
+		repo:     repo,
+		userRepo: userRepo,
+		hub:      hub,
+	}
+}
+
+type UpdateLeaderboardSettingsInput struct {
+	ExcludeAnonymous *bool    `json:"exclude_anonymous"`
+	ExcludedNames    []string `json:"excluded_names" binding:"omitempty,max=100,dive,max=100"`
+}
+
+// LeaderboardTopic returns the StreamHub topic for a leaderboard period
+func LeaderboardTopic(period string) string {
+	return "leaderboard:" + period
+}
+
+// IsValidLeaderboardPeriod reports whether period is a supported window
+func IsValidLeaderboardPeriod(period string) bool {
+	for _, p := range leaderboardPeriods {
+		if p == period {
+			return true
+		}
+	}
+	return false
+}
+
+// periodStart returns the start of the leaderboard window (nil = all time).
+// Weeks start on Monday.
+func periodStart(period string, now time.Time, settings *models.LeaderboardSettings) *time.Time {
+	var start time.Time
+	switch period {
+	case models.LeaderboardMonthly:
+		start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	case models.LeaderboardWeekly:
+		daysSinceMonday := (int(now.Weekday()) + 6) % 7
+		start = time.Date(now.Year(), now.Month(), now.Day()-daysSinceMonday, 0, 0, 0, 0, now.Location())
+	case models.LeaderboardSession:
+		if settings.SessionStartedAt == nil {
+			return nil
+		}
+		start = *settings.SessionStartedAt
+	default:
+		return nil
+	}
+	return &start
+}
+
+// excludedNames merges the creator's excluded names with anonymous names
+func excludedNames(settings *models.LeaderboardSettings) []string {
+	var names []string
+	if settings.ExcludeAnonymous {
+		names = append(names, models.AnonymousNames...)
+	}
+	for _, name := range settings.ExcludedNames {
+		names = append(names, strings.ToLower(strings.TrimSpace(name)))
+	}
+	return names
+}
+
+func (s *LeaderboardService) GetLeaderboard(userID uuid.UUID, period string, limit int) (*models.Leaderboard, error) {
+	if !IsValidLeaderboardPeriod(period) {
+		return nil, errors.New("invalid period")
+	}
+
+	settings, err := s.repo.GetSettings(userID)
+	if err != nil {
+		return nil, errors.New("failed to get leaderboard settings")
+	}
+
+	since := periodStart(period, time.Now(), settings)
+	entries, err := s.repo.TopSupporters(userID, since, excludedNames(settings), limit)
+	if err != nil {
+		return nil, errors.New("failed to get leaderboard")
+	}
+	if entries == nil {
+		entries = []models.LeaderboardEntry{}
+	}
+
+	return &models.Leaderboard{
+		Period:  period,
+		Since:   since,
+		Entries: entries,
+	}, nil
+}
+
+// GetLeaderboardByUsername returns the public leaderboard for a creator page
+func (s *LeaderboardService) GetLeaderboardByUsername(username, period string, limit int) (*models.Leaderboard, error) {
+	user, err := s.userRepo.FindByUsername(username)
+	if err != nil {
+		return nil, errors.New("creator not found")
+	}
+	return s.GetLeaderboard(user.ID, period, limit)
+}
+
+func (s *LeaderboardService) GetSettings(userID uuid.UUID) (*models.LeaderboardSettings, error) {
+	return s.repo.GetSettings(userID)
+}
+
+func (s *LeaderboardService) UpdateSettings(log *utils.RequestLogger, userID uuid.UUID, input *UpdateLeaderboardSettingsInput) (*models.LeaderboardSettings, error) {
+	settings, err := s.repo.GetSettings(userID)
+	if err != nil {
+		return nil, errors.New("failed to get leaderboard settings")
+	}
+
+	if input.ExcludeAnonymous != nil {
+		settings.ExcludeAnonymous = *input.ExcludeAnonymous
+	}
+	if input.ExcludedNames != nil {
+		settings.ExcludedNames = input.ExcludedNames
+	}
+
+	if err := s.repo.SaveSettings(settings); err != nil {
+		return nil, errors.New("failed to update leaderboard settings")
+	}
+
+	s.publish(log, userID)
+	return settings, nil
+}
+
+// StartSession resets the per-stream-session leaderboard
+func (s *LeaderboardService) StartSession(log *utils.RequestLogger, userID uuid.UUID) (*models.LeaderboardSettings, error) {
+	settings, err := s.repo.GetSettings(userID)
+	if err != nil {
+		return nil, errors.New("failed to get leaderboard settings")
+	}
+
+	now := time.Now()
+	settings.SessionStartedAt = &now
+	if err := s.repo.SaveSettings(settings); err != nil {
+		return nil, errors.New("failed to start stream session")
+	}
+
+	s.publish(log, userID)
+	return settings, nil
+}
+
+// OnDonationPaid refreshes leaderboard overlays for the creator
+func (s *LeaderboardService) OnDonationPaid(log *utils.RequestLogger, donation *models.Donation) {
+	s.publish(log, donation.CreatorID)
+}
+
+// OnAlertApproved refreshes leaderboard overlays, since an approved
+// donation's name can now be shown
+func (s *LeaderboardService) OnAlertApproved(log *utils.RequestLogger, donation *models.Donation) {
+	s.publish(log, donation.CreatorID)
+}
+
+// publish recomputes only the periods that have a connected overlay
+func (s *LeaderboardService) publish(log *utils.RequestLogger, userID uuid.UUID) {
+	for _, period := range leaderboardPeriods {
+		topic := LeaderboardTopic(period)
+		if !s.hub.HasClients(topic, userID) {
+			continue
+		}
+
+		board, err := s.GetLeaderboard(userID, period, LeaderboardOverlaySize)
+		if err != nil {
+			log.LogError("LeaderboardService", err, "Failed to refresh leaderboard")
+			continue
+		}
+		s.hub.Broadcast(log, topic, userID, NewOverlayEvent(EventLeaderboard, board))
+	}
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jajanin/backend/internal/models"
+)
+
+func TestPeriodStart_Weekly(t *testing.T) {
+	// Thursday 2024-05-16 15:30
+	now := time.Date(2024, 5, 16, 15, 30, 0, 0, time.UTC)
+	settings := &models.LeaderboardSettings{}
+
+	start := periodStart(models.LeaderboardWeekly, now, settings)
+	expected := time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC) // Monday
+	if start == nil || !start.Equal(expected) {
+		t.Errorf("Expected week start %v, got %v", expected, start)
+	}
+
+	// Sunday belongs to the week that started on the previous Monday
+	sunday := time.Date(2024, 5, 19, 10, 0, 0, 0, time.UTC)
+	start = periodStart(models.LeaderboardWeekly, sunday, settings)
+	if start == nil || !start.Equal(expected) {
+		t.Errorf("Expected week start %v for Sunday, got %v", expected, start)
+	}
+}
+
+func TestPeriodStart_MonthlyAndAllTime(t *testing.T) {
+	now := time.Date(2024, 5, 16, 15, 30, 0, 0, time.UTC)
+	settings := &models.LeaderboardSettings{}
+
+	start := periodStart(models.LeaderboardMonthly, now, settings)
+	expected := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	if start == nil || !start.Equal(expected) {
+		t.Errorf("Expected month start %v, got %v", expected, start)
+	}
+
+	if start := periodStart(models.LeaderboardAllTime, now, settings); start != nil {
+		t.Errorf("Expected nil start for all-time, got %v", start)
+	}
+}
+
+func TestPeriodStart_Session(t *testing.T) {
+	now := time.Now()
+	settings := &models.LeaderboardSettings{}
+
+	// No session started yet falls back to all time
+	if start := periodStart(models.LeaderboardSession, now, settings); start != nil {
+		t.Errorf("Expected nil start without session, got %v", start)
+	}
+
+	sessionStart := now.Add(-2 * time.Hour)
+	settings.SessionStartedAt = &sessionStart
+	start := periodStart(models.LeaderboardSession, now, settings)
+	if start == nil || !start.Equal(sessionStart) {
+		t.Errorf("Expected session start %v, got %v", sessionStart, start)
+	}
+}
+
+func TestExcludedNames(t *testing.T) {
+	settings := &models.LeaderboardSettings{
+		ExcludedNames: []string{"  Mod Bot "},
+	}
+
+	names := excludedNames(settings)
+	if len(names) != 1 || names[0] != "mod bot" {
+		t.Errorf("Expected normalized excluded name, got %v", names)
+	}
+
+	settings.ExcludeAnonymous = true
+	names = excludedNames(settings)
+	if len(names) != len(models.AnonymousNames)+1 {
+		t.Errorf("Expected anonymous names to be included, got %v", names)
+	}
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/repository"
+	"github.com/jajanin/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAccountLocked = errors.New("too many failed login attempts")
+	ErrUserNotFound  = errors.New("user not found")
+)
+
+// AccountLockedError is returned while an email address is locked out.
+// errors.Is(err, ErrAccountLocked) matches it.
+type AccountLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *AccountLockedError) Error() string {
+	minutes := int(e.RetryAfter.Round(time.Minute) / time.Minute)
+	if minutes <= 1 {
+		return ErrAccountLocked.Error() + ", please try again in a minute"
+	}
+	return fmt.Sprintf("%s, please try again in %d minutes", ErrAccountLocked, minutes)
+}
+
+func (e *AccountLockedError) Is(target error) bool {
+	return target == ErrAccountLocked
+}
+
+// LockoutPolicy decides how long an email address is locked after failed logins
+type LockoutPolicy struct {
+	Threshold int           // failures before the first lockout
+	Base      time.Duration // first lockout, doubled with every further failure
+	Max       time.Duration // longest lockout; older failures are forgotten after this
+}
+
+// Duration is the lockout after the given number of consecutive failures
+func (p LockoutPolicy) Duration(failures int) time.Duration {
+	if p.Threshold < 1 || failures < p.Threshold {
+		return 0
+	}
+	d := p.Base
+	for i := p.Threshold; i < failures && d < p.Max; i++ {
+		d *= 2
+	}
+	return min(d, p.Max)
+}
+
+// LoginGuardService locks out email addresses under password guessing and
+// tells users about sign-ins from devices they haven't used before
+type LoginGuardService struct {
+	repo     *repository.LoginSecurityRepository
+	userRepo *repository.UserRepository
+	audit    *AuditService
+	accounts *AccountService
+	policy   LockoutPolicy
+}
+
+func NewLoginGuardService(
+	repo *repository.LoginSecurityRepository,
+	userRepo *repository.UserRepository,
+	audit *AuditService,
+	accounts *AccountService,
+	policy LockoutPolicy,
+) *LoginGuardService {
+	return &LoginGuardService{
+		repo:     repo,
+		userRepo: userRepo,
+		audit:    audit,
+		accounts: accounts,
+		policy:   policy,
+	}
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Check returns an *AccountLockedError while email is locked out
+func (s *LoginGuardService) Check(email string) error {
+	throttle, err := s.repo.FindThrottle(normalizeEmail(email))
+	if err != nil {
+		return nil
+	}
+	now := time.Now()
+	if throttle.IsLocked(now) {
+		return &AccountLockedError{RetryAfter: throttle.LockedUntil.Sub(now)}
+	}
+	return nil
+}
+
+// Failed counts a failed login for email and locks it once the policy says
+// so. userID is nil when no account has that address.
+func (s *LoginGuardService) Failed(log *utils.RequestLogger, email string, userID *uuid.UUID, meta SessionMeta, reason string) {
+	email = normalizeEmail(email)
+	now := time.Now()
+
+	throttle, err := s.repo.RecordFailure(email, now, now.Add(-s.policy.Max))
+	if err != nil {
+		log.LogError("LoginGuardService.Failed", err, "Failed to record failed login")
+		return
+	}
+	s.audit.Record(log, &models.AuditLog{
+		Action:    models.AuditLoginFailed,
+		UserID:    userID,
+		Email:     email,
+		IPAddress: meta.IPAddress,
+		UserAgent: meta.UserAgent,
+		Metadata:  map[string]interface{}{"reason": reason, "failed_count": throttle.FailedCount},
+	})
+
+	lockout := s.policy.Duration(throttle.FailedCount)
+	if lockout == 0 {
+		return
+	}
+	if err := s.repo.Lock(email, now.Add(lockout)); err != nil {
+		log.LogError("LoginGuardService.Failed", err, "Failed to lock account")
+		return
+	}
+	log.LogWarn("LoginGuardService.Failed", fmt.Sprintf("Locked %s for %s after %d failed logins", email, lockout, throttle.FailedCount))
+	s.audit.Record(log, &models.AuditLog{
+		Action:    models.AuditLoginLocked,
+		UserID:    userID,
+		Email:     email,
+		IPAddress: meta.IPAddress,
+		UserAgent: meta.UserAgent,
+		Metadata:  map[string]interface{}{"failed_count": throttle.FailedCount, "locked_seconds": int(lockout.Seconds())},
+	})
+}
+
+// Succeeded clears the failure count and records where the user signed in
+// from, emailing them if the IP address or browser is new for the account
+func (s *LoginGuardService) Succeeded(log *utils.RequestLogger, user *models.User, meta SessionMeta, method string) {
+	if err := s.repo.ClearThrottle(normalizeEmail(user.Email)); err != nil {
+		log.LogError("LoginGuardService.Succeeded", err, "Failed to clear failed logins")
+	}
+	s.audit.Record(log, &models.AuditLog{
+		Action:    models.AuditLoginSucceeded,
+		UserID:    &user.ID,
+		Email:     normalizeEmail(user.Email),
+		IPAddress: meta.IPAddress,
+		UserAgent: meta.UserAgent,
+		Metadata:  map[string]interface{}{"method": method},
+	})
+
+	hasAny, knownIP, knownAgent, err := s.repo.DeviceHistory(user.ID, meta.IPAddress, meta.UserAgent)
+	if err != nil {
+		log.LogError("LoginGuardService.Succeeded", err, "Failed to check login devices")
+		return
+	}
+	now := time.Now()
+	if err := s.repo.TouchDevice(user.ID, meta.IPAddress, meta.UserAgent, now); err != nil {
+		log.LogError("LoginGuardService.Succeeded", err, "Failed to record login device")
+	}
+
+	// The first recorded device is where the account was created or first
+	// seen after this check was added, so there is nothing to compare with
+	if !hasAny || (knownIP && knownAgent) {
+		return
+	}
+	s.audit.Record(log, &models.AuditLog{
+		Action:    models.AuditLoginNewDevice,
+		UserID:    &user.ID,
+		Email:     normalizeEmail(user.Email),
+		IPAddress: meta.IPAddress,
+		UserAgent: meta.UserAgent,
+		Metadata:  map[string]interface{}{"new_ip": !knownIP, "new_user_agent": !knownAgent},
+	})
+	s.accounts.NotifyNewLogin(log, user, meta, now)
+}
+
+// RecordDevice remembers the device an account was created on, so signing
+// in from it later isn't reported as new
+func (s *LoginGuardService) RecordDevice(log *utils.RequestLogger, userID uuid.UUID, meta SessionMeta) {
+	if err := s.repo.TouchDevice(userID, meta.IPAddress, meta.UserAgent, time.Now()); err != nil {
+		log.LogError("LoginGuardService.RecordDevice", err, "Failed to record login device")
+	}
+}
+
+// Unlock lifts a lockout early on behalf of an admin
+func (s *LoginGuardService) Unlock(log *utils.RequestLogger, adminID, userID uuid.UUID) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	email := normalizeEmail(user.Email)
+	if err := s.repo.ClearThrottle(email); err != nil {
+		return errors.New("failed to unlock account")
+	}
+	s.audit.Record(log, &models.AuditLog{
+		Action:  models.AuditAccountUnlocked,
+		UserID:  &user.ID,
+		ActorID: &adminID,
+		Email:   email,
+	})
+	return nil
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLockoutPolicy_Duration(t *testing.T) {
+	p := LockoutPolicy{Threshold: 5, Base: time.Minute, Max: time.Hour}
+
+	cases := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{4, 0},
+		{5, time.Minute},
+		{6, 2 * time.Minute},
+		{8, 8 * time.Minute},
+		{11, time.Hour}, // 64 minutes, capped
+		{1000, time.Hour},
+	}
+	for _, tc := range cases {
+		if got := p.Duration(tc.failures); got != tc.want {
+			t.Errorf("Duration(%d) = %s, want %s", tc.failures, got, tc.want)
+		}
+	}
+
+	if got := (LockoutPolicy{}).Duration(100); got != 0 {
+		t.Errorf("Expected a zero policy to never lock, got %s", got)
+	}
+}
+
+func TestAccountLockedError(t *testing.T) {
+	err := error(&AccountLockedError{RetryAfter: 90 * time.Second})
+	if !errors.Is(err, ErrAccountLocked) {
+		t.Error("Expected errors.Is to match ErrAccountLocked")
+	}
+	if !strings.Contains(err.Error(), "2 minutes") {
+		t.Errorf("Unexpected message %q", err.Error())
+	}
+	if short := (&AccountLockedError{RetryAfter: time.Second}).Error(); !strings.Contains(short, "in a minute") {
+		t.Errorf("Expected at least 1 minute, got %q", short)
+	}
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OverlayEventVersion is the envelope version sent in every event ("v").
+// Bump it only for breaking changes to the envelope itself; new event types
+// and new fields in Data are backwards compatible.
+const OverlayEventVersion = 1
+
+// Overlay event types. Overlays must ignore types they don't know.
+const (
+	EventConnected       = "connected"        // sent once when the stream opens
+	EventDonation        = "donation"         // paid donation alert (AlertData)
+	EventTest            = "test"             // test alert from the dashboard (AlertData)
+	EventGoalProgress    = "goal_progress"    // goal progress changed (GoalProgress)
+	EventGoalReached     = "goal_reached"     // goal target crossed (GoalProgress)
+	EventMilestone       = "milestone"        // goal crossed 25/50/75% (GoalMilestone)
+	EventSettingsChanged = "settings_changed" // alert settings saved (AlertSettings for this overlay)
+	EventQueueState      = "queue_state"      // approval queue size changed (QueueState)
+	EventLeaderboard     = "leaderboard"      // leaderboard widget update
+	EventTimer           = "timer"            // subathon timer state
+	EventPoll            = "poll"             // poll results
+	EventTicker          = "ticker"           // recent supporters snapshot (Ticker)
+	EventTickerItem      = "ticker_item"      // new supporter to prepend (TickerItem)
+)
+
+// OverlayEvent is the envelope for everything pushed over overlay SSE streams
+type OverlayEvent struct {
+	V         int         `json:"v"`
+	ID        uuid.UUID   `json:"id"`
+	Type      string      `json:"type"`
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+func NewOverlayEvent(eventType string, data interface{}) *OverlayEvent {
+	return &OverlayEvent{
+		V:         OverlayEventVersion,
+		ID:        uuid.New(),
+		Type:      eventType,
+		Timestamp: time.Now().UTC(),
+		Data:      data,
+	}
+}
+
+// WithData returns a copy of the event carrying different data (same ID),
+// used when each connection needs its own payload
+func (e *OverlayEvent) WithData(data interface{}) *OverlayEvent {
+	copied := *e
+	copied.Data = data
+	return &copied
+}
+
+// QueueState is the payload of queue_state events
+type QueueState struct {
+	PendingReview int64 `json:"pending_review"`
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OverlaySession is one connected overlay (an OBS browser source)
+type OverlaySession struct {
+	ID              uuid.UUID `json:"id"`
+	Widget          string    `json:"widget"`
+	UserAgent       string    `json:"user_agent"`
+	ConnectedAt     time.Time `json:"connected_at"`
+	LastHeartbeatAt time.Time `json:"last_heartbeat_at"`
+
+	seq uint64 // connection order, for sessions connected at the same instant
+}
+
+// OverlayPresence tracks which overlays are currently connected per creator,
+// so the dashboard can show whether OBS is live before a donation arrives
+type OverlayPresence struct {
+	sessions map[uuid.UUID]map[uuid.UUID]*OverlaySession
+	lastSeq  uint64
+	now      func() time.Time
+	mu       sync.RWMutex
+}
+
+func NewOverlayPresence() *OverlayPresence {
+	return &OverlayPresence{
+		sessions: make(map[uuid.UUID]map[uuid.UUID]*OverlaySession),
+		now:      time.Now,
+	}
+}
+
+// Connect records a new overlay session and returns its ID
+func (p *OverlayPresence) Connect(userID uuid.UUID, widget, userAgent string) uuid.UUID {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	p.lastSeq++
+	session := &OverlaySession{
+		ID:              uuid.New(),
+		Widget:          widget,
+		UserAgent:       userAgent,
+		ConnectedAt:     now,
+		LastHeartbeatAt: now,
+		seq:             p.lastSeq,
+	}
+
+	if p.sessions[userID] == nil {
+		p.sessions[userID] = make(map[uuid.UUID]*OverlaySession)
+	}
+	p.sessions[userID][session.ID] = session
+	return session.ID
+}
+
+// Heartbeat refreshes the session's last heartbeat time
+func (p *OverlayPresence) Heartbeat(userID, sessionID uuid.UUID) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if session, ok := p.sessions[userID][sessionID]; ok {
+		session.LastHeartbeatAt = p.now()
+	}
+}
+
+// Disconnect removes the session
+func (p *OverlayPresence) Disconnect(userID, sessionID uuid.UUID) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.sessions[userID], sessionID)
+	if len(p.sessions[userID]) == 0 {
+		delete(p.sessions, userID)
+	}
+}
+
+// List returns the creator's connected overlays, oldest connection first
+func (p *OverlayPresence) List(userID uuid.UUID) []OverlaySession {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	sessions := make([]OverlaySession, 0, len(p.sessions[userID]))
+	for _, session := range p.sessions[userID] {
+		sessions = append(sessions, *session)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].ConnectedAt.Equal(sessions[j].ConnectedAt) {
+			return sessions[i].ConnectedAt.Before(sessions[j].ConnectedAt)
+		}
+		return sessions[i].seq < sessions[j].seq
+	})
+	return sessions
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+)
+
+// newTestPresence returns a presence whose clock only moves when advanced
+func newTestPresence() (*OverlayPresence, func(time.Duration)) {
+	presence := NewOverlayPresence()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	presence.now = func() time.Time { return now }
+	return presence, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestOverlayPresence_ConnectAndDisconnect(t *testing.T) {
+	presence, _ := newTestPresence()
+	userID := uuid.New()
+	otherID := uuid.New()
+
+	// Both connect at the same instant; connection order decides
+	alertID := presence.Connect(userID, models.OverlayWidgetAlert, "OBS/30.0")
+	presence.Connect(userID, models.OverlayWidgetGoal, "OBS/30.0")
+	presence.Connect(otherID, models.OverlayWidgetAlert, "OBS/29.1")
+
+	sessions := presence.List(userID)
+	if len(sessions) != 2 {
+		t.Fatalf("Expected 2 sessions, got %d", len(sessions))
+	}
+	if sessions[0].ID != alertID || sessions[0].UserAgent != "OBS/30.0" {
+		t.Errorf("Expected alert session first, got %+v", sessions[0])
+	}
+
+	presence.Disconnect(userID, alertID)
+	if got := len(presence.List(userID)); got != 1 {
+		t.Errorf("Expected 1 session after disconnect, got %d", got)
+	}
+	if got := len(presence.List(otherID)); got != 1 {
+		t.Errorf("Expected other creator's session untouched, got %d", got)
+	}
+}
+
+func TestOverlayPresence_Heartbeat(t *testing.T) {
+	presence, advance := newTestPresence()
+	userID := uuid.New()
+
+	id := presence.Connect(userID, models.OverlayWidgetAlert, "OBS/30.0")
+	before := presence.List(userID)[0].LastHeartbeatAt
+
+	advance(30 * time.Second)
+	presence.Heartbeat(userID, id)
+	session := presence.List(userID)[0]
+	if want := before.Add(30 * time.Second); !session.LastHeartbeatAt.Equal(want) {
+		t.Errorf("Expected heartbeat at %v, got %v", want, session.LastHeartbeatAt)
+	}
+	if !session.ConnectedAt.Equal(before) {
+		t.Errorf("Expected connected_at to stay %v, got %v", before, session.ConnectedAt)
+	}
+
+	// Heartbeat for an unknown session is a no-op
+	presence.Heartbeat(userID, uuid.New())
+	if got := len(presence.List(userID)); got != 1 {
+		t.Errorf("Expected 1 session, got %d", got)
+	}
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/repository"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+// profileStore is the OverlayProfileRepository as the service uses it, an
+// interface so tests can run without a database
+type profileStore interface {
+	Create(profile *models.OverlayProfile) error
+	FindByID(id, userID uuid.UUID) (*models.OverlayProfile, error)
+	FindByUserID(userID uuid.UUID) ([]models.OverlayProfile, error)
+	CountByUserID(userID uuid.UUID) (int64, error)
+	Update(profile *models.OverlayProfile) error
+	Delete(id, userID uuid.UUID) error
+}
+
+type OverlayProfileService struct {
+	profileRepo         profileStore
+	userService         *UserService
+	overlayTokenService *OverlayTokenService
+}
+
+func NewOverlayProfileService(profileRepo *repository.OverlayProfileRepository, userService *UserService, overlayTokenService *OverlayTokenService) *OverlayProfileService {
+	return &OverlayProfileService{
+		profileRepo:         profileRepo,
+		userService:         userService,
+		overlayTokenService: overlayTokenService,
+	}
+}
+
+type CreateOverlayProfileInput struct {
+	Name        string          `json:"name" binding:"required,min=1,max=100"`
+	Settings    json.RawMessage `json:"settings"`     // partial settings, missing fields use defaults
+	CreateToken bool            `json:"create_token"` // also mint an alert token bound to the profile
+}
+
+type UpdateOverlayProfileInput struct {
+	Name     *string         `json:"name" binding:"omitempty,min=1,max=100"`
+	Settings json.RawMessage `json:"settings"` // partial update of the profile's settings
+}
+
+type CloneOverlayProfileInput struct {
+	Name string `json:"name" binding:"omitempty,max=100"`
+}
+
+// OverlayProfileResponse is a profile with its parsed settings. Token is
+// only set when a token was created along with the profile (shown once).
+type OverlayProfileResponse struct {
+	*models.OverlayProfile
+	Settings *models.AlertSettings       `json:"settings"`
+	Token    *CreateOverlayTokenResponse `json:"token,omitempty"`
+}
+
+func toProfileResponse(profile *models.OverlayProfile) *OverlayProfileResponse {
+	settings := models.ParseAlertSettings(profile.Settings)
+	return &OverlayProfileResponse{OverlayProfile: profile, Settings: &settings}
+}
+
+// decodeSettings applies a partial settings body on top of base and checks asset ownership
+func (s *OverlayProfileService) decodeSettings(userID uuid.UUID, base models.AlertSettings, raw json.RawMessage) ([]byte, error) {
+	settings := &base
+	if len(raw) > 0 {
+		var err error
+		if settings, err = models.DecodeAlertSettings(base, raw); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.userService.ValidateSettingsAssets(userID, settings); err != nil {
+		return nil, err
+	}
+	return json.Marshal(settings)
+}
+
+func (s *OverlayProfileService) checkLimit(userID uuid.UUID) error {
+	count, err := s.profileRepo.CountByUserID(userID)
+	if err != nil {
+		return errors.New("failed to count overlay profiles")
+	}
+	if count >= models.MaxOverlayProfiles {
+		return fmt.Errorf("you can have at most %d overlay profiles", models.MaxOverlayProfiles)
+	}
+	return nil
+}
+
+func (s *OverlayProfileService) Create(userID uuid.UUID, input *CreateOverlayProfileInput) (*OverlayProfileResponse, error) {
+	if err := s.checkLimit(userID); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.decodeSettings(userID, models.DefaultAlertSettings(), input.Settings)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &models.OverlayProfile{
+		UserID:   userID,
+		Name:     strings.TrimSpace(input.Name),
+		Settings: settings,
+	}
+	if err := s.profileRepo.Create(profile); err != nil {
+		return nil, errors.New("failed to create overlay profile")
+	}
+
+	resp := toProfileResponse(profile)
+	if input.CreateToken {
+		token, err := s.overlayTokenService.Create(userID, &CreateOverlayTokenInput{
+			Label:     "Profile: " + profile.Name,
+			Widget:    models.OverlayWidgetAlert,
+			ProfileID: &profile.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		resp.Token = token
+	}
+	return resp, nil
+}
+
+func (s *OverlayProfileService) GetAll(userID uuid.UUID) ([]*OverlayProfileResponse, error) {
+	profiles, err := s.profileRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]*OverlayProfileResponse, len(profiles))
+	for i := range profiles {
+		resp[i] = toProfileResponse(&profiles[i])
+	}
+	return resp, nil
+}
+
+func (s *OverlayProfileService) GetByID(userID, id uuid.UUID) (*OverlayProfileResponse, error) {
+	profile, err := s.profileRepo.FindByID(id, userID)
+	if err != nil {
+		return nil, errors.New("overlay profile not found")
+	}
+	return toProfileResponse(profile), nil
+}
+
+func (s *OverlayProfileService) Update(log *utils.RequestLogger, userID, id uuid.UUID, input *UpdateOverlayProfileInput) (*OverlayProfileResponse, error) {
+	profile, err := s.profileRepo.FindByID(id, userID)
+	if err != nil {
+		return nil, errors.New("overlay profile not found")
+	}
+
+	if input.Name != nil {
+		profile.Name = strings.TrimSpace(*input.Name)
+	}
+	if len(input.Settings) > 0 {
+		settings, err := s.decodeSettings(userID, models.ParseAlertSettings(profile.Settings), input.Settings)
+		if err != nil {
+			return nil, err
+		}
+		profile.Settings = settings
+	}
+
+	if err := s.profileRepo.Update(profile); err != nil {
+		return nil, errors.New("failed to update overlay profile")
+	}
+
+	s.userService.NotifyAlertSettingsChanged(log, userID)
+	return toProfileResponse(profile), nil
+}
+
+// Clone copies a profile's settings into a new profile
+func (s *OverlayProfileService) Clone(userID, id uuid.UUID, name string) (*OverlayProfileResponse, error) {
+	source, err := s.profileRepo.FindByID(id, userID)
+	if err != nil {
+		return nil, errors.New("overlay profile not found")
+	}
+	if err := s.checkLimit(userID); err != nil {
+		return nil, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = source.Name + " (copy)"
+	}
+
+	clone := &models.OverlayProfile{
+		UserID:   userID,
+		Name:     name,
+		Settings: source.Settings,
+	}
+	if err := s.profileRepo.Create(clone); err != nil {
+		return nil, errors.New("failed to clone overlay profile")
+	}
+	return toProfileResponse(clone), nil
+}
+
+// SetDefault copies the profile's settings into the creator's own alert
+// settings, which stream keys and unbound tokens use. There is no separate
+// default profile, so the alert settings page keeps editing what overlays show.
+func (s *OverlayProfileService) SetDefault(log *utils.RequestLogger, userID, id uuid.UUID) (*OverlayProfileResponse, error) {
+	profile, err := s.profileRepo.FindByID(id, userID)
+	if err != nil {
+		return nil, errors.New("overlay profile not found")
+	}
+	if _, err := s.userService.UpdateAlertSettings(log, userID, profile.Settings); err != nil {
+		return nil, err
+	}
+	return s.GetByID(userID, id)
+}
+
+func (s *OverlayProfileService) Delete(log *utils.RequestLogger, userID, id uuid.UUID) error {
+	if _, err := s.profileRepo.FindByID(id, userID); err != nil {
+		return errors.New("overlay profile not found")
+	}
+	if err := s.profileRepo.Delete(id, userID); err != nil {
+		return errors.New("failed to delete overlay profile")
+	}
+
+	s.userService.NotifyAlertSettingsChanged(log, userID)
+	return nil
+}
+
+// SettingsFor returns the alert settings an overlay should render with:
+// the profile bound to the token, else the creator's own alert settings.
+func (s *OverlayProfileService) SettingsFor(user *models.User, token *models.OverlayToken) *models.AlertSettings {
+	if token != nil && token.ProfileID != nil {
+		if profile, err := s.profileRepo.FindByID(*token.ProfileID, user.ID); err == nil {
+			settings := models.ParseAlertSettings(profile.Settings)
+			return &settings
+		}
+	}
+
+	return s.userService.GetAlertSettingsForUser(user)
+}
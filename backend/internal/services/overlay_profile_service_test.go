@@ -0,0 +1,215 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// MockOverlayProfileRepository keeps profiles in memory. Like the
+// repository, deleting a profile unbinds the overlay tokens pointing at it.
+type MockOverlayProfileRepository struct {
+	profiles map[uuid.UUID]*models.OverlayProfile
+	tokens   []*models.OverlayToken
+}
+
+var _ profileStore = (*MockOverlayProfileRepository)(nil)
+
+func NewMockOverlayProfileRepository() *MockOverlayProfileRepository {
+	return &MockOverlayProfileRepository{profiles: make(map[uuid.UUID]*models.OverlayProfile)}
+}
+
+func (m *MockOverlayProfileRepository) Create(profile *models.OverlayProfile) error {
+	profile.ID = uuid.New()
+	profile.CreatedAt = time.Now()
+	copied := *profile
+	m.profiles[profile.ID] = &copied
+	return nil
+}
+
+func (m *MockOverlayProfileRepository) FindByID(id, userID uuid.UUID) (*models.OverlayProfile, error) {
+	if profile, ok := m.profiles[id]; ok && profile.UserID == userID {
+		copied := *profile
+		return &copied, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MockOverlayProfileRepository) FindByUserID(userID uuid.UUID) ([]models.OverlayProfile, error) {
+	var profiles []models.OverlayProfile
+	for _, profile := range m.profiles {
+		if profile.UserID == userID {
+			profiles = append(profiles, *profile)
+		}
+	}
+	return profiles, nil
+}
+
+func (m *MockOverlayProfileRepository) CountByUserID(userID uuid.UUID) (int64, error) {
+	profiles, err := m.FindByUserID(userID)
+	return int64(len(profiles)), err
+}
+
+func (m *MockOverlayProfileRepository) Update(profile *models.OverlayProfile) error {
+	copied := *profile
+	m.profiles[profile.ID] = &copied
+	return nil
+}
+
+func (m *MockOverlayProfileRepository) Delete(id, userID uuid.UUID) error {
+	for _, token := range m.tokens {
+		if token.UserID == userID && token.ProfileID != nil && *token.ProfileID == id {
+			token.ProfileID = nil
+		}
+	}
+	if profile, ok := m.profiles[id]; ok && profile.UserID == userID {
+		delete(m.profiles, id)
+	}
+	return nil
+}
+
+func TestOverlayProfileService_SettingsFor(t *testing.T) {
+	mockRepo := NewMockOverlayProfileRepository()
+	mockUserRepo := NewMockUserRepository()
+	s := &OverlayProfileService{profileRepo: mockRepo, userService: &UserService{userRepo: mockUserRepo}}
+
+	userSettings, _ := json.Marshal(map[string]int{"duration": 3})
+	user := &models.User{ID: uuid.New(), AlertSettings: userSettings}
+	mockUserRepo.AddUser(user)
+
+	valorantSettings, _ := json.Marshal(map[string]int{"duration": 5})
+	valorant, err := s.Create(user.ID, &CreateOverlayProfileInput{Name: "Valorant", Settings: valorantSettings})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSettings, _ := json.Marshal(map[string]int{"duration": 9})
+	other, err := s.Create(uuid.New(), &CreateOverlayProfileInput{Name: "Other", Settings: otherSettings})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token *models.OverlayToken
+		want  int
+	}{
+		{"stream key", nil, 3},
+		{"unbound token", &models.OverlayToken{UserID: user.ID}, 3},
+		{"bound token", &models.OverlayToken{UserID: user.ID, ProfileID: &valorant.ID}, 5},
+		// A profile of another creator is never used
+		{"another creator's profile", &models.OverlayToken{UserID: user.ID, ProfileID: &other.ID}, 3},
+	}
+	for _, tt := range tests {
+		if got := s.SettingsFor(user, tt.token).Duration; got != tt.want {
+			t.Errorf("%s: expected duration %d, got %d", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestOverlayProfileService_SetDefaultKeepsAlertSettingsEditable(t *testing.T) {
+	mockRepo := NewMockOverlayProfileRepository()
+	mockUserRepo := NewMockUserRepository()
+	users := &UserService{userRepo: mockUserRepo, alertService: NewAlertService(NewSubscribers(StreamLimits{}))}
+	s := &OverlayProfileService{profileRepo: mockRepo, userService: users}
+	log := utils.NewRequestLogger("test")
+
+	user := &models.User{Email: "budi@example.com"}
+	mockUserRepo.AddUser(user)
+	unbound := &models.OverlayToken{UserID: user.ID}
+
+	settings, _ := json.Marshal(map[string]int{"duration": 7})
+	profile, err := s.Create(user.ID, &CreateOverlayProfileInput{Name: "Just Chatting", Settings: settings})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.SetDefault(log, user.ID, profile.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.SettingsFor(user, unbound).Duration; got != 7 {
+		t.Errorf("Expected the profile's settings as the default, got %d", got)
+	}
+
+	// The alert settings page still changes what unbound overlays show
+	if _, err := users.PatchAlertSettings(log, user.ID, []byte(`{"duration": 4}`)); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.SettingsFor(user, unbound).Duration; got != 4 {
+		t.Errorf("Expected the edited alert settings, got %d", got)
+	}
+	if stored, _ := mockRepo.FindByID(profile.ID, user.ID); models.ParseAlertSettings(stored.Settings).Duration != 7 {
+		t.Error("Expected the profile itself to keep its settings")
+	}
+
+	if _, err := s.SetDefault(log, uuid.New(), profile.ID); err == nil {
+		t.Error("Expected another creator's profile to be refused")
+	}
+}
+
+func TestOverlayProfileService_Limit(t *testing.T) {
+	mockRepo := NewMockOverlayProfileRepository()
+	s := &OverlayProfileService{profileRepo: mockRepo}
+	userID := uuid.New()
+
+	var first *OverlayProfileResponse
+	for i := 0; i < models.MaxOverlayProfiles; i++ {
+		profile, err := s.Create(userID, &CreateOverlayProfileInput{Name: "Profile"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if first == nil {
+			first = profile
+		}
+	}
+
+	_, err := s.Create(userID, &CreateOverlayProfileInput{Name: "One too many"})
+	if err == nil || !strings.Contains(err.Error(), "at most 20") {
+		t.Errorf("Expected the profile limit, got %v", err)
+	}
+	if _, err := s.Clone(userID, first.ID, ""); err == nil {
+		t.Error("Expected cloning past the limit to fail")
+	}
+
+	// The limit is per creator
+	if _, err := s.Create(uuid.New(), &CreateOverlayProfileInput{Name: "Profile"}); err != nil {
+		t.Errorf("Expected another creator's profile to be created, got %v", err)
+	}
+}
+
+func TestOverlayProfileService_DeleteUnbindsTokens(t *testing.T) {
+	mockRepo := NewMockOverlayProfileRepository()
+	mockUserRepo := NewMockUserRepository()
+	s := &OverlayProfileService{profileRepo: mockRepo, userService: &UserService{userRepo: mockUserRepo}}
+	log := utils.NewRequestLogger("test")
+
+	userSettings, _ := json.Marshal(map[string]int{"duration": 10})
+	user := &models.User{ID: uuid.New(), AlertSettings: userSettings}
+	mockUserRepo.AddUser(user)
+
+	settings, _ := json.Marshal(map[string]int{"duration": 5})
+	deleted, err := s.Create(user.ID, &CreateOverlayProfileInput{Name: "Valorant", Settings: settings})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := &models.OverlayToken{UserID: user.ID, ProfileID: &deleted.ID}
+	mockRepo.tokens = append(mockRepo.tokens, token)
+
+	if err := s.Delete(log, user.ID, deleted.ID); err != nil {
+		t.Fatal(err)
+	}
+	if token.ProfileID != nil {
+		t.Errorf("Expected the token to be unbound, got profile %v", token.ProfileID)
+	}
+	if got := s.SettingsFor(user, token).Duration; got != 10 {
+		t.Errorf("Expected the user's settings after the token's profile was deleted, got %d", got)
+	}
+
+	if err := s.Delete(log, user.ID, deleted.ID); err == nil {
+		t.Error("Expected deleting a missing profile to fail")
+	}
+}
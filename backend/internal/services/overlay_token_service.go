@@ -0,0 +1,157 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/repository"
+)
+
+// Overlay tokens are prefixed so they can be told apart from legacy stream keys
+const OverlayTokenPrefix = "ovl_"
+
+// overlayTokenTouchInterval is how often last-used is written for a token
+const overlayTokenTouchInterval = time.Minute
+
+var ErrInvalidOverlayToken = errors.New("invalid overlay token")
+
+type OverlayTokenService struct {
+	tokenRepo   *repository.OverlayTokenRepository
+	profileRepo *repository.OverlayProfileRepository
+	userService *UserService
+}
+
+func NewOverlayTokenService(tokenRepo *repository.OverlayTokenRepository, profileRepo *repository.OverlayProfileRepository, userService *UserService) *OverlayTokenService {
+	return &OverlayTokenService{
+		tokenRepo:   tokenRepo,
+		profileRepo: profileRepo,
+		userService: userService,
+	}
+}
+
+type CreateOverlayTokenInput struct {
+	Label     string     `json:"label" binding:"required,min=1,max=100"`
+	Widget    string     `json:"widget" binding:"required"`
+	ProfileID *uuid.UUID `json:"profile_id"` // alert widget only
+}
+
+// CreateOverlayTokenResponse includes the plain token, which is only shown once
+type CreateOverlayTokenResponse struct {
+	Token        string               `json:"token"`
+	OverlayToken *models.OverlayToken `json:"overlay_token"`
+}
+
+func hashOverlayToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateOverlayToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return OverlayTokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (s *OverlayTokenService) Create(userID uuid.UUID, input *CreateOverlayTokenInput) (*CreateOverlayTokenResponse, error) {
+	if !models.IsValidOverlayWidget(input.Widget) {
+		return nil, errors.New("invalid widget, use one of: " + strings.Join(models.OverlayWidgets, ", "))
+	}
+	if input.ProfileID != nil {
+		if input.Widget != models.OverlayWidgetAlert {
+			return nil, errors.New("only alert tokens can be bound to a profile")
+		}
+		if _, err := s.profileRepo.FindByID(*input.ProfileID, userID); err != nil {
+			return nil, errors.New("overlay profile not found")
+		}
+	}
+
+	plain, err := generateOverlayToken()
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	token := &models.OverlayToken{
+		UserID:      userID,
+		Label:       input.Label,
+		Widget:      input.Widget,
+		ProfileID:   input.ProfileID,
+		TokenHash:   hashOverlayToken(plain),
+		TokenPrefix: plain[:len(OverlayTokenPrefix)+6],
+	}
+	if err := s.tokenRepo.Create(token); err != nil {
+		return nil, errors.New("failed to create overlay token")
+	}
+
+	return &CreateOverlayTokenResponse{Token: plain, OverlayToken: token}, nil
+}
+
+func (s *OverlayTokenService) GetAll(userID uuid.UUID) ([]models.OverlayToken, error) {
+	return s.tokenRepo.FindByUserID(userID)
+}
+
+func (s *OverlayTokenService) Revoke(userID, tokenID uuid.UUID) error {
+	ok, err := s.tokenRepo.Revoke(tokenID, userID)
+	if err != nil {
+		return errors.New("failed to revoke overlay token")
+	}
+	if !ok {
+		return errors.New("overlay token not found")
+	}
+	return nil
+}
+
+// Resolve returns the creator behind an overlay credential for the given
+// widget. Overlay tokens must be scoped to the widget; legacy stream keys
+// (including a previous key in its grace period) can read every widget.
+func (s *OverlayTokenService) Resolve(credential, widget string) (*models.User, error) {
+	user, _, err := s.ResolveToken(credential, widget)
+	return user, err
+}
+
+// ResolveToken is Resolve that also returns the overlay token used
+// (nil for legacy stream keys), e.g. to find the profile it is bound to
+func (s *OverlayTokenService) ResolveToken(credential, widget string) (*models.User, *models.OverlayToken, error) {
+	return s.resolve(credential, widget, true)
+}
+
+// StillValid re-checks a credential of a connected overlay without
+// recording it as used, for the periodic checks on open streams
+func (s *OverlayTokenService) StillValid(credential, widget string) bool {
+	_, _, err := s.resolve(credential, widget, false)
+	return err == nil
+}
+
+func (s *OverlayTokenService) resolve(credential, widget string, touch bool) (*models.User, *models.OverlayToken, error) {
+	if !strings.HasPrefix(credential, OverlayTokenPrefix) {
+		user, err := s.userService.GetByStreamKey(credential)
+		if err != nil {
+			return nil, nil, ErrInvalidOverlayToken
+		}
+		return user, nil, nil
+	}
+
+	token, err := s.tokenRepo.FindActiveByHash(hashOverlayToken(credential))
+	if err != nil || token.Widget != widget {
+		return nil, nil, ErrInvalidOverlayToken
+	}
+
+	user, err := s.userService.GetByID(token.UserID)
+	if err != nil {
+		return nil, nil, ErrInvalidOverlayToken
+	}
+
+	// Overlays reconnect often; don't write on every connection
+	if touch && (token.LastUsedAt == nil || time.Since(*token.LastUsedAt) > overlayTokenTouchInterval) {
+		_ = s.tokenRepo.TouchLastUsed(token.ID) // Not critical
+	}
+	return user, token, nil
+}
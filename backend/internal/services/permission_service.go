@@ -0,0 +1,285 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/repository"
+	"github.com/jajanin/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// permissionCacheTTL bounds how long a change made on another instance can
+// take to apply here. Changes made through this service apply at once.
+const permissionCacheTTL = time.Minute
+
+var (
+	ErrRoleNotFound      = errors.New("role not found")
+	ErrRoleNotEditable   = errors.New("the admin role always has every permission")
+	ErrUnknownPermission = errors.New("unknown permission")
+	ErrOwnRole           = errors.New("you can't change your own role")
+)
+
+// PermissionSet is the set of permissions granted by a role
+type PermissionSet map[string]bool
+
+func NewPermissionSet(permissions []string) PermissionSet {
+	set := make(PermissionSet, len(permissions))
+	for _, p := range permissions {
+		set[p] = true
+	}
+	return set
+}
+
+// Has reports whether the set grants perm, directly or through PermAll
+func (s PermissionSet) Has(perm string) bool {
+	return s[models.PermAll] || s[perm]
+}
+
+// List returns the granted permissions, with PermAll expanded
+func (s PermissionSet) List() []string {
+	if s[models.PermAll] {
+		return append([]string{}, models.Permissions...)
+	}
+	list := make([]string, 0, len(s))
+	for p := range s {
+		list = append(list, p)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Principal is what the admin routes need to know about a user
+type Principal struct {
+	Role             string
+	Permissions      PermissionSet
+	TwoFactorEnabled bool
+}
+
+// IsStaff reports whether the principal may open the admin panel at all
+func (p *Principal) IsStaff() bool {
+	return len(p.Permissions) > 0
+}
+
+type cachedPrincipal struct {
+	role             string
+	twoFactorEnabled bool
+	expiresAt        time.Time
+}
+
+// PermissionService resolves users to their role's permissions. Both the
+// user → role lookup and the role → permissions table are cached, so the
+// admin routes don't query the database on every request.
+type PermissionService struct {
+	roleRepo *repository.RoleRepository
+	userRepo *repository.UserRepository
+	audit    *AuditService
+
+	// loaders are fields so tests can run without a database
+	loadUser  func(userID uuid.UUID) (role string, twoFactorEnabled bool, err error)
+	loadRoles func() (map[string]PermissionSet, error)
+	now       func() time.Time
+
+	mu             sync.Mutex
+	users          map[uuid.UUID]cachedPrincipal
+	roles          map[string]PermissionSet
+	rolesExpiresAt time.Time
+}
+
+func NewPermissionService(roleRepo *repository.RoleRepository, userRepo *repository.UserRepository, twoFactorRepo *repository.TwoFactorRepository, audit *AuditService) *PermissionService {
+	s := &PermissionService{
+		roleRepo: roleRepo,
+		userRepo: userRepo,
+		audit:    audit,
+		now:      time.Now,
+		users:    map[uuid.UUID]cachedPrincipal{},
+	}
+	s.loadUser = func(userID uuid.UUID) (string, bool, error) {
+		user, err := userRepo.FindByID(userID)
+		if err != nil {
+			return "", false, err
+		}
+		tf, err := twoFactorRepo.FindByUserID(userID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", false, err
+		}
+		return user.Role, tf != nil && tf.IsEnabled(), nil
+	}
+	s.loadRoles = func() (map[string]PermissionSet, error) {
+		roles, err := roleRepo.FindAll()
+		if err != nil {
+			return nil, err
+		}
+		byName := make(map[string]PermissionSet, len(roles))
+		for _, role := range roles {
+			byName[role.Name] = NewPermissionSet(role.Permissions)
+		}
+		return byName, nil
+	}
+	return s
+}
+
+// Principal returns the user's role, permissions and two-factor status
+func (s *PermissionService) Principal(userID uuid.UUID) (*Principal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	cached, ok := s.users[userID]
+	if !ok || now.After(cached.expiresAt) {
+		role, enabled, err := s.loadUser(userID)
+		if err != nil {
+			return nil, err
+		}
+		cached = cachedPrincipal{role: role, twoFactorEnabled: enabled, expiresAt: now.Add(permissionCacheTTL)}
+		s.users[userID] = cached
+	}
+
+	if s.roles == nil || now.After(s.rolesExpiresAt) {
+		roles, err := s.loadRoles()
+		if err != nil {
+			return nil, err
+		}
+		s.roles = roles
+		s.rolesExpiresAt = now.Add(permissionCacheTTL)
+	}
+
+	permissions := s.roles[cached.role]
+	if cached.role == models.RoleUser || permissions == nil {
+		permissions = PermissionSet{}
+	}
+	return &Principal{
+		Role:             cached.role,
+		Permissions:      permissions,
+		TwoFactorEnabled: cached.twoFactorEnabled,
+	}, nil
+}
+
+// InvalidateUser drops the cached role and two-factor status of a user
+func (s *PermissionService) InvalidateUser(userID uuid.UUID) {
+	s.mu.Lock()
+	delete(s.users, userID)
+	s.mu.Unlock()
+}
+
+// InvalidateRoles drops the cached role table
+func (s *PermissionService) InvalidateRoles() {
+	s.mu.Lock()
+	s.roles = nil
+	s.mu.Unlock()
+}
+
+// OnTwoFactorChanged keeps the cached two-factor status current
+func (s *PermissionService) OnTwoFactorChanged(userID uuid.UUID) {
+	s.InvalidateUser(userID)
+}
+
+// RoleSummary is a role as listed in the admin panel
+type RoleSummary struct {
+	models.Role
+	Editable bool `json:"editable"`
+}
+
+// ListRoles returns every role with its permissions
+func (s *PermissionService) ListRoles() ([]RoleSummary, error) {
+	roles, err := s.roleRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]RoleSummary, len(roles))
+	for i, role := range roles {
+		result[i] = RoleSummary{Role: role, Editable: role.Name != models.RoleAdmin}
+	}
+	return result, nil
+}
+
+type UpdateRoleInput struct {
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+// UpdateRolePermissions replaces the permissions of a role. The admin role
+// can't be changed, so there is always someone who can manage roles.
+func (s *PermissionService) UpdateRolePermissions(log *utils.RequestLogger, actorID uuid.UUID, name string, input *UpdateRoleInput) (*models.Role, error) {
+	if name == models.RoleAdmin {
+		return nil, ErrRoleNotEditable
+	}
+	role, err := s.roleRepo.FindByName(name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+
+	permissions := NewPermissionSet(nil)
+	for _, p := range input.Permissions {
+		if !models.IsValidPermission(p) {
+			return nil, ErrUnknownPermission
+		}
+		permissions[p] = true
+	}
+	list := permissions.List()
+
+	if err := s.roleRepo.UpdatePermissions(name, list); err != nil {
+		return nil, errors.New("failed to update role")
+	}
+	s.InvalidateRoles()
+
+	s.audit.Record(log, &models.AuditLog{
+		Action:   models.AuditRoleUpdated,
+		ActorID:  &actorID,
+		Metadata: map[string]interface{}{"role": name, "before": []string(role.Permissions), "after": list},
+	})
+
+	role.Permissions = list
+	return role, nil
+}
+
+type SetUserRoleInput struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// SetUserRole assigns a role to a user. Admins can't change their own role,
+// which would risk leaving nobody able to manage roles.
+func (s *PermissionService) SetUserRole(log *utils.RequestLogger, actorID, userID uuid.UUID, input *SetUserRoleInput) error {
+	if actorID == userID {
+		return ErrOwnRole
+	}
+	if input.Role != models.RoleUser {
+		if _, err := s.roleRepo.FindByName(input.Role); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRoleNotFound
+			}
+			return err
+		}
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	if user.Role == input.Role {
+		return nil
+	}
+
+	if err := s.userRepo.UpdateRole(userID, input.Role); err != nil {
+		return errors.New("failed to update role")
+	}
+	s.InvalidateUser(userID)
+
+	s.audit.Record(log, &models.AuditLog{
+		Action:   models.AuditUserRoleChanged,
+		UserID:   &user.ID,
+		ActorID:  &actorID,
+		Email:    user.Email,
+		Metadata: map[string]interface{}{"before": user.Role, "after": input.Role},
+	})
+	return nil
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+)
+
+func TestPermissionSet_Has(t *testing.T) {
+	finance := NewPermissionSet([]string{models.PermWithdrawalsRead, models.PermWithdrawalsWrite})
+	if !finance.Has(models.PermWithdrawalsWrite) {
+		t.Error("Expected finance to handle withdrawals")
+	}
+	if finance.Has(models.PermProductsManage) {
+		t.Error("Expected finance not to manage products")
+	}
+
+	admin := NewPermissionSet([]string{models.PermAll})
+	for _, perm := range models.Permissions {
+		if !admin.Has(perm) {
+			t.Errorf("Expected admin to have %s", perm)
+		}
+	}
+	if got := len(admin.List()); got != len(models.Permissions) {
+		t.Errorf("Expected the wildcard to list every permission, got %d", got)
+	}
+}
+
+// newTestPermissionService counts how often the database would be queried
+func newTestPermissionService(roles map[string]string, permissions map[string][]string) (*PermissionService, *int, *int, *time.Time) {
+	userLoads, roleLoads := 0, 0
+	now := time.Now()
+	s := &PermissionService{
+		users: map[uuid.UUID]cachedPrincipal{},
+		now:   func() time.Time { return now },
+	}
+	s.loadUser = func(userID uuid.UUID) (string, bool, error) {
+		userLoads++
+		return roles[userID.String()], true, nil
+	}
+	s.loadRoles = func() (map[string]PermissionSet, error) {
+		roleLoads++
+		byName := map[string]PermissionSet{}
+		for name, perms := range permissions {
+			byName[name] = NewPermissionSet(perms)
+		}
+		return byName, nil
+	}
+	return s, &userLoads, &roleLoads, &now
+}
+
+func TestPermissionService_Cache(t *testing.T) {
+	userID := uuid.New()
+	roles := map[string]string{userID.String(): models.RoleSupport}
+	permissions := map[string][]string{models.RoleSupport: {models.PermUsersRead}}
+	s, userLoads, roleLoads, now := newTestPermissionService(roles, permissions)
+
+	for i := 0; i < 3; i++ {
+		p, err := s.Principal(userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !p.IsStaff() || !p.Permissions.Has(models.PermUsersRead) || p.Permissions.Has(models.PermUsersWrite) {
+			t.Fatalf("Unexpected principal %+v", p)
+		}
+	}
+	if *userLoads != 1 || *roleLoads != 1 {
+		t.Errorf("Expected one load each, got %d user and %d role loads", *userLoads, *roleLoads)
+	}
+
+	// A role change applies at once after invalidation
+	permissions[models.RoleSupport] = []string{models.PermUsersRead, models.PermUsersWrite}
+	s.InvalidateRoles()
+	if p, _ := s.Principal(userID); !p.Permissions.Has(models.PermUsersWrite) {
+		t.Error("Expected the updated role to apply after InvalidateRoles")
+	}
+
+	// So does assigning another role to the user
+	roles[userID.String()] = models.RoleUser
+	s.InvalidateUser(userID)
+	if p, _ := s.Principal(userID); p.IsStaff() {
+		t.Error("Expected a demoted user to lose staff access")
+	}
+
+	// Changes made elsewhere are picked up once the cache expires
+	roles[userID.String()] = models.RoleSupport
+	*now = now.Add(permissionCacheTTL + time.Second)
+	if p, _ := s.Principal(userID); !p.IsStaff() {
+		t.Error("Expected the cache to expire")
+	}
+	if *userLoads != 3 || *roleLoads != 3 {
+		t.Errorf("Expected three loads each, got %d user and %d role loads", *userLoads, *roleLoads)
+	}
+}
+
+func TestPermissionService_UnknownRole(t *testing.T) {
+	userID := uuid.New()
+	s, _, _, _ := newTestPermissionService(map[string]string{userID.String(): "retired"}, nil)
+
+	p, err := s.Principal(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.IsStaff() {
+		t.Error("Expected a role without a definition to grant nothing")
+	}
+}
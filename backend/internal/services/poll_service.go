@@ -0,0 +1,146 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/repository"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+const TopicPoll = "poll"
+
+// pollStore is the PollRepository as PollService and DonationService use
+// it, an interface so tests can run without a database
+type pollStore interface {
+	CreateClosingOpen(poll *models.Poll) ([]models.Poll, error)
+	FindByID(id uuid.UUID) (*models.Poll, error)
+	FindByUserID(userID uuid.UUID, limit int) ([]models.Poll, error)
+	FindOpenByUserID(userID uuid.UUID) (*models.Poll, error)
+	FindOpenOption(optionID, userID uuid.UUID) (*models.PollOption, error)
+	Close(id uuid.UUID) (bool, error)
+	AddVote(optionID uuid.UUID, amount int64) (bool, error)
+}
+
+type PollService struct {
+	pollRepo pollStore
+	userRepo userStore
+	hub      *StreamHub
+}
+
+func NewPollService(pollRepo *repository.PollRepository, userRepo *repository.UserRepository, hub *StreamHub) *PollService {
+	return &PollService{
+		pollRepo: pollRepo,
+		userRepo: userRepo,
+		hub:      hub,
+	}
+}
+
+type CreatePollInput struct {
+	Question string   `json:"question" binding:"required,min=1,max=200"`
+	Options  []string `json:"options" binding:"required,min=2,max=10,dive,min=1,max=100"`
+}
+
+// Create opens a new poll. Any poll that is still open is closed first,
+// so a creator only runs one poll at a time.
+func (s *PollService) Create(log *utils.RequestLogger, userID uuid.UUID, input *CreatePollInput) (*models.Poll, error) {
+	poll := &models.Poll{
+		UserID:   userID,
+		Question: strings.TrimSpace(input.Question),
+		Status:   models.PollStatusOpen,
+	}
+	for i, label := range input.Options {
+		label = strings.TrimSpace(label)
+		if label == "" {
+			return nil, errors.New("poll option cannot be empty")
+		}
+		poll.Options = append(poll.Options, models.PollOption{Label: label, SortOrder: i})
+	}
+
+	closed, err := s.pollRepo.CreateClosingOpen(poll)
+	if err != nil {
+		return nil, errors.New("failed to create poll")
+	}
+
+	// Overlays see the final results of the previous poll, then the new one
+	for i := range closed {
+		s.publish(log, &closed[i])
+	}
+	s.publish(log, poll)
+	return poll, nil
+}
+
+func (s *PollService) GetAll(userID uuid.UUID) ([]models.Poll, error) {
+	return s.pollRepo.FindByUserID(userID, 50)
+}
+
+// Close freezes the poll so later donations no longer change the results
+func (s *PollService) Close(log *utils.RequestLogger, userID, pollID uuid.UUID) (*models.Poll, error) {
+	poll, err := s.pollRepo.FindByID(pollID)
+	if err != nil || poll.UserID != userID {
+		return nil, errors.New("poll not found")
+	}
+
+	ok, err := s.pollRepo.Close(pollID)
+	if err != nil {
+		return nil, errors.New("failed to close poll")
+	}
+	if !ok {
+		return nil, errors.New("poll already closed")
+	}
+
+	poll, err = s.pollRepo.FindByID(pollID)
+	if err != nil {
+		return nil, errors.New("poll not found")
+	}
+
+	s.publish(log, poll)
+	return poll, nil
+}
+
+// GetOpen returns the creator's open poll
+func (s *PollService) GetOpen(userID uuid.UUID) (*models.Poll, error) {
+	poll, err := s.pollRepo.FindOpenByUserID(userID)
+	if err != nil {
+		return nil, errors.New("no open poll")
+	}
+	return poll, nil
+}
+
+// GetOpenByUsername returns the open poll shown on the donation page
+func (s *PollService) GetOpenByUsername(username string) (*models.Poll, error) {
+	user, err := s.userRepo.FindByUsername(username)
+	if err != nil {
+		return nil, errors.New("creator not found")
+	}
+	return s.GetOpen(user.ID)
+}
+
+// OnDonationPaid adds the donation amount to the chosen poll option
+func (s *PollService) OnDonationPaid(log *utils.RequestLogger, donation *models.Donation) {
+	if donation.PollOptionID == nil {
+		return
+	}
+
+	ok, err := s.pollRepo.AddVote(*donation.PollOptionID, donation.Amount)
+	if err != nil {
+		log.LogError("PollService", err, "Failed to add poll vote")
+		return
+	}
+	if !ok {
+		log.Info().Str("donation_id", donation.ID.String()).Msg("Poll closed before payment, vote ignored")
+		return
+	}
+
+	poll, err := s.pollRepo.FindOpenByUserID(donation.CreatorID)
+	if err != nil {
+		return
+	}
+	s.publish(log, poll)
+}
+
+func (s *PollService) publish(log *utils.RequestLogger, poll *models.Poll) {
+	s.hub.Broadcast(log, TopicPoll, poll.UserID, NewOverlayEvent(EventPoll, poll.ToResults()))
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// MockPollRepository keeps polls in memory and applies the same conditions
+// as the repository's queries
+type MockPollRepository struct {
+	polls map[uuid.UUID]*models.Poll
+}
+
+var _ pollStore = (*MockPollRepository)(nil)
+
+func NewMockPollRepository() *MockPollRepository {
+	return &MockPollRepository{polls: make(map[uuid.UUID]*models.Poll)}
+}
+
+func (m *MockPollRepository) CreateClosingOpen(poll *models.Poll) ([]models.Poll, error) {
+	var closed []models.Poll
+	for _, open := range m.polls {
+		if open.UserID == poll.UserID && m.close(open) {
+			closed = append(closed, *open)
+		}
+	}
+
+	poll.ID = uuid.New()
+	poll.CreatedAt = time.Now()
+	for i := range poll.Options {
+		poll.Options[i].ID = uuid.New()
+		poll.Options[i].PollID = poll.ID
+	}
+	m.polls[poll.ID] = poll
+	return closed, nil
+}
+
+func (m *MockPollRepository) FindByID(id uuid.UUID) (*models.Poll, error) {
+	if poll, ok := m.polls[id]; ok {
+		copied := *poll
+		return &copied, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MockPollRepository) FindByUserID(userID uuid.UUID, limit int) ([]models.Poll, error) {
+	var polls []models.Poll
+	for _, poll := range m.polls {
+		if poll.UserID == userID && len(polls) < limit {
+			polls = append(polls, *poll)
+		}
+	}
+	return polls, nil
+}
+
+func (m *MockPollRepository) FindOpenByUserID(userID uuid.UUID) (*models.Poll, error) {
+	for _, poll := range m.polls {
+		if poll.UserID == userID && poll.Status == models.PollStatusOpen {
+			copied := *poll
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MockPollRepository) FindOpenOption(optionID, userID uuid.UUID) (*models.PollOption, error) {
+	for _, poll := range m.polls {
+		if poll.UserID != userID || poll.Status != models.PollStatusOpen {
+			continue
+		}
+		for _, option := range poll.Options {
+			if option.ID == optionID {
+				return &option, nil
+			}
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MockPollRepository) Close(id uuid.UUID) (bool, error) {
+	poll, ok := m.polls[id]
+	return ok && m.close(poll), nil
+}
+
+// close closes an open poll, reporting whether it was open
+func (m *MockPollRepository) close(poll *models.Poll) bool {
+	if poll.Status != models.PollStatusOpen {
+		return false
+	}
+	now := time.Now()
+	poll.Status = models.PollStatusClosed
+	poll.ClosedAt = &now
+	return true
+}
+
+func (m *MockPollRepository) AddVote(optionID uuid.UUID, amount int64) (bool, error) {
+	for _, poll := range m.polls {
+		if poll.Status != models.PollStatusOpen {
+			continue
+		}
+		for i := range poll.Options {
+			if poll.Options[i].ID == optionID {
+				poll.Options[i].TotalAmount += amount
+				poll.Options[i].VoteCount++
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func TestCreateDonation_RejectsOptionOutsideOpenPoll(t *testing.T) {
+	mockPollRepo := NewMockPollRepository()
+	mockUserRepo := NewMockUserRepository()
+	polls := &PollService{pollRepo: mockPollRepo, userRepo: mockUserRepo, hub: NewStreamHub(NewSubscribers(StreamLimits{}))}
+	s := &DonationService{donationRepo: NewMockDonationRepository(), userRepo: mockUserRepo, pollRepo: mockPollRepo}
+	log := utils.NewRequestLogger("test")
+
+	username := "budi"
+	creator := &models.User{Email: "budi@example.com", Username: &username}
+	mockUserRepo.AddUser(creator)
+	other := &models.User{Email: "sari@example.com"}
+	mockUserRepo.AddUser(other)
+
+	input := &CreatePollInput{Question: "Main game apa?", Options: []string{"Minecraft", "Valorant"}}
+	closed, err := polls.Create(log, creator.ID, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Creating a new poll closes the previous one
+	if _, err := polls.Create(log, creator.ID, input); err != nil {
+		t.Fatal(err)
+	}
+	otherPoll, err := polls.Create(log, other.ID, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mockPollRepo.polls[closed.ID].Status != models.PollStatusClosed {
+		t.Fatalf("Expected the previous poll to be closed, got %q", mockPollRepo.polls[closed.ID].Status)
+	}
+
+	tests := []struct {
+		name     string
+		optionID uuid.UUID
+	}{
+		{"closed poll", closed.Options[0].ID},
+		{"another creator's poll", otherPoll.Options[0].ID},
+		{"unknown option", uuid.New()},
+	}
+	for _, tt := range tests {
+		_, err := s.CreateDonation(log, &CreateDonationInput{
+			CreatorUsername: username,
+			BuyerName:       "Sari",
+			BuyerEmail:      "sari@example.com",
+			Amount:          10000,
+			PollOptionID:    &tt.optionID,
+		}, nil)
+		if err == nil || err.Error() != "poll option not found or poll is closed" {
+			t.Errorf("%s: expected the option to be refused, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestPollService_CreateBroadcastsClosedPoll(t *testing.T) {
+	mockRepo := NewMockPollRepository()
+	hub := NewStreamHub(NewSubscribers(StreamLimits{}))
+	s := &PollService{pollRepo: mockRepo, userRepo: NewMockUserRepository(), hub: hub}
+	log := utils.NewRequestLogger("test")
+	creatorID := uuid.New()
+
+	first, err := s.Create(log, creatorID, &CreatePollInput{Question: "Main game apa?", Options: []string{"Minecraft", "Valorant"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	overlay := hub.Subscribe(TopicPoll, creatorID)
+	defer hub.Unsubscribe(overlay)
+
+	second, err := s.Create(log, creatorID, &CreatePollInput{Question: "Makan apa?", Options: []string{"Bakso", "Soto"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := nextEvent(overlay)
+	if event == nil || event.Type != EventPoll {
+		t.Fatalf("Expected a poll event, got %+v", event)
+	}
+	if results := event.Data.(models.PollResults); results.PollID != first.ID || results.Status != models.PollStatusClosed {
+		t.Errorf("Expected the closed results of the previous poll first, got %+v", results)
+	}
+	event = nextEvent(overlay)
+	if event == nil || event.Type != EventPoll {
+		t.Fatalf("Expected a poll event, got %+v", event)
+	}
+	if results := event.Data.(models.PollResults); results.PollID != second.ID || results.Status != models.PollStatusOpen {
+		t.Errorf("Expected the new poll next, got %+v", results)
+	}
+}
+
+func TestPollService_IgnoresVotesAfterClose(t *testing.T) {
+	mockRepo := NewMockPollRepository()
+	hub := NewStreamHub(NewSubscribers(StreamLimits{}))
+	s := &PollService{pollRepo: mockRepo, userRepo: NewMockUserRepository(), hub: hub}
+	log := utils.NewRequestLogger("test")
+	creatorID := uuid.New()
+
+	poll, err := s.Create(log, creatorID, &CreatePollInput{Question: "Main game apa?", Options: []string{"Minecraft", "Valorant"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	optionID := poll.Options[0].ID
+	overlay := hub.Subscribe(TopicPoll, creatorID)
+	defer hub.Unsubscribe(overlay)
+
+	s.OnDonationPaid(log, &models.Donation{CreatorID: creatorID, Amount: 20000, PollOptionID: &optionID})
+	if got := mockRepo.polls[poll.ID].Options[0]; got.TotalAmount != 20000 || got.VoteCount != 1 {
+		t.Fatalf("Expected one vote of 20000, got %d votes of %d", got.VoteCount, got.TotalAmount)
+	}
+	if event := nextEvent(overlay); event == nil || event.Type != EventPoll {
+		t.Fatalf("Expected a poll event, got %+v", event)
+	}
+
+	if _, err := s.Close(log, creatorID, poll.ID); err != nil {
+		t.Fatal(err)
+	}
+	nextEvent(overlay) // the closed results
+
+	// A donation started before the poll closed and paid after it
+	s.OnDonationPaid(log, &models.Donation{CreatorID: creatorID, Amount: 50000, PollOptionID: &optionID})
+	if got := mockRepo.polls[poll.ID].Options[0]; got.TotalAmount != 20000 || got.VoteCount != 1 {
+		t.Errorf("Expected the closed results to stay at one vote of 20000, got %d votes of %d", got.VoteCount, got.TotalAmount)
+	}
+	if event := nextEvent(overlay); event != nil {
+		t.Errorf("Expected no poll event after closing, got %+v", event)
+	}
+}
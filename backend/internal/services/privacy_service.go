@@ -0,0 +1,318 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/repository"
+	"github.com/jajanin/backend/internal/storage"
+	"github.com/jajanin/backend/internal/utils"
+	"gorm.io/datatypes"
+)
+
+var (
+	ErrDeleteStaffAccount  = errors.New("staff accounts can't be deleted, ask an admin to remove your role first")
+	ErrDeleteConfirmation  = errors.New("incorrect password")
+	ErrDeleteEmailMismatch = errors.New("type your email address to confirm")
+	ErrPendingWithdrawals  = errors.New("wait until your pending withdrawals are processed before deleting your account")
+	ErrOutstandingBalance  = errors.New("withdraw your balance before deleting your account")
+	ErrForfeitBalance      = errors.New("your balance is below the minimum withdrawal, confirm that you forfeit it to continue")
+)
+
+// PrivacyService exports a user's personal data and deletes accounts
+type PrivacyService struct {
+	repo       *repository.PrivacyRepository
+	userRepo   *repository.UserRepository
+	assetRepo  *repository.AssetRepository
+	withdrawal *WithdrawalService
+	store      storage.Storage
+	audit      *AuditService
+}
+
+func NewPrivacyService(
+	repo *repository.PrivacyRepository,
+	userRepo *repository.UserRepository,
+	assetRepo *repository.AssetRepository,
+	withdrawal *WithdrawalService,
+	store storage.Storage,
+	audit *AuditService,
+) *PrivacyService {
+	return &PrivacyService{
+		repo:       repo,
+		userRepo:   userRepo,
+		assetRepo:  assetRepo,
+		withdrawal: withdrawal,
+		store:      store,
+		audit:      audit,
+	}
+}
+
+// profileExport is profile.json. The user's bank and social details are
+// included, credentials and internal keys (such as the stream key that
+// authenticates overlays) are not.
+type profileExport struct {
+	ExportedAt time.Time          `json:"exported_at"`
+	User       exportedUserFields `json:"user"`
+}
+
+// exportedUserFields lists the user fields written to profile.json. Fields
+// are copied one by one so a new column on User isn't exported by accident.
+type exportedUserFields struct {
+	ID                   uuid.UUID      `json:"id"`
+	Email                string         `json:"email"`
+	Name                 string         `json:"name"`
+	Username             *string        `json:"username"`
+	ImageURL             string         `json:"image_url"`
+	Bio                  string         `json:"bio"`
+	Role                 string         `json:"role"`
+	BankName             string         `json:"bank_name,omitempty"`
+	BankAccount          string         `json:"bank_account,omitempty"`
+	BankHolder           string         `json:"bank_holder,omitempty"`
+	TwitterURL           string         `json:"twitter_url,omitempty"`
+	InstagramURL         string         `json:"instagram_url,omitempty"`
+	YoutubeURL           string         `json:"youtube_url,omitempty"`
+	WebsiteURL           string         `json:"website_url,omitempty"`
+	AlertSettings        datatypes.JSON `json:"alert_settings"`
+	AlertApprovalEnabled bool           `json:"alert_approval_enabled"`
+	EmailVerifiedAt      *time.Time     `json:"email_verified_at"`
+	CreatedAt            time.Time      `json:"created_at"`
+	UpdatedAt            time.Time      `json:"updated_at"`
+}
+
+func newProfileExport(user *models.User, now time.Time) *profileExport {
+	return &profileExport{
+		ExportedAt: now,
+		User: exportedUserFields{
+			ID:                   user.ID,
+			Email:                user.Email,
+			Name:                 user.Name,
+			Username:             user.Username,
+			ImageURL:             user.ImageURL,
+			Bio:                  user.Bio,
+			Role:                 user.Role,
+			BankName:             user.BankName,
+			BankAccount:          user.BankAccount,
+			BankHolder:           user.BankHolder,
+			TwitterURL:           user.TwitterURL,
+			InstagramURL:         user.InstagramURL,
+			YoutubeURL:           user.YoutubeURL,
+			WebsiteURL:           user.WebsiteURL,
+			AlertSettings:        user.AlertSettings,
+			AlertApprovalEnabled: user.AlertApprovalEnabled,
+			EmailVerifiedAt:      user.EmailVerifiedAt,
+			CreatedAt:            user.CreatedAt,
+			UpdatedAt:            user.UpdatedAt,
+		},
+	}
+}
+
+// Export writes a ZIP archive of everything stored about the user
+func (s *PrivacyService) Export(userID uuid.UUID, w io.Writer) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	settings, err := s.repo.FindSettings(userID)
+	if err != nil {
+		return errors.New("failed to load settings")
+	}
+	received, err := s.repo.FindDonationsReceived(userID)
+	if err != nil {
+		return errors.New("failed to load donations")
+	}
+	purchases, err := s.repo.FindPurchases(userID)
+	if err != nil {
+		return errors.New("failed to load purchases")
+	}
+	withdrawals, err := s.repo.FindWithdrawals(userID)
+	if err != nil {
+		return errors.New("failed to load withdrawals")
+	}
+
+	archive := zip.NewWriter(w)
+	files := []struct {
+		name  string
+		write func(io.Writer) error
+	}{
+		{"profile.json", jsonFile(newProfileExport(user, time.Now()))},
+		{"settings.json", jsonFile(settings)},
+		{"donations_received.csv", csvFile(donationsReceivedRows(received))},
+		{"purchases.csv", csvFile(purchaseRows(purchases))},
+		{"withdrawals.csv", csvFile(withdrawalRows(withdrawals))},
+	}
+	for _, file := range files {
+		f, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if err := file.write(f); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+func jsonFile(v interface{}) func(io.Writer) error {
+	return func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+}
+
+func csvFile(rows [][]string) func(io.Writer) error {
+	return func(w io.Writer) error {
+		cw := csv.NewWriter(w)
+		if err := cw.WriteAll(rows); err != nil {
+			return err
+		}
+		return cw.Error()
+	}
+}
+
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// csvCell keeps spreadsheet apps from running free text as a formula by
+// prefixing cells that start with a formula character with a quote
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func donationsReceivedRows(donations []models.Donation) [][]string {
+	rows := [][]string{{
+		"id", "created_at", "paid_at", "supporter_name", "supporter_email", "product", "quantity",
+		"amount", "message", "payment_method", "payment_status", "alert_status",
+	}}
+	for _, d := range donations {
+		rows = append(rows, []string{
+			d.ID.String(), formatExportTime(&d.CreatedAt), formatExportTime(d.PaidAt), csvCell(d.BuyerName), csvCell(d.BuyerEmail),
+			csvCell(d.ProductName), strconv.Itoa(d.Quantity), strconv.FormatInt(d.Amount, 10), csvCell(d.Message),
+			csvCell(d.PaymentMethod), string(d.PaymentStatus), string(d.AlertStatus),
+		})
+	}
+	return rows
+}
+
+func purchaseRows(donations []models.Donation) [][]string {
+	rows := [][]string{{
+		"id", "created_at", "paid_at", "creator", "name", "product", "quantity",
+		"amount", "message", "payment_method", "payment_status",
+	}}
+	for _, d := range donations {
+		creator := d.Creator.Name
+		if d.Creator.Username != nil {
+			creator = *d.Creator.Username
+		}
+		rows = append(rows, []string{
+			d.ID.String(), formatExportTime(&d.CreatedAt), formatExportTime(d.PaidAt), csvCell(creator), csvCell(d.BuyerName),
+			csvCell(d.ProductName), strconv.Itoa(d.Quantity), strconv.FormatInt(d.Amount, 10), csvCell(d.Message),
+			csvCell(d.PaymentMethod), string(d.PaymentStatus),
+		})
+	}
+	return rows
+}
+
+func withdrawalRows(withdrawals []models.Withdrawal) [][]string {
+	rows := [][]string{{
+		"id", "created_at", "processed_at", "amount", "status", "bank_name", "bank_account", "bank_holder", "notes",
+	}}
+	for _, w := range withdrawals {
+		rows = append(rows, []string{
+			w.ID.String(), formatExportTime(&w.CreatedAt), formatExportTime(w.ProcessedAt),
+			strconv.FormatInt(w.Amount, 10), string(w.Status), csvCell(w.BankName), csvCell(w.BankAccount), csvCell(w.BankHolder), csvCell(w.Notes),
+		})
+	}
+	return rows
+}
+
+// DeleteAccountInput confirms the deletion with the password, or with the
+// email address for accounts that only sign in with Google
+type DeleteAccountInput struct {
+	Password       string `json:"password"`
+	Email          string `json:"email"`
+	ForfeitBalance bool   `json:"forfeit_balance"` // give up a balance too small to withdraw
+}
+
+// balanceBlocksDeletion decides whether the balance lets the account go.
+// Money that can still be paid out must be withdrawn first; a remainder
+// below the minimum withdrawal can only be forfeited.
+func balanceBlocksDeletion(balance map[string]int64, forfeit bool) error {
+	if balance["pending_withdrawals"] > 0 {
+		return ErrPendingWithdrawals
+	}
+	available := balance["available_balance"]
+	if available >= MinWithdrawalAmount {
+		return ErrOutstandingBalance
+	}
+	if available > 0 && !forfeit {
+		return ErrForfeitBalance
+	}
+	return nil
+}
+
+// DeleteAccount erases the user's personal data and closes the account.
+// Donations and withdrawals are kept as financial records.
+func (s *PrivacyService) DeleteAccount(ctx context.Context, log *utils.RequestLogger, userID uuid.UUID, input *DeleteAccountInput) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if user.IsStaff() {
+		return ErrDeleteStaffAccount
+	}
+
+	if user.PasswordHash != "" {
+		if !utils.CheckPassword(input.Password, user.PasswordHash) {
+			return ErrDeleteConfirmation
+		}
+	} else if !strings.EqualFold(strings.TrimSpace(input.Email), user.Email) {
+		return ErrDeleteEmailMismatch
+	}
+
+	balance, err := s.withdrawal.GetBalance(userID)
+	if err != nil {
+		return errors.New("failed to get balance")
+	}
+	if err := balanceBlocksDeletion(balance, input.ForfeitBalance); err != nil {
+		return err
+	}
+
+	// Uploaded files live outside the database
+	assets, err := s.assetRepo.FindByUserID(userID)
+	if err != nil {
+		return errors.New("failed to load assets")
+	}
+	for _, asset := range assets {
+		if err := s.store.Delete(ctx, asset.StorageKey); err != nil {
+			return errors.New("failed to delete uploaded files")
+		}
+	}
+
+	if err := s.repo.EraseUser(userID); err != nil {
+		return errors.New("failed to delete account")
+	}
+
+	s.audit.Record(log, &models.AuditLog{
+		Action:   models.AuditAccountDeleted,
+		UserID:   &userID,
+		Metadata: map[string]interface{}{"forfeited_balance": balance["available_balance"]},
+	})
+	return nil
+}
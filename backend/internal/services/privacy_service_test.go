@@ -0,0 +1,121 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+)
+
+func TestBalanceBlocksDeletion(t *testing.T) {
+	tests := []struct {
+		name    string
+		balance map[string]int64
+		forfeit bool
+		want    error
+	}{
+		{"empty", map[string]int64{}, false, nil},
+		{"pending withdrawal", map[string]int64{"pending_withdrawals": 50000}, true, ErrPendingWithdrawals},
+		{"withdrawable", map[string]int64{"available_balance": MinWithdrawalAmount}, true, ErrOutstandingBalance},
+		{"small remainder", map[string]int64{"available_balance": 1000}, false, ErrForfeitBalance},
+		{"small remainder forfeited", map[string]int64{"available_balance": 1000}, true, nil},
+	}
+	for _, tt := range tests {
+		if got := balanceBlocksDeletion(tt.balance, tt.forfeit); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestExportRows(t *testing.T) {
+	paidAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	username := "budi"
+	donations := []models.Donation{{
+		ID:            uuid.New(),
+		BuyerName:     "Sari",
+		BuyerEmail:    "sari@example.com",
+		Amount:        25000,
+		Quantity:      5,
+		Message:       "semangat, bang",
+		PaidAt:        &paidAt,
+		PaymentStatus: models.PaymentStatusPaid,
+		Creator:       models.User{Name: "Budi", Username: &username},
+	}}
+
+	received := donationsReceivedRows(donations)
+	if len(received) != 2 || len(received[1]) != len(received[0]) {
+		t.Fatalf("Expected a header and one row of equal width, got %v", received)
+	}
+	if received[1][2] != "2026-01-02T03:04:05Z" || received[1][4] != "sari@example.com" || received[1][7] != "25000" {
+		t.Errorf("Unexpected row %v", received[1])
+	}
+
+	purchases := purchaseRows(donations)
+	if purchases[1][3] != "budi" {
+		t.Errorf("Expected the creator's username, got %q", purchases[1][3])
+	}
+
+	withdrawals := withdrawalRows([]models.Withdrawal{{Amount: 50000, Status: models.WithdrawalStatusPending}})
+	if withdrawals[1][2] != "" || withdrawals[1][3] != "50000" {
+		t.Errorf("Unexpected row %v", withdrawals[1])
+	}
+}
+
+func TestExportRows_EscapesFormulas(t *testing.T) {
+	donations := []models.Donation{{
+		BuyerName:     "=HYPERLINK(\"http://evil\")",
+		Message:       "+1 semangat",
+		ProductName:   "Kopi",
+		PaymentMethod: "@qris",
+		Creator:       models.User{Name: "-Budi"},
+	}}
+
+	received := donationsReceivedRows(donations)
+	if received[1][3] != "'=HYPERLINK(\"http://evil\")" || received[1][8] != "'+1 semangat" || received[1][9] != "'@qris" {
+		t.Errorf("Expected formula cells to be quoted, got %v", received[1])
+	}
+	if received[1][5] != "Kopi" {
+		t.Errorf("Expected plain text untouched, got %q", received[1][5])
+	}
+
+	purchases := purchaseRows(donations)
+	if purchases[1][3] != "'-Budi" || purchases[1][4] != "'=HYPERLINK(\"http://evil\")" {
+		t.Errorf("Expected formula cells to be quoted, got %v", purchases[1])
+	}
+
+	for _, value := range []string{"\tx", "\rx"} {
+		if got := csvCell(value); got != "'"+value {
+			t.Errorf("Expected %q to be quoted, got %q", value, got)
+		}
+	}
+}
+
+func TestProfileExport_LeavesOutCredentials(t *testing.T) {
+	googleID := "1234567890"
+	user := &models.User{
+		ID:                uuid.New(),
+		Email:             "budi@example.com",
+		Name:              "Budi",
+		PasswordHash:      "$2a$10$hash",
+		GoogleID:          &googleID,
+		BankAccount:       "1234567890",
+		StreamKey:         "sk_live_secret",
+		PreviousStreamKey: "sk_live_old",
+	}
+
+	data, err := json.Marshal(newProfileExport(user, time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"sk_live_secret", "sk_live_old", "$2a$10$hash", "stream_key"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("Expected profile.json not to contain %q", secret)
+		}
+	}
+	if !strings.Contains(string(data), `"bank_account":"1234567890"`) {
+		t.Errorf("Expected the bank details to be exported, got %s", data)
+	}
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"errors"
+	"image/color"
+	"net/url"
+	"strings"
+
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/qrcode"
+	"github.com/jajanin/backend/internal/repository"
+)
+
+// QR image size limits in pixels
+const (
+	DefaultQRSize = 512
+	MinQRSize     = 128
+	MaxQRSize     = 2048
+)
+
+// QRBrandColor is the Jajanin orange used for branded QR codes
+var QRBrandColor = color.RGBA{0xFE, 0x62, 0x44, 0xFF}
+
+var ErrQRNotAvailable = errors.New("QR code not available")
+
+type QRService struct {
+	donationRepo *repository.DonationRepository
+	userRepo     *repository.UserRepository
+	frontendURL  string
+}
+
+func NewQRService(donationRepo *repository.DonationRepository, userRepo *repository.UserRepository, frontendURL string) *QRService {
+	return &QRService{
+		donationRepo: donationRepo,
+		userRepo:     userRepo,
+		frontendURL:  strings.TrimRight(frontendURL, "/"),
+	}
+}
+
+// PaymentQR encodes the QRIS payload of a pending donation. Paid, expired
+// and e-wallet donations have nothing to scan.
+func (s *QRService) PaymentQR(orderID string) (*qrcode.Code, error) {
+	donation, err := s.donationRepo.FindByPaymentID(orderID)
+	if err != nil {
+		return nil, errors.New("donation not found")
+	}
+	if donation.PaymentStatus != models.PaymentStatusPending || donation.QRISPayload == "" {
+		return nil, ErrQRNotAvailable
+	}
+	return qrcode.Encode([]byte(donation.QRISPayload), qrcode.Medium)
+}
+
+// ProfileURL is the creator's public donation page
+func (s *QRService) ProfileURL(user *models.User) string {
+	username := ""
+	if user.Username != nil {
+		username = *user.Username
+	}
+	return s.frontendURL + "/" + url.PathEscape(username)
+}
+
+// ProfileQR encodes the donation page URL of a creator. High error
+// correction leaves room for the badge drawn over the centre.
+func (s *QRService) ProfileQR(username string) (*qrcode.Code, error) {
+	user, err := s.userRepo.FindByUsername(username)
+	if err != nil {
+		return nil, errors.New("creator not found")
+	}
+	return s.ProfileQRForUser(user)
+}
+
+func (s *QRService) ProfileQRForUser(user *models.User) (*qrcode.Code, error) {
+	if user.Username == nil || *user.Username == "" {
+		return nil, ErrQRNotAvailable
+	}
+	return qrcode.Encode([]byte(s.ProfileURL(user)), qrcode.High)
+}
+
+// BrandedQROptions renders donation page QR codes in the brand color with a badge
+func BrandedQROptions(size int) qrcode.RenderOptions {
+	opts := qrcode.DefaultRenderOptions()
+	opts.Size = size
+	opts.Foreground = QRBrandColor
+	opts.Badge = true
+	return opts
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/repository"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used, session has been revoked")
+	ErrSessionNotFound     = errors.New("session not found")
+)
+
+// SessionMeta describes the device a session was started or refreshed from
+type SessionMeta struct {
+	UserAgent string
+	IPAddress string
+	// TwoFactorVerified is set when the login passed a two-factor check,
+	// which then also counts as a step-up for the new session
+	TwoFactorVerified bool
+}
+
+// TokenPair is what a client keeps: a short-lived access token and the
+// refresh token that renews it. The refresh token changes on every refresh.
+type TokenPair struct {
+	Token        string    `json:"token"`
+	ExpiresAt    time.Time `json:"expires_at"` // of the access token
+	RefreshToken string    `json:"refresh_token"`
+}
+
+type SessionService struct {
+	repo     *repository.SessionRepository
+	userRepo *repository.UserRepository
+	ttl      time.Duration
+}
+
+func NewSessionService(repo *repository.SessionRepository, userRepo *repository.UserRepository, ttl time.Duration) *SessionService {
+	return &SessionService{repo: repo, userRepo: userRepo, ttl: ttl}
+}
+
+func hashRefreshSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateRefreshSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Refresh tokens are "<session id>.<secret>" so a replayed old token still
+// points at its session, which is then revoked
+func parseRefreshToken(token string) (uuid.UUID, string, bool) {
+	id, secret, found := strings.Cut(token, ".")
+	if !found || secret == "" {
+		return uuid.Nil, "", false
+	}
+	sessionID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, "", false
+	}
+	return sessionID, secret, true
+}
+
+func (s *SessionService) issue(user *models.User, session *models.Session, secret string) (*TokenPair, error) {
+	token, expiresAt, err := utils.GenerateToken(user.ID, session.ID, user.Email, derefString(user.Username))
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+	return &TokenPair{
+		Token:        token,
+		ExpiresAt:    expiresAt,
+		RefreshToken: session.ID.String() + "." + secret,
+	}, nil
+}
+
+// Start signs the user in on a new device
+func (s *SessionService) Start(user *models.User, meta SessionMeta) (*TokenPair, error) {
+	secret, err := generateRefreshSecret()
+	if err != nil {
+		return nil, errors.New("failed to generate refresh token")
+	}
+
+	now := time.Now()
+	session := &models.Session{
+		UserID:           user.ID,
+		RefreshTokenHash: hashRefreshSecret(secret),
+		UserAgent:        meta.UserAgent,
+		DeviceName:       models.DeviceNameFromUserAgent(meta.UserAgent),
+		IPAddress:        meta.IPAddress,
+		LastUsedAt:       now,
+		ExpiresAt:        now.Add(s.ttl),
+	}
+	if meta.TwoFactorVerified {
+		session.StepUpAt = &now
+	}
+	if err := s.repo.Create(session); err != nil {
+		return nil, errors.New("failed to create session")
+	}
+
+	return s.issue(user, session, secret)
+}
+
+// Refresh exchanges a refresh token for a new token pair. Presenting a
+// refresh token that was already rotated means it leaked, so the whole
+// session is revoked.
+func (s *SessionService) Refresh(log *utils.RequestLogger, refreshToken string, meta SessionMeta) (*TokenPair, error) {
+	sessionID, secret, ok := parseRefreshToken(refreshToken)
+	if !ok {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	session, err := s.repo.FindByID(sessionID)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	now := time.Now()
+	if !session.IsActive(now) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	oldHash := hashRefreshSecret(secret)
+	if subtle.ConstantTimeCompare([]byte(oldHash), []byte(session.RefreshTokenHash)) != 1 {
+		s.revokeReused(log, session)
+		return nil, ErrRefreshTokenReused
+	}
+
+	user, err := s.userRepo.FindByID(session.UserID)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	newSecret, err := generateRefreshSecret()
+	if err != nil {
+		return nil, errors.New("failed to generate refresh token")
+	}
+	session.RefreshTokenHash = hashRefreshSecret(newSecret)
+	session.UserAgent = meta.UserAgent
+	session.DeviceName = models.DeviceNameFromUserAgent(meta.UserAgent)
+	session.IPAddress = meta.IPAddress
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(s.ttl)
+
+	rotated, err := s.repo.Rotate(session, oldHash)
+	if err != nil {
+		return nil, errors.New("failed to refresh session")
+	}
+	if !rotated {
+		// Another request rotated this token first
+		s.revokeReused(log, session)
+		return nil, ErrRefreshTokenReused
+	}
+
+	return s.issue(user, session, newSecret)
+}
+
+func (s *SessionService) revokeReused(log *utils.RequestLogger, session *models.Session) {
+	log.LogWarn("SessionService.Refresh", "Refresh token reuse detected, revoking session "+session.ID.String())
+	if _, err := s.repo.Revoke(session.ID, session.UserID, models.SessionRevokedReuse); err != nil {
+		log.LogError("SessionService.Refresh", err, "Failed to revoke session after refresh token reuse")
+	}
+}
+
+// Revoke ends one of the user's sessions
+func (s *SessionService) Revoke(userID, sessionID uuid.UUID, reason string) error {
+	revoked, err := s.repo.Revoke(sessionID, userID, reason)
+	if err != nil {
+		return errors.New("failed to revoke session")
+	}
+	if !revoked {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeAll ends every session of the user and returns how many were active
+func (s *SessionService) RevokeAll(userID uuid.UUID, reason string) (int64, error) {
+	count, err := s.repo.RevokeAllByUserID(userID, reason)
+	if err != nil {
+		return 0, errors.New("failed to revoke sessions")
+	}
+	return count, nil
+}
+
+// RevokeOthers ends every session of the user except the current one
+func (s *SessionService) RevokeOthers(userID, currentID uuid.UUID, reason string) (int64, error) {
+	count, err := s.repo.RevokeOthersByUserID(userID, currentID, reason)
+	if err != nil {
+		return 0, errors.New("failed to revoke sessions")
+	}
+	return count, nil
+}
+
+func (s *SessionService) List(userID uuid.UUID) ([]models.Session, error) {
+	return s.repo.FindActiveByUserID(userID)
+}
+
+// IsActive reports whether an access token's session may still be used
+func (s *SessionService) IsActive(sessionID, userID uuid.UUID) bool {
+	session, err := s.repo.FindByID(sessionID)
+	if err != nil {
+		return false
+	}
+	return session.UserID == userID && session.IsActive(time.Now())
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestParseRefreshToken(t *testing.T) {
+	id := uuid.New()
+
+	sessionID, secret, ok := parseRefreshToken(id.String() + ".s3cret")
+	if !ok || sessionID != id || secret != "s3cret" {
+		t.Errorf("Expected (%s, s3cret, true), got (%s, %s, %v)", id, sessionID, secret, ok)
+	}
+
+	for _, token := range []string{"", "s3cret", id.String(), id.String() + ".", "not-a-uuid.s3cret"} {
+		if _, _, ok := parseRefreshToken(token); ok {
+			t.Errorf("Expected %q to be rejected", token)
+		}
+	}
+}
+
+func TestHashRefreshSecret(t *testing.T) {
+	a, _ := generateRefreshSecret()
+	b, _ := generateRefreshSecret()
+	if a == b {
+		t.Fatal("Expected unique secrets")
+	}
+	if hashRefreshSecret(a) != hashRefreshSecret(a) || hashRefreshSecret(a) == hashRefreshSecret(b) {
+		t.Error("Expected a stable, distinct hash per secret")
+	}
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+// Overlay widget topics served by StreamHub
+const (
+	TopicGoal = "goal"
+)
+
+// StreamHub fans out widget events (goal, leaderboard, ...) to SSE clients.
+// Clients are keyed by topic and creator ID, so one creator can run several
+// widgets side by side.
+type StreamHub struct {
+	subs *Subscribers
+}
+
+func NewStreamHub(subs *Subscribers) *StreamHub {
+	return &StreamHub{subs: subs}
+}
+
+func hubKey(topic string, userID uuid.UUID) string {
+	return topic + ":" + userID.String()
+}
+
+func (h *StreamHub) Subscribe(topic string, userID uuid.UUID) *Subscriber {
+	return h.subs.Subscribe(userID, hubKey(topic, userID))
+}
+
+func (h *StreamHub) Unsubscribe(sub *Subscriber) {
+	h.subs.Unsubscribe(sub)
+}
+
+func (h *StreamHub) Broadcast(log *utils.RequestLogger, topic string, userID uuid.UUID, event *OverlayEvent) {
+	clients, delivered := h.subs.Publish(hubKey(topic, userID), event)
+	if clients == 0 {
+		return
+	}
+
+	log.Info().Str("topic", topic).Str("user_id", userID.String()).Str("event", event.Type).
+		Int("clients", clients).Int("delivered", delivered).Msg("Stream event broadcast")
+}
+
+// HasClients returns true if any widget is listening on the topic
+func (h *StreamHub) HasClients(topic string, userID uuid.UUID) bool {
+	return h.subs.Count(hubKey(topic, userID)) > 0
+}
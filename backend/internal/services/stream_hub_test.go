@@ -0,0 +1,54 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+func TestStreamHub_BroadcastByTopic(t *testing.T) {
+	hub := NewStreamHub(NewSubscribers(StreamLimits{}))
+	log := utils.NewRequestLogger("test")
+	userID := uuid.New()
+
+	goalSub := hub.Subscribe(TopicGoal, userID)
+	otherSub := hub.Subscribe("other", userID)
+
+	hub.Broadcast(log, TopicGoal, userID, NewOverlayEvent(EventGoalProgress, nil))
+
+	select {
+	case event := <-goalSub.Events:
+		if event.Type != EventGoalProgress {
+			t.Errorf("Expected goal_progress, got %s", event.Type)
+		}
+	default:
+		t.Fatal("Expected event on goal channel")
+	}
+
+	select {
+	case <-otherSub.Events:
+		t.Error("Expected no event on other topic")
+	default:
+	}
+}
+
+func TestStreamHub_Unsubscribe(t *testing.T) {
+	hub := NewStreamHub(NewSubscribers(StreamLimits{}))
+	userID := uuid.New()
+
+	sub := hub.Subscribe(TopicGoal, userID)
+	if !hub.HasClients(TopicGoal, userID) {
+		t.Fatal("Expected registered client")
+	}
+
+	hub.Unsubscribe(sub)
+	if hub.HasClients(TopicGoal, userID) {
+		t.Error("Expected no clients after unsubscribe")
+	}
+	select {
+	case <-sub.Done():
+	default:
+		t.Error("Expected subscriber to be done")
+	}
+}
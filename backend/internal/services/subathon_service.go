@@ -0,0 +1,167 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/repository"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+const TopicTimer = "timer"
+
+type SubathonService struct {
+	repo *repository.SubathonRepository
+	hub  *StreamHub
+}
+
+func NewSubathonService(repo *repository.SubathonRepository, hub *StreamHub) *SubathonService {
+	return &SubathonService{repo: repo, hub: hub}
+}
+
+type UpdateSubathonRulesInput struct {
+	SecondsPerUnit *int64                        `json:"seconds_per_unit" binding:"omitempty,min=0,max=3600"`
+	UnitAmount     *int64                        `json:"unit_amount" binding:"omitempty,min=1000"`
+	MaxSeconds     *int64                        `json:"max_seconds" binding:"omitempty,min=0"`
+	ProductBonuses []models.SubathonProductBonus `json:"product_bonuses" binding:"omitempty,max=50"`
+}
+
+type StartSubathonInput struct {
+	DurationSeconds int64 `json:"duration_seconds" binding:"required,min=60,max=604800"`
+}
+
+// Get returns the creator's timer, ending it first if time ran out
+func (s *SubathonService) Get(userID uuid.UUID) (*models.SubathonTimer, error) {
+	timer, err := s.repo.FindOrCreate(userID)
+	if err != nil {
+		return nil, errors.New("failed to get subathon timer")
+	}
+
+	if timer.Status == models.SubathonStatusRunning && timer.Remaining(time.Now()) == 0 {
+		if err := s.repo.MarkExpired(userID); err != nil {
+			return nil, errors.New("failed to update subathon timer")
+		}
+		return s.repo.FindByUserID(userID)
+	}
+
+	return timer, nil
+}
+
+func (s *SubathonService) UpdateRules(log *utils.RequestLogger, userID uuid.UUID, input *UpdateSubathonRulesInput) (*models.SubathonTimer, error) {
+	timer, err := s.repo.FindOrCreate(userID)
+	if err != nil {
+		return nil, errors.New("failed to get subathon timer")
+	}
+
+	if input.SecondsPerUnit != nil {
+		timer.SecondsPerUnit = *input.SecondsPerUnit
+	}
+	if input.UnitAmount != nil {
+		timer.UnitAmount = *input.UnitAmount
+	}
+	if input.MaxSeconds != nil {
+		timer.MaxSeconds = *input.MaxSeconds
+	}
+	if input.ProductBonuses != nil {
+		for _, bonus := range input.ProductBonuses {
+			if bonus.ProductID == uuid.Nil || bonus.Seconds < 0 {
+				return nil, errors.New("invalid product bonus")
+			}
+		}
+		timer.ProductBonuses = input.ProductBonuses
+	}
+
+	if err := s.repo.UpdateRules(timer); err != nil {
+		return nil, errors.New("failed to update subathon rules")
+	}
+
+	return timer, nil
+}
+
+func (s *SubathonService) Start(log *utils.RequestLogger, userID uuid.UUID, input *StartSubathonInput) (*models.SubathonTimer, error) {
+	if _, err := s.Get(userID); err != nil {
+		return nil, err
+	}
+	return s.transition(log, userID, "subathon already running", func() (bool, error) {
+		return s.repo.Start(userID, input.DurationSeconds)
+	})
+}
+
+func (s *SubathonService) Pause(log *utils.RequestLogger, userID uuid.UUID) (*models.SubathonTimer, error) {
+	return s.transition(log, userID, "subathon is not running", func() (bool, error) {
+		return s.repo.Pause(userID)
+	})
+}
+
+func (s *SubathonService) Resume(log *utils.RequestLogger, userID uuid.UUID) (*models.SubathonTimer, error) {
+	return s.transition(log, userID, "subathon is not paused", func() (bool, error) {
+		return s.repo.Resume(userID)
+	})
+}
+
+func (s *SubathonService) End(log *utils.RequestLogger, userID uuid.UUID) (*models.SubathonTimer, error) {
+	return s.transition(log, userID, "subathon is not running", func() (bool, error) {
+		return s.repo.End(userID)
+	})
+}
+
+// OnDonationPaid extends the creator's running or paused timer
+func (s *SubathonService) OnDonationPaid(log *utils.RequestLogger, donation *models.Donation) {
+	timer, err := s.repo.FindByUserID(donation.CreatorID)
+	if err != nil {
+		return // creator never set up a subathon
+	}
+	if timer.Status != models.SubathonStatusRunning && timer.Status != models.SubathonStatusPaused {
+		return
+	}
+
+	seconds := timer.SecondsFor(donation)
+	if seconds <= 0 {
+		return
+	}
+
+	added, extended, err := s.repo.Extend(donation.CreatorID, seconds, timer.MaxSeconds)
+	if err != nil {
+		log.LogError("SubathonService", err, "Failed to extend subathon timer")
+		return
+	}
+	if !extended {
+		return
+	}
+
+	log.Info().Str("user_id", donation.CreatorID.String()).Int64("seconds", added).Msg("Subathon timer extended")
+
+	timer, err = s.repo.FindByUserID(donation.CreatorID)
+	if err != nil {
+		return
+	}
+	state := timer.ToState(time.Now())
+	state.AddedSeconds = added // under MaxSeconds this can be less than the donation earned
+	s.hub.Broadcast(log, TopicTimer, donation.CreatorID, NewOverlayEvent(EventTimer, state))
+}
+
+// transition runs a conditional state change and broadcasts the new state
+func (s *SubathonService) transition(log *utils.RequestLogger, userID uuid.UUID, invalidMsg string, fn func() (bool, error)) (*models.SubathonTimer, error) {
+	if _, err := s.repo.FindOrCreate(userID); err != nil {
+		return nil, errors.New("failed to get subathon timer")
+	}
+
+	ok, err := fn()
+	if err != nil {
+		log.LogError("SubathonService", err, "Failed to update subathon timer")
+		return nil, errors.New("failed to update subathon timer")
+	}
+	if !ok {
+		return nil, errors.New(invalidMsg)
+	}
+
+	timer, err := s.repo.FindByUserID(userID)
+	if err != nil {
+		return nil, errors.New("failed to get subathon timer")
+	}
+
+	s.hub.Broadcast(log, TopicTimer, userID, NewOverlayEvent(EventTimer, timer.ToState(time.Now())))
+	return timer, nil
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+)
+
+func TestSubathonSecondsFor_Proportional(t *testing.T) {
+	timer := &models.SubathonTimer{SecondsPerUnit: 60, UnitAmount: 10000}
+
+	tests := []struct {
+		amount   int64
+		expected int64
+	}{
+		{10000, 60},
+		{15000, 90},
+		{5000, 30},
+		{1000, 6},
+	}
+
+	for _, tt := range tests {
+		got := timer.SecondsFor(&models.Donation{Amount: tt.amount})
+		if got != tt.expected {
+			t.Errorf("Amount %d: expected %d seconds, got %d", tt.amount, tt.expected, got)
+		}
+	}
+}
+
+func TestSubathonSecondsFor_ProductBonus(t *testing.T) {
+	productID := uuid.New()
+	timer := &models.SubathonTimer{
+		SecondsPerUnit: 60,
+		UnitAmount:     10000,
+		ProductBonuses: []models.SubathonProductBonus{
+			{ProductID: productID, Seconds: 30},
+		},
+	}
+
+	donation := &models.Donation{Amount: 20000, ProductID: &productID, Quantity: 2}
+	if got := timer.SecondsFor(donation); got != 120+60 {
+		t.Errorf("Expected 180 seconds, got %d", got)
+	}
+
+	otherProduct := uuid.New()
+	donation.ProductID = &otherProduct
+	if got := timer.SecondsFor(donation); got != 120 {
+		t.Errorf("Expected 120 seconds without bonus, got %d", got)
+	}
+}
+
+func TestSubathonRemaining(t *testing.T) {
+	now := time.Now()
+	endsAt := now.Add(90 * time.Second)
+
+	running := &models.SubathonTimer{Status: models.SubathonStatusRunning, EndsAt: &endsAt}
+	if got := running.Remaining(now); got != 90 {
+		t.Errorf("Expected 90 seconds remaining, got %d", got)
+	}
+
+	expired := &models.SubathonTimer{Status: models.SubathonStatusRunning, EndsAt: &now}
+	if got := expired.Remaining(now.Add(time.Second)); got != 0 {
+		t.Errorf("Expected expired timer to have 0 remaining, got %d", got)
+	}
+
+	paused := &models.SubathonTimer{Status: models.SubathonStatusPaused, RemainingSeconds: 300}
+	if got := paused.Remaining(now.Add(time.Hour)); got != 300 {
+		t.Errorf("Expected paused timer to keep 300 seconds, got %d", got)
+	}
+}
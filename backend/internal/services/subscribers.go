@@ -0,0 +1,253 @@
+package services
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// A creator's counters are kept this long after their last connection
+	// closes, then forgotten so the registry doesn't grow with every creator
+	creatorStatsTTL = 24 * time.Hour
+	// How often Subscribe looks for counters to forget
+	creatorStatsPruneInterval = 10 * time.Minute
+)
+
+// StreamLimits bounds the SSE connections held by the server. Zero means unlimited.
+type StreamLimits struct {
+	MaxPerCreator int // across all of a creator's overlays and widgets
+	MaxTotal      int // across all creators
+	Buffer        int // events queued per connection before new ones are dropped
+}
+
+// StreamStats are connection and backpressure counters
+type StreamStats struct {
+	Connections   int   `json:"connections"`
+	Evicted       int64 `json:"evicted"`        // connections closed to make room for newer ones
+	DroppedEvents int64 `json:"dropped_events"` // events not delivered because a connection's buffer was full
+}
+
+// Subscriber is one SSE connection. Events is never closed; Done is closed
+// when the connection is evicted, and the stream handler must then return.
+type Subscriber struct {
+	Events chan *OverlayEvent
+
+	done      chan struct{}
+	closeOnce sync.Once
+	creatorID uuid.UUID
+	key       string
+	elem      *list.Element // position in Subscribers.order
+	dropped   atomic.Int64
+}
+
+func (s *Subscriber) Done() <-chan struct{} {
+	return s.done
+}
+
+// Dropped is the number of events this connection missed
+func (s *Subscriber) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+func (s *Subscriber) close() {
+	s.closeOnce.Do(func() { close(s.done) })
+}
+
+type creatorCounters struct {
+	connections int
+	evicted     int64
+	dropped     atomic.Int64
+	idleSince   time.Time // when the last connection closed; zero while connected
+}
+
+// Subscribers is the registry behind AlertService and StreamHub. Publishing
+// only holds the lock long enough to read the subscriber list, and sends
+// never block: a connection that can't keep up loses events (counted)
+// instead of stalling everyone else.
+type Subscribers struct {
+	limits StreamLimits
+
+	mu       sync.RWMutex
+	byKey    map[string][]*Subscriber
+	order    *list.List // all subscribers, oldest connection first
+	creators map[uuid.UUID]*creatorCounters
+	prunedAt time.Time
+	evicted  int64
+	dropped  atomic.Int64
+}
+
+func NewSubscribers(limits StreamLimits) *Subscribers {
+	if limits.Buffer <= 0 {
+		limits.Buffer = 16
+	}
+	return &Subscribers{
+		limits:   limits,
+		byKey:    make(map[string][]*Subscriber),
+		order:    list.New(),
+		creators: make(map[uuid.UUID]*creatorCounters),
+	}
+}
+
+// Subscribe registers a connection for key, evicting the creator's oldest
+// connection (or the oldest connection overall) when a limit is reached
+func (s *Subscribers) Subscribe(creatorID uuid.UUID, key string) *Subscriber {
+	sub := &Subscriber{
+		Events:    make(chan *OverlayEvent, s.limits.Buffer),
+		done:      make(chan struct{}),
+		creatorID: creatorID,
+		key:       key,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneIdle(time.Now())
+	counters := s.creators[creatorID]
+	if counters == nil {
+		counters = &creatorCounters{}
+		s.creators[creatorID] = counters
+	}
+
+	if s.limits.MaxPerCreator > 0 && counters.connections >= s.limits.MaxPerCreator {
+		for e := s.order.Front(); e != nil; e = e.Next() {
+			if oldest := e.Value.(*Subscriber); oldest.creatorID == creatorID {
+				s.evict(oldest)
+				break
+			}
+		}
+	}
+	if s.limits.MaxTotal > 0 && s.order.Len() >= s.limits.MaxTotal {
+		s.evict(s.order.Front().Value.(*Subscriber))
+	}
+
+	sub.elem = s.order.PushBack(sub)
+	s.byKey[key] = append(s.byKey[key], sub)
+	counters.connections++
+	counters.idleSince = time.Time{}
+	return sub
+}
+
+// pruneIdle forgets the counters of creators without connections since
+// creatorStatsTTL, at most once per creatorStatsPruneInterval. Caller holds
+// the lock.
+func (s *Subscribers) pruneIdle(now time.Time) {
+	if now.Sub(s.prunedAt) < creatorStatsPruneInterval {
+		return
+	}
+	s.prunedAt = now
+	for creatorID, counters := range s.creators {
+		if counters.connections == 0 && now.Sub(counters.idleSince) >= creatorStatsTTL {
+			delete(s.creators, creatorID)
+		}
+	}
+}
+
+// evict removes sub and tells its handler to disconnect. Caller holds the lock.
+func (s *Subscribers) evict(sub *Subscriber) {
+	// Count first so remove keeps the creator's counters
+	s.creators[sub.creatorID].evicted++
+	s.evicted++
+	s.remove(sub)
+	sub.close()
+}
+
+// remove deletes sub from the registry. Caller holds the lock.
+func (s *Subscribers) remove(sub *Subscriber) bool {
+	subs := s.byKey[sub.key]
+	for i, c := range subs {
+		if c != sub {
+			continue
+		}
+		// Copy instead of reslicing in place: Publish may still be reading the old slice
+		rest := make([]*Subscriber, 0, len(subs)-1)
+		rest = append(append(rest, subs[:i]...), subs[i+1:]...)
+		if len(rest) == 0 {
+			delete(s.byKey, sub.key)
+		} else {
+			s.byKey[sub.key] = rest
+		}
+
+		s.order.Remove(sub.elem)
+		counters := s.creators[sub.creatorID]
+		counters.connections--
+		if counters.connections == 0 {
+			if counters.evicted == 0 && counters.dropped.Load() == 0 {
+				delete(s.creators, sub.creatorID)
+			} else {
+				counters.idleSince = time.Now()
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Unsubscribe removes the connection; safe to call after it was evicted
+func (s *Subscribers) Unsubscribe(sub *Subscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(sub)
+	sub.close()
+}
+
+// Publish sends the event to every connection for key without blocking and
+// returns how many connections there were and how many received it
+func (s *Subscribers) Publish(key string, event *OverlayEvent) (clients, delivered int) {
+	s.mu.RLock()
+	subs := s.byKey[key] // never mutated in place, safe to use after unlocking
+	s.mu.RUnlock()
+
+	for _, sub := range subs {
+		select {
+		case sub.Events <- event:
+			delivered++
+		default:
+			sub.dropped.Add(1)
+			s.dropped.Add(1)
+			s.mu.RLock()
+			if counters := s.creators[sub.creatorID]; counters != nil {
+				counters.dropped.Add(1)
+			}
+			s.mu.RUnlock()
+		}
+	}
+	return len(subs), delivered
+}
+
+// Count returns the number of connections for key
+func (s *Subscribers) Count(key string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.byKey[key])
+}
+
+// Stats returns the server-wide counters
+func (s *Subscribers) Stats() StreamStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return StreamStats{
+		Connections:   s.order.Len(),
+		Evicted:       s.evicted,
+		DroppedEvents: s.dropped.Load(),
+	}
+}
+
+// CreatorStats returns the counters of one creator's connections
+func (s *Subscribers) CreatorStats(creatorID uuid.UUID) StreamStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counters := s.creators[creatorID]
+	if counters == nil {
+		return StreamStats{}
+	}
+	return StreamStats{
+		Connections:   counters.connections,
+		Evicted:       counters.evicted,
+		DroppedEvents: counters.dropped.Load(),
+	}
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func isDone(sub *Subscriber) bool {
+	select {
+	case <-sub.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+func TestSubscribers_EvictsOldestPerCreator(t *testing.T) {
+	subs := NewSubscribers(StreamLimits{MaxPerCreator: 2})
+	creator, other := uuid.New(), uuid.New()
+
+	first := subs.Subscribe(creator, "alert")
+	otherSub := subs.Subscribe(other, "alert:other")
+	second := subs.Subscribe(creator, "goal")
+	third := subs.Subscribe(creator, "alert")
+
+	if !isDone(first) {
+		t.Error("Expected the creator's oldest connection to be evicted")
+	}
+	if isDone(second) || isDone(third) || isDone(otherSub) {
+		t.Error("Expected the other connections to stay open")
+	}
+
+	stats := subs.CreatorStats(creator)
+	if stats.Connections != 2 || stats.Evicted != 1 {
+		t.Errorf("Expected 2 connections and 1 eviction, got %+v", stats)
+	}
+	if subs.Count("alert") != 1 {
+		t.Errorf("Expected 1 alert connection, got %d", subs.Count("alert"))
+	}
+
+	// Unsubscribing an evicted connection is a no-op
+	subs.Unsubscribe(first)
+	if got := subs.Stats().Connections; got != 3 {
+		t.Errorf("Expected 3 connections, got %d", got)
+	}
+}
+
+func TestSubscribers_EvictsOldestGlobally(t *testing.T) {
+	subs := NewSubscribers(StreamLimits{MaxTotal: 2})
+
+	first := subs.Subscribe(uuid.New(), "a")
+	subs.Subscribe(uuid.New(), "b")
+	subs.Subscribe(uuid.New(), "c")
+
+	if !isDone(first) {
+		t.Error("Expected the oldest connection to be evicted")
+	}
+	if stats := subs.Stats(); stats.Connections != 2 || stats.Evicted != 1 {
+		t.Errorf("Expected 2 connections and 1 eviction, got %+v", stats)
+	}
+}
+
+func TestSubscribers_DropsWhenBufferFull(t *testing.T) {
+	subs := NewSubscribers(StreamLimits{Buffer: 1})
+	creator := uuid.New()
+
+	slow := subs.Subscribe(creator, "alert")
+	fast := subs.Subscribe(creator, "alert")
+
+	event := NewOverlayEvent(EventTest, nil)
+	if clients, delivered := subs.Publish("alert", event); clients != 2 || delivered != 2 {
+		t.Fatalf("Expected 2/2 delivered, got %d/%d", delivered, clients)
+	}
+	<-fast.Events
+
+	if clients, delivered := subs.Publish("alert", event); clients != 2 || delivered != 1 {
+		t.Fatalf("Expected 1/2 delivered, got %d/%d", delivered, clients)
+	}
+	if slow.Dropped() != 1 || fast.Dropped() != 0 {
+		t.Errorf("Expected only the slow client to drop, got %d and %d", slow.Dropped(), fast.Dropped())
+	}
+	if stats := subs.CreatorStats(creator); stats.DroppedEvents != 1 {
+		t.Errorf("Expected 1 dropped event, got %+v", stats)
+	}
+}
+
+func TestSubscribers_ForgetsIdleCreators(t *testing.T) {
+	subs := NewSubscribers(StreamLimits{MaxPerCreator: 1})
+	creator := uuid.New()
+
+	evicted := subs.Subscribe(creator, "alert")
+	subs.Unsubscribe(subs.Subscribe(creator, "alert"))
+	subs.Unsubscribe(evicted)
+
+	// Counters survive the last connection for a while
+	if stats := subs.CreatorStats(creator); stats.Connections != 0 || stats.Evicted != 1 {
+		t.Fatalf("Expected the eviction to be kept, got %+v", stats)
+	}
+
+	subs.mu.Lock()
+	subs.creators[creator].idleSince = time.Now().Add(-creatorStatsTTL)
+	subs.prunedAt = time.Now().Add(-creatorStatsPruneInterval)
+	subs.mu.Unlock()
+
+	subs.Unsubscribe(subs.Subscribe(uuid.New(), "other"))
+	if stats := subs.CreatorStats(creator); stats != (StreamStats{}) {
+		t.Errorf("Expected idle counters to be forgotten, got %+v", stats)
+	}
+	if len(subs.creators) != 0 {
+		t.Errorf("Expected no counters left, got %d", len(subs.creators))
+	}
+}
+
+// BenchmarkSubscribers_Publish10k publishes to 10k connections of one
+// creator, with other creators subscribing and leaving concurrently
+func BenchmarkSubscribers_Publish10k(b *testing.B) {
+	subs := NewSubscribers(StreamLimits{Buffer: 16})
+	creator := uuid.New()
+
+	stop := make(chan struct{})
+	defer close(stop)
+
+	// Every connection reads its events like a stream handler would, so
+	// the benchmark measures delivery rather than dropping
+	for i := 0; i < 10000; i++ {
+		sub := subs.Subscribe(creator, "alert")
+		go func() {
+			for {
+				select {
+				case <-sub.Events:
+				case <-stop:
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+				sub := subs.Subscribe(uuid.New(), fmt.Sprintf("churn:%d", i%100))
+				subs.Unsubscribe(sub)
+			}
+		}
+	}()
+
+	event := NewOverlayEvent(EventDonation, nil)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if clients, _ := subs.Publish("alert", event); clients != 10000 {
+			b.Fatalf("Expected 10000 clients, got %d", clients)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/repository"
+	"github.com/jajanin/backend/internal/utils"
+)
+
+const TopicTicker = "ticker"
+
+// MaxTickerCount is the most supporters a ticker can show
+const MaxTickerCount = 50
+
+type TickerService struct {
+	repo         *repository.TickerRepository
+	donationRepo *repository.DonationRepository
+	userRepo     *repository.UserRepository
+	hub          *StreamHub
+}
+
+func NewTickerService(repo *repository.TickerRepository, donationRepo *repository.DonationRepository, userRepo *repository.UserRepository, hub *StreamHub) *TickerService {
+	return &TickerService{
+		repo:         repo,
+		donationRepo: donationRepo,
+		userRepo:     userRepo,
+		hub:          hub,
+	}
+}
+
+type UpdateTickerSettingsInput struct {
+	Count           *int    `json:"count" binding:"omitempty,min=1,max=50"`
+	WindowMinutes   *int    `json:"window_minutes" binding:"omitempty,min=0,max=10080"`
+	IncludeMessages *bool   `json:"include_messages"`
+	Format          *string `json:"format" binding:"omitempty,max=200"`
+	Separator       *string `json:"separator" binding:"omitempty,max=20"`
+}
+
+// hiddenFromTicker reports whether the donation is still held for (or failed)
+// moderation, so its name and message must not be shown yet
+func hiddenFromTicker(d *models.Donation) bool {
+	return d.AlertStatus == models.AlertStatusPendingReview || d.AlertStatus == models.AlertStatusRejected
+}
+
+// GetTicker builds the ticker snapshot from the creator's most recent paid donations
+func (s *TickerService) GetTicker(userID uuid.UUID) (*models.Ticker, error) {
+	settings, err := s.repo.GetSettings(userID)
+	if err != nil {
+		return nil, errors.New("failed to get ticker settings")
+	}
+
+	donations, err := s.donationRepo.FindRecentByCreatorID(userID, settings.Count)
+	if err != nil {
+		return nil, errors.New("failed to get recent donations")
+	}
+
+	since := settings.Since(time.Now())
+	items := []models.TickerItem{}
+	for i := range donations {
+		if hiddenFromTicker(&donations[i]) {
+			continue
+		}
+		item := settings.NewTickerItem(&donations[i])
+		if since != nil && item.PaidAt.Before(*since) {
+			continue
+		}
+		items = append(items, item)
+	}
+
+	return &models.Ticker{
+		Count:         settings.Count,
+		WindowMinutes: settings.WindowMinutes,
+		Separator:     settings.Separator,
+		Items:         items,
+	}, nil
+}
+
+func (s *TickerService) GetSettings(userID uuid.UUID) (*models.TickerSettings, error) {
+	return s.repo.GetSettings(userID)
+}
+
+func (s *TickerService) UpdateSettings(log *utils.RequestLogger, userID uuid.UUID, input *UpdateTickerSettingsInput) (*models.TickerSettings, error) {
+	settings, err := s.repo.GetSettings(userID)
+	if err != nil {
+		return nil, errors.New("failed to get ticker settings")
+	}
+
+	if input.Count != nil {
+		settings.Count = *input.Count
+	}
+	if input.WindowMinutes != nil {
+		settings.WindowMinutes = *input.WindowMinutes
+	}
+	if input.IncludeMessages != nil {
+		settings.IncludeMessages = *input.IncludeMessages
+	}
+	if input.Format != nil {
+		format := strings.TrimSpace(*input.Format)
+		if format == "" {
+			format = models.DefaultTickerFormat
+		}
+		settings.Format = format
+	}
+	if input.Separator != nil {
+		settings.Separator = *input.Separator
+	}
+
+	if err := s.repo.SaveSettings(settings); err != nil {
+		return nil, errors.New("failed to update ticker settings")
+	}
+
+	// Settings change what every item looks like: resend the whole snapshot
+	if s.hub.HasClients(TopicTicker, userID) {
+		ticker, err := s.GetTicker(userID)
+		if err != nil {
+			log.LogError("TickerService", err, "Failed to refresh ticker")
+		} else {
+			s.hub.Broadcast(log, TopicTicker, userID, NewOverlayEvent(EventTicker, ticker))
+		}
+	}
+	return settings, nil
+}
+
+// OnDonationPaid pushes the new supporter to ticker overlays. Donations to
+// creators who moderate alerts are pushed by OnAlertApproved instead.
+func (s *TickerService) OnDonationPaid(log *utils.RequestLogger, donation *models.Donation) {
+	if !s.hub.HasClients(TopicTicker, donation.CreatorID) {
+		return
+	}
+
+	creator, err := s.userRepo.FindByID(donation.CreatorID)
+	if err != nil || creator.AlertApprovalEnabled {
+		return
+	}
+
+	settings, err := s.repo.GetSettings(donation.CreatorID)
+	if err != nil {
+		log.LogError("TickerService", err, "Failed to get ticker settings")
+		return
+	}
+
+	s.hub.Broadcast(log, TopicTicker, donation.CreatorID, NewOverlayEvent(EventTickerItem, settings.NewTickerItem(donation)))
+}
+
+// OnAlertApproved pushes a supporter that was held for review once the
+// creator approves the alert, unless it already fell out of the window
+func (s *TickerService) OnAlertApproved(log *utils.RequestLogger, donation *models.Donation) {
+	if !s.hub.HasClients(TopicTicker, donation.CreatorID) {
+		return
+	}
+
+	settings, err := s.repo.GetSettings(donation.CreatorID)
+	if err != nil {
+		log.LogError("TickerService", err, "Failed to get ticker settings")
+		return
+	}
+
+	item := settings.NewTickerItem(donation)
+	if since := settings.Since(time.Now()); since != nil && item.PaidAt.Before(*since) {
+		return
+	}
+	s.hub.Broadcast(log, TopicTicker, donation.CreatorID, NewOverlayEvent(EventTickerItem, item))
+}
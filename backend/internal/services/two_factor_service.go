@@ -0,0 +1,403 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jajanin/backend/internal/models"
+	"github.com/jajanin/backend/internal/qrcode"
+	"github.com/jajanin/backend/internal/repository"
+	"github.com/jajanin/backend/internal/totp"
+	"github.com/jajanin/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	twoFactorIssuer    = "Jajanin"
+	recoveryCodeCount  = 10
+	challengeTokenTTL  = 5 * time.Minute
+	StepUpWindow       = 10 * time.Minute // how long a two-factor check covers sensitive actions
+	recoveryCodeLength = 10
+)
+
+var (
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorSetupMissing   = errors.New("start two-factor setup first")
+	ErrTwoFactorMandatory      = errors.New("two-factor authentication is mandatory for staff accounts")
+)
+
+// TwoFactorChangeListener is notified after a user turns two-factor
+// authentication on or off
+type TwoFactorChangeListener interface {
+	OnTwoFactorChanged(userID uuid.UUID)
+}
+
+type TwoFactorService struct {
+	repo        *repository.TwoFactorRepository
+	userRepo    *repository.UserRepository
+	sessionRepo *repository.SessionRepository
+	sealer      *utils.Sealer
+	guard       *LoginGuardService
+	listeners   []TwoFactorChangeListener
+}
+
+func NewTwoFactorService(repo *repository.TwoFactorRepository, userRepo *repository.UserRepository, sessionRepo *repository.SessionRepository, sealer *utils.Sealer, guard *LoginGuardService) *TwoFactorService {
+	return &TwoFactorService{
+		repo:        repo,
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		sealer:      sealer,
+		guard:       guard,
+	}
+}
+
+// AddChangeListener registers a listener for enabled/disabled changes
+func (s *TwoFactorService) AddChangeListener(listener TwoFactorChangeListener) {
+	s.listeners = append(s.listeners, listener)
+}
+
+func (s *TwoFactorService) notifyChanged(userID uuid.UUID) {
+	for _, listener := range s.listeners {
+		listener.OnTwoFactorChanged(userID)
+	}
+}
+
+type TwoFactorCodeInput struct {
+	// A 6-digit authenticator code or one of the recovery codes
+	Code string `json:"code" binding:"required"`
+}
+
+type TwoFactorStatus struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+}
+
+// TwoFactorSetup is shown once while enrolling an authenticator app
+type TwoFactorSetup struct {
+	Secret string `json:"secret"`  // for manual entry
+	URI    string `json:"uri"`     // otpauth:// provisioning URI
+	QRCode string `json:"qr_code"` // the URI as a PNG data URL
+}
+
+// RecoveryCodesResponse lists freshly generated recovery codes, only shown once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// generateRecoveryCodes returns codes formatted like "k3xq7-a9mfz"
+func generateRecoveryCodes() ([]string, []string, error) {
+	enc := base32.StdEncoding.WithPadding(base32.NoPadding)
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(enc.EncodeToString(b))[:recoveryCodeLength]
+		codes[i] = raw[:5] + "-" + raw[5:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+func (s *TwoFactorService) find(userID uuid.UUID) (*models.TwoFactor, error) {
+	tf, err := s.repo.FindByUserID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return tf, err
+}
+
+// IsEnabled reports whether the user must pass a two-factor check to sign in
+func (s *TwoFactorService) IsEnabled(userID uuid.UUID) (bool, error) {
+	tf, err := s.find(userID)
+	if err != nil {
+		return false, err
+	}
+	return tf.IsEnabled(), nil
+}
+
+func (s *TwoFactorService) Status(userID uuid.UUID) (*TwoFactorStatus, error) {
+	tf, err := s.find(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !tf.IsEnabled() {
+		return &TwoFactorStatus{}, nil
+	}
+
+	remaining, err := s.repo.CountUnusedRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &TwoFactorStatus{
+		Enabled:                true,
+		EnabledAt:              tf.EnabledAt,
+		RecoveryCodesRemaining: remaining,
+	}, nil
+}
+
+// Setup starts enrollment with a new secret. It takes effect once Enable
+// confirms a code from the authenticator app.
+func (s *TwoFactorService) Setup(userID uuid.UUID) (*TwoFactorSetup, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	tf, err := s.find(userID)
+	if err != nil {
+		return nil, errors.New("failed to check two-factor status")
+	}
+	if tf.IsEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, errors.New("failed to generate secret")
+	}
+	sealed, err := s.sealer.Seal(secret, userID.String())
+	if err != nil {
+		return nil, errors.New("failed to encrypt secret")
+	}
+	if err := s.repo.Save(&models.TwoFactor{UserID: userID, SecretEncrypted: sealed}); err != nil {
+		return nil, errors.New("failed to save two-factor setup")
+	}
+
+	uri := totp.URI(twoFactorIssuer, user.Email, secret)
+	code, err := qrcode.Encode([]byte(uri), qrcode.Medium)
+	if err != nil {
+		return nil, errors.New("failed to generate QR code")
+	}
+	opts := qrcode.DefaultRenderOptions()
+	opts.Size = 256
+	png, err := code.PNG(opts)
+	if err != nil {
+		return nil, errors.New("failed to generate QR code")
+	}
+
+	return &TwoFactorSetup{
+		Secret: secret,
+		URI:    uri,
+		QRCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+// checkTOTP validates an authenticator code, rejecting replays
+func (s *TwoFactorService) checkTOTP(tf *models.TwoFactor, code string) (bool, error) {
+	secret, stale, err := s.sealer.OpenStale(tf.SecretEncrypted, tf.UserID.String())
+	if err != nil {
+		return false, errors.New("failed to read two-factor secret")
+	}
+	// Move secrets sealed with a previous key over to the current one
+	if stale {
+		if sealed, err := s.sealer.Seal(secret, tf.UserID.String()); err == nil {
+			_ = s.repo.UpdateSecret(tf.UserID, sealed) // Retried on the next check
+		}
+	}
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	return s.repo.UseStep(tf.UserID, step)
+}
+
+// Enable confirms enrollment with a code from the app and returns the
+// recovery codes
+func (s *TwoFactorService) Enable(userID uuid.UUID, input *TwoFactorCodeInput) (*RecoveryCodesResponse, error) {
+	tf, err := s.find(userID)
+	if err != nil {
+		return nil, errors.New("failed to check two-factor status")
+	}
+	if tf == nil {
+		return nil, ErrTwoFactorSetupMissing
+	}
+	if tf.IsEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	ok, err := s.checkTOTP(tf, input.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, errors.New("failed to generate recovery codes")
+	}
+	if err := s.repo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, errors.New("failed to save recovery codes")
+	}
+
+	now := time.Now()
+	tf.EnabledAt = &now
+	// Keep the step UseStep just stored
+	if latest, err := s.repo.FindByUserID(userID); err == nil {
+		tf.LastUsedStep = latest.LastUsedStep
+	}
+	if err := s.repo.Save(tf); err != nil {
+		return nil, errors.New("failed to enable two-factor authentication")
+	}
+	s.notifyChanged(userID)
+
+	return &RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Verify checks an authenticator or recovery code of a user with two-factor
+// enabled. Recovery codes are used up.
+func (s *TwoFactorService) Verify(userID uuid.UUID, code string) error {
+	tf, err := s.find(userID)
+	if err != nil {
+		return errors.New("failed to check two-factor status")
+	}
+	if !tf.IsEnabled() {
+		return ErrTwoFactorNotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		ok, err := s.checkTOTP(tf, code)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		return ErrInvalidTwoFactorCode
+	}
+
+	ok, err := s.repo.UseRecoveryCode(userID, hashRecoveryCode(code))
+	if err != nil {
+		return errors.New("failed to check recovery code")
+	}
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// verifySignedIn checks a code from a signed-in user. Wrong codes count
+// towards the account's login lockout, so a stolen session can't be used to
+// guess them.
+func (s *TwoFactorService) verifySignedIn(log *utils.RequestLogger, user *models.User, code string, meta SessionMeta) error {
+	if err := s.guard.Check(user.Email); err != nil {
+		return err
+	}
+	if err := s.Verify(user.ID, code); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			s.guard.Failed(log, user.Email, &user.ID, meta, "wrong_two_factor_code")
+		}
+		return err
+	}
+	return nil
+}
+
+// Disable turns two-factor authentication off. Staff can't.
+func (s *TwoFactorService) Disable(log *utils.RequestLogger, userID uuid.UUID, input *TwoFactorCodeInput, meta SessionMeta) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if user.IsStaff() {
+		return ErrTwoFactorMandatory
+	}
+	if err := s.verifySignedIn(log, user, input.Code, meta); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(userID); err != nil {
+		return errors.New("failed to disable two-factor authentication")
+	}
+	s.notifyChanged(userID)
+	return nil
+}
+
+// Reset removes the enrollment without a code. It is only for accounts
+// whose previous holder lost the right to them.
+func (s *TwoFactorService) Reset(userID uuid.UUID) error {
+	if err := s.repo.Delete(userID); err != nil {
+		return errors.New("failed to reset two-factor authentication")
+	}
+	s.notifyChanged(userID)
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes after checking a code
+func (s *TwoFactorService) RegenerateRecoveryCodes(log *utils.RequestLogger, userID uuid.UUID, input *TwoFactorCodeInput, meta SessionMeta) (*RecoveryCodesResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if err := s.verifySignedIn(log, user, input.Code, meta); err != nil {
+		return nil, err
+	}
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, errors.New("failed to generate recovery codes")
+	}
+	if err := s.repo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, errors.New("failed to save recovery codes")
+	}
+	return &RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// StepUp re-checks the second factor within a session, unlocking sensitive
+// actions for StepUpWindow
+func (s *TwoFactorService) StepUp(log *utils.RequestLogger, userID, sessionID uuid.UUID, input *TwoFactorCodeInput, meta SessionMeta) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if err := s.verifySignedIn(log, user, input.Code, meta); err != nil {
+		return err
+	}
+	if err := s.sessionRepo.MarkStepUp(sessionID, time.Now()); err != nil {
+		return errors.New("failed to record verification")
+	}
+	return nil
+}
+
+// StepUpSatisfied reports whether a sensitive action may proceed: users
+// without two-factor always may, others need a recent check in this session
+func (s *TwoFactorService) StepUpSatisfied(userID, sessionID uuid.UUID) bool {
+	enabled, err := s.IsEnabled(userID)
+	if err != nil {
+		return false
+	}
+	if !enabled {
+		return true
+	}
+	session, err := s.sessionRepo.FindByID(sessionID)
+	if err != nil || session.StepUpAt == nil {
+		return false
+	}
+	return time.Since(*session.StepUpAt) < StepUpWindow
+}
+
+// NewChallenge issues the token for the second login step
+func (s *TwoFactorService) NewChallenge(userID uuid.UUID) (string, error) {
+	token, err := utils.GenerateChallengeToken(userID, challengeTokenTTL)
+	if err != nil {
+		return "", errors.New("failed to generate challenge")
+	}
+	return token, nil
+}
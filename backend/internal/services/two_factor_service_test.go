@@ -0,0 +1,44 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != recoveryCodeCount || len(hashes) != recoveryCodeCount {
+		t.Fatalf("Expected %d codes, got %d", recoveryCodeCount, len(codes))
+	}
+
+	format := regexp.MustCompile(`^[a-z2-7]{5}-[a-z2-7]{5}$`)
+	seen := map[string]bool{}
+	for i, code := range codes {
+		if !format.MatchString(code) {
+			t.Errorf("Unexpected code format %q", code)
+		}
+		if seen[code] {
+			t.Errorf("Duplicate code %q", code)
+		}
+		seen[code] = true
+		if hashes[i] != hashRecoveryCode(code) {
+			t.Errorf("Hash of %q doesn't match", code)
+		}
+	}
+}
+
+func TestHashRecoveryCode_Normalizes(t *testing.T) {
+	want := hashRecoveryCode("abcde-fghij")
+	for _, typed := range []string{"ABCDE-FGHIJ", "abcdefghij", "abcde fghij", strings.ToUpper("abcdefghij")} {
+		if hashRecoveryCode(typed) != want {
+			t.Errorf("Expected %q to match the stored code", typed)
+		}
+	}
+	if hashRecoveryCode("abcde-fghik") == want {
+		t.Error("Expected a different code to hash differently")
+	}
+}
@@ -3,18 +3,42 @@ package services
 import (
 	"encoding/json"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jajanin/backend/internal/models"
 	"github.com/jajanin/backend/internal/repository"
+	"github.com/jajanin/backend/internal/utils"
 )
 
+// userStore is the UserRepository as UserService, DonationService and
+// WithdrawalService use it, an interface so tests can run without a database
+type userStore interface {
+	FindByID(id uuid.UUID) (*models.User, error)
+	FindByUsername(username string) (*models.User, error)
+	FindByStreamKey(streamKey string) (*models.User, error)
+	FindByPreviousStreamKey(streamKey string) (*models.User, error)
+	CheckUsernameExists(username string, excludeID uuid.UUID) bool
+	ReleaseUsername(username string, cutoff time.Time) error
+	Update(user *models.User) error
+}
+
 type UserService struct {
-	userRepo *repository.UserRepository
+	userRepo        userStore
+	assetRepo       *repository.AssetRepository
+	alertService    *AlertService
+	streamKeyGrace  time.Duration
+	usernameCooloff time.Duration
 }
 
-func NewUserService(userRepo *repository.UserRepository) *UserService {
-	return &UserService{userRepo: userRepo}
+func NewUserService(userRepo *repository.UserRepository, assetRepo *repository.AssetRepository, alertService *AlertService, streamKeyGrace, usernameCooloff time.Duration) *UserService {
+	return &UserService{
+		userRepo:        userRepo,
+		assetRepo:       assetRepo,
+		alertService:    alertService,
+		streamKeyGrace:  streamKeyGrace,
+		usernameCooloff: usernameCooloff,
+	}
 }
 
 type UpdateProfileInput struct {
@@ -57,6 +81,8 @@ func (s *UserService) UpdateProfile(userID uuid.UUID, input *UpdateProfileInput)
 		currentUsername = *user.Username
 	}
 	if input.Username != "" && input.Username != currentUsername {
+		// A deleted account's username is free again after the cool-off
+		_ = s.userRepo.ReleaseUsername(input.Username, time.Now().Add(-s.usernameCooloff))
 		if s.userRepo.CheckUsernameExists(input.Username, userID) {
 			return nil, errors.New("username already taken")
 		}
@@ -116,12 +142,41 @@ func (s *UserService) UpdateSocialLinks(userID uuid.UUID, input *UpdateSocialLin
 }
 
 // UpdateAlertSettings updates the user's alert box settings
-func (s *UserService) UpdateAlertSettings(userID uuid.UUID, settings *models.AlertSettings) (*models.User, error) {
+// (full replace: fields missing from the body are reset to their defaults)
+func (s *UserService) UpdateAlertSettings(log *utils.RequestLogger, userID uuid.UUID, body []byte) (*models.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	settings, err := models.DecodeAlertSettings(models.DefaultAlertSettings(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.saveAlertSettings(log, user, settings)
+}
+
+// PatchAlertSettings updates only the fields present in the body
+func (s *UserService) PatchAlertSettings(log *utils.RequestLogger, userID uuid.UUID, body []byte) (*models.User, error) {
 	user, err := s.userRepo.FindByID(userID)
 	if err != nil {
 		return nil, errors.New("user not found")
 	}
 
+	settings, err := models.DecodeAlertSettings(models.ParseAlertSettings(user.AlertSettings), body)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.saveAlertSettings(log, user, settings)
+}
+
+func (s *UserService) saveAlertSettings(log *utils.RequestLogger, user *models.User, settings *models.AlertSettings) (*models.User, error) {
+	if err := s.ValidateSettingsAssets(user.ID, settings); err != nil {
+		return nil, err
+	}
+
 	// Convert settings to JSON
 	settingsJSON, err := json.Marshal(settings)
 	if err != nil {
@@ -133,40 +188,93 @@ func (s *UserService) UpdateAlertSettings(userID uuid.UUID, settings *models.Ale
 		return nil, errors.New("failed to update alert settings")
 	}
 
+	s.NotifyAlertSettingsChanged(log, user.ID)
 	return user, nil
 }
 
-// GetAlertSettings returns the user's alert box settings
-func (s *UserService) GetAlertSettings(username string) (*models.AlertSettings, error) {
-	user, err := s.userRepo.FindByUsername(username)
+// NotifyAlertSettingsChanged tells connected alert overlays to reload their
+// settings. Each connection resolves its own payload (see OverlayHandler).
+func (s *UserService) NotifyAlertSettingsChanged(log *utils.RequestLogger, userID uuid.UUID) {
+	if s.alertService == nil {
+		return
+	}
+	s.alertService.Publish(log, userID.String(), NewOverlayEvent(EventSettingsChanged, nil))
+}
+
+// ValidateSettingsAssets checks that uploads referenced by alert settings
+// belong to the creator and are of the right kind
+func (s *UserService) ValidateSettingsAssets(userID uuid.UUID, settings *models.AlertSettings) error {
+	if err := s.checkSettingsAsset(userID, settings.SoundAssetID, models.AssetKindSound); err != nil {
+		return err
+	}
+	return s.checkSettingsAsset(userID, settings.ImageAssetID, models.AssetKindImage)
+}
+
+func (s *UserService) checkSettingsAsset(userID uuid.UUID, assetID *uuid.UUID, kind string) error {
+	if assetID == nil {
+		return nil
+	}
+	asset, err := s.assetRepo.FindByIDAndUserID(*assetID, userID)
+	if err != nil {
+		return errors.New(kind + " asset not found")
+	}
+	if asset.Kind != kind {
+		return errors.New("asset is not a " + kind)
+	}
+	return nil
+}
+
+// UpdateAlertApproval toggles the manual approval queue for donation alerts
+func (s *UserService) UpdateAlertApproval(userID uuid.UUID, enabled bool) (*models.User, error) {
+	user, err := s.userRepo.FindByID(userID)
 	if err != nil {
 		return nil, errors.New("user not found")
 	}
 
-	settings := models.DefaultAlertSettings()
+	user.AlertApprovalEnabled = enabled
 
-	if len(user.AlertSettings) > 0 {
-		if err := json.Unmarshal(user.AlertSettings, &settings); err != nil {
-			// Return defaults if parsing fails
-			return &settings, nil
-		}
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, errors.New("failed to update alert approval")
 	}
 
+	return user, nil
+}
+
+// GetAlertSettings returns the user's alert box settings
+func (s *UserService) GetAlertSettings(username string) (*models.AlertSettings, error) {
+	user, err := s.userRepo.FindByUsername(username)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	settings := models.ParseAlertSettings(user.AlertSettings)
 	return &settings, nil
 }
 
-// GetByStreamKey returns a user by their stream key
+// GetByStreamKey returns a user by their stream key, accepting the previous
+// key while it is still in its grace period
 func (s *UserService) GetByStreamKey(streamKey string) (*models.User, error) {
-	return s.userRepo.FindByStreamKey(streamKey)
+	user, err := s.userRepo.FindByStreamKey(streamKey)
+	if err == nil {
+		return user, nil
+	}
+	return s.userRepo.FindByPreviousStreamKey(streamKey)
 }
 
-// RegenerateStreamKey generates a new stream key for the user
+// RegenerateStreamKey generates a new stream key for the user. The old key
+// keeps working for the configured grace period.
 func (s *UserService) RegenerateStreamKey(userID uuid.UUID) (string, error) {
 	user, err := s.userRepo.FindByID(userID)
 	if err != nil {
 		return "", errors.New("user not found")
 	}
 
+	if user.StreamKey != "" && s.streamKeyGrace > 0 {
+		expiresAt := time.Now().Add(s.streamKeyGrace)
+		user.PreviousStreamKey = user.StreamKey
+		user.PreviousStreamKeyExpiresAt = &expiresAt
+	}
+
 	// Generate new unique stream key
 	user.StreamKey = uuid.New().String()
 
@@ -177,20 +285,9 @@ func (s *UserService) RegenerateStreamKey(userID uuid.UUID) (string, error) {
 	return user.StreamKey, nil
 }
 
-// GetAlertSettingsByStreamKey returns alert settings for a user by stream key
-func (s *UserService) GetAlertSettingsByStreamKey(streamKey string) (*models.AlertSettings, error) {
-	user, err := s.userRepo.FindByStreamKey(streamKey)
-	if err != nil {
-		return nil, errors.New("user not found")
-	}
-
-	settings := models.DefaultAlertSettings()
-
-	if len(user.AlertSettings) > 0 {
-		if err := json.Unmarshal(user.AlertSettings, &settings); err != nil {
-			return &settings, nil
-		}
-	}
-
-	return &settings, nil
+// GetAlertSettingsForUser returns the alert settings of an already resolved user
+// (used by overlays authenticated with a stream key or overlay token)
+func (s *UserService) GetAlertSettingsForUser(user *models.User) *models.AlertSettings {
+	settings := models.ParseAlertSettings(user.AlertSettings)
+	return &settings
 }
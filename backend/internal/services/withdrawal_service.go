@@ -11,7 +11,7 @@ import (
 type WithdrawalService struct {
 	withdrawalRepo *repository.WithdrawalRepository
 	donationRepo   *repository.DonationRepository
-	userRepo       *repository.UserRepository
+	userRepo       userStore
 }
 
 func NewWithdrawalService(
@@ -26,6 +26,11 @@ func NewWithdrawalService(
 	}
 }
 
+var ErrEmailNotVerified = errors.New("please verify your email before requesting a withdrawal")
+
+// MinWithdrawalAmount is the smallest payout, matching CreateWithdrawalInput
+const MinWithdrawalAmount = 50000
+
 type CreateWithdrawalInput struct {
 	Amount int64 `json:"amount" binding:"required,min=50000"`
 }
@@ -37,6 +42,11 @@ func (s *WithdrawalService) CreateWithdrawal(userID uuid.UUID, input *CreateWith
 		return nil, errors.New("user not found")
 	}
 
+	// Payouts go to whoever controls the account, so the email must be real
+	if !user.IsEmailVerified() {
+		return nil, ErrEmailNotVerified
+	}
+
 	// Check if bank info is set
 	if user.BankName == "" || user.BankAccount == "" || user.BankHolder == "" {
 		return nil, errors.New("please set your bank information first")
@@ -0,0 +1,85 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test vectors from RFC 6238 appendix B (SHA-1, 8 digits)
+func TestHOTP_RFC6238Vectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	for _, tt := range tests {
+		if got := hotp(key, uint64(Step(time.Unix(tt.unix, 0))), 8); got != tt.want {
+			t.Errorf("T=%d: expected %s, got %s", tt.unix, tt.want, got)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	now := time.Unix(1111111111, 0)
+
+	code, err := Code(secret, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != "050471" {
+		t.Errorf("Expected 050471, got %s", code)
+	}
+
+	step, ok := Validate(secret, code, now)
+	if !ok || step != Step(now) {
+		t.Errorf("Expected code to validate at step %d, got %d %v", Step(now), step, ok)
+	}
+
+	// One step of drift either way is accepted, two are not
+	if _, ok := Validate(secret, code, now.Add(Period)); !ok {
+		t.Error("Expected code from the previous step to be accepted")
+	}
+	if _, ok := Validate(secret, code, now.Add(2*Period)); ok {
+		t.Error("Expected code from two steps ago to be rejected")
+	}
+
+	for _, bad := range []string{"", "12345", "1234567", "000000"} {
+		if _, ok := Validate(secret, bad, now); ok {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+	if _, ok := Validate("not base32!", code, now); ok {
+		t.Error("Expected an invalid secret to be rejected")
+	}
+}
+
+func TestGenerateSecretAndURI(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secret) != 32 {
+		t.Errorf("Expected a 32 character secret, got %q", secret)
+	}
+	if _, err := Code(strings.ToLower(secret), time.Now()); err != nil {
+		t.Errorf("Expected lowercase secrets to decode: %v", err)
+	}
+
+	uri := URI("Jajanin", "budi@example.com", secret)
+	if !strings.HasPrefix(uri, "otpauth://totp/Jajanin:budi@example.com?") {
+		t.Errorf("Unexpected URI %s", uri)
+	}
+	if !strings.Contains(uri, "secret="+secret) || !strings.Contains(uri, "issuer=Jajanin") {
+		t.Errorf("Expected secret and issuer in %s", uri)
+	}
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+const sealedPrefix = "v1:"
+
+var ErrDecrypt = errors.New("failed to decrypt value")
+
+// Sealer encrypts short secrets (such as TOTP seeds) for storage with
+// AES-256-GCM. The key is derived from a configured passphrase. Previous
+// passphrases can still open values while they are re-sealed.
+type Sealer struct {
+	aead     cipher.AEAD
+	previous []cipher.AEAD
+}
+
+func newAEAD(passphrase string) (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte(passphrase))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func NewSealer(passphrase string, previous ...string) (*Sealer, error) {
+	if passphrase == "" {
+		return nil, errors.New("encryption key is required")
+	}
+	aead, err := newAEAD(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	s := &Sealer{aead: aead}
+	for _, p := range previous {
+		if p = strings.TrimSpace(p); p == "" || p == passphrase {
+			continue
+		}
+		old, err := newAEAD(p)
+		if err != nil {
+			return nil, err
+		}
+		s.previous = append(s.previous, old)
+	}
+	return s, nil
+}
+
+// Seal encrypts plaintext. associated binds the ciphertext to a context
+// (e.g. the owning user ID) so it can't be moved to another row.
+func (s *Sealer) Seal(plaintext, associated string) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := s.aead.Seal(nonce, nonce, []byte(plaintext), []byte(associated))
+	return sealedPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value produced by Seal with the same associated data
+func (s *Sealer) Open(ciphertext, associated string) (string, error) {
+	plain, _, err := s.OpenStale(ciphertext, associated)
+	return plain, err
+}
+
+// OpenStale is Open that also reports whether a previous key was needed,
+// in which case the value should be sealed again with the current key
+func (s *Sealer) OpenStale(ciphertext, associated string) (string, bool, error) {
+	encoded, ok := strings.CutPrefix(ciphertext, sealedPrefix)
+	if !ok {
+		return "", false, ErrDecrypt
+	}
+	data, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(data) < s.aead.NonceSize() {
+		return "", false, ErrDecrypt
+	}
+	nonce, sealed := data[:s.aead.NonceSize()], data[s.aead.NonceSize():]
+	for i, aead := range append([]cipher.AEAD{s.aead}, s.previous...) {
+		if plain, err := aead.Open(nil, nonce, sealed, []byte(associated)); err == nil {
+			return string(plain), i > 0, nil
+		}
+	}
+	return "", false, ErrDecrypt
+}
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSealer(t *testing.T) {
+	s, err := NewSealer("passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := s.Seal("JBSWY3DPEHPK3PXP", "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(sealed, "JBSWY3DPEHPK3PXP") || !strings.HasPrefix(sealed, "v1:") {
+		t.Fatalf("Unexpected ciphertext %q", sealed)
+	}
+	if again, _ := s.Seal("JBSWY3DPEHPK3PXP", "user-1"); again == sealed {
+		t.Error("Expected a fresh nonce per seal")
+	}
+
+	plain, err := s.Open(sealed, "user-1")
+	if err != nil || plain != "JBSWY3DPEHPK3PXP" {
+		t.Fatalf("Expected round trip, got %q %v", plain, err)
+	}
+
+	if _, err := s.Open(sealed, "user-2"); !errors.Is(err, ErrDecrypt) {
+		t.Error("Expected a different associated value to fail")
+	}
+	other, _ := NewSealer("other passphrase")
+	if _, err := other.Open(sealed, "user-1"); !errors.Is(err, ErrDecrypt) {
+		t.Error("Expected a different key to fail")
+	}
+	for _, bad := range []string{"", "v1:", "v1:!!!", "JBSWY3DPEHPK3PXP"} {
+		if _, err := s.Open(bad, "user-1"); !errors.Is(err, ErrDecrypt) {
+			t.Errorf("Expected %q to fail", bad)
+		}
+	}
+}
+
+func TestSealer_PreviousKeys(t *testing.T) {
+	old, _ := NewSealer("old passphrase")
+	sealed, err := old.Seal("JBSWY3DPEHPK3PXP", "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, err := NewSealer("new passphrase", "old passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, stale, err := rotated.OpenStale(sealed, "user-1")
+	if err != nil || plain != "JBSWY3DPEHPK3PXP" || !stale {
+		t.Fatalf("Expected the previous key to open a stale value, got %q %v %v", plain, stale, err)
+	}
+
+	resealed, _ := rotated.Seal(plain, "user-1")
+	if _, stale, err := rotated.OpenStale(resealed, "user-1"); err != nil || stale {
+		t.Errorf("Expected a value sealed with the current key not to be stale, got %v %v", stale, err)
+	}
+	if _, err := old.Open(resealed, "user-1"); !errors.Is(err, ErrDecrypt) {
+		t.Error("Expected the old key not to open values sealed with the new one")
+	}
+}
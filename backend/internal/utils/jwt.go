@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -9,45 +10,79 @@ import (
 )
 
 type JWTClaims struct {
-	UserID   uuid.UUID `json:"user_id"`
-	Email    string    `json:"email"`
-	Username string    `json:"username"`
+	UserID    uuid.UUID `json:"user_id"`
+	SessionID uuid.UUID `json:"sid"`
+	Email     string    `json:"email"`
+	Username  string    `json:"username"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID uuid.UUID, email, username string) (string, error) {
+// GenerateToken issues a short-lived access token bound to a session and
+// returns it with its expiry
+func GenerateToken(userID, sessionID uuid.UUID, email, username string) (string, time.Time, error) {
 	cfg := config.AppConfig
+	now := time.Now()
+	expiresAt := now.Add(time.Duration(cfg.JWTAccessTTLMinutes) * time.Minute)
 
 	claims := JWTClaims{
-		UserID:   userID,
-		Email:    email,
-		Username: username,
+		UserID:    userID,
+		SessionID: sessionID,
+		Email:     email,
+		Username:  username,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(cfg.JWTExpiryHours) * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "jajanin",
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(cfg.JWTSecret))
+	signed, err := jwtKeys.Sign(claims)
+	return signed, expiresAt, err
 }
 
 func ValidateToken(tokenString string) (*JWTClaims, error) {
-	cfg := config.AppConfig
-
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(cfg.JWTSecret), nil
-	})
-
+	token, err := jwtKeys.Parse(tokenString, &JWTClaims{})
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(*JWTClaims)
+	if !ok || !token.Valid {
+		return nil, jwt.ErrSignatureInvalid
+	}
+	// Tokens issued before sessions existed can't be revoked, so they are refused
+	if claims.SessionID == uuid.Nil {
+		return nil, errors.New("token has no session")
 	}
+	return claims, nil
+}
+
+const twoFactorChallengeAudience = "jajanin:2fa-challenge"
+
+// GenerateChallengeToken issues the short-lived token that carries a login
+// from the password (or Google) step to the two-factor step. It has no
+// session, so ValidateToken never accepts it as an access token.
+func GenerateChallengeToken(userID uuid.UUID, ttl time.Duration) (string, error) {
+	now := time.Now()
 
-	return nil, jwt.ErrSignatureInvalid
+	claims := jwt.RegisteredClaims{
+		Subject:   userID.String(),
+		Audience:  jwt.ClaimStrings{twoFactorChallengeAudience},
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		Issuer:    "jajanin",
+	}
+
+	return jwtKeys.Sign(claims)
+}
+
+// ValidateChallengeToken returns the user a challenge token was issued for
+func ValidateChallengeToken(tokenString string) (uuid.UUID, error) {
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwtKeys.Parse(tokenString, claims, jwt.WithAudience(twoFactorChallengeAudience), jwt.WithExpirationRequired())
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return uuid.Parse(claims.Subject)
 }
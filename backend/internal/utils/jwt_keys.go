@@ -0,0 +1,233 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jajanin/backend/internal/config"
+)
+
+// SigningKey is one key of the JWT keyring, identified by the kid header
+type SigningKey struct {
+	ID     string
+	Method jwt.SigningMethod
+	sign   any // []byte, *rsa.PrivateKey or ed25519.PrivateKey
+	verify any // []byte, *rsa.PublicKey or ed25519.PublicKey
+}
+
+// NewHMACKey wraps an HS256 secret. The kid is derived from the secret so
+// every instance sharing it agrees on the ID.
+func NewHMACKey(secret string) *SigningKey {
+	sum := sha256.Sum256([]byte(secret))
+	return &SigningKey{
+		ID:     "hs-" + hex.EncodeToString(sum[:4]),
+		Method: jwt.SigningMethodHS256,
+		sign:   []byte(secret),
+		verify: []byte(secret),
+	}
+}
+
+// ParsePrivateKeyPEM reads an RSA (RS256) or Ed25519 (EdDSA) private key in
+// PKCS#1 or PKCS#8 PEM form
+func ParsePrivateKeyPEM(kid string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %s: no PEM block found", kid)
+	}
+
+	var parsed any
+	parsed, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", kid, err)
+		}
+	}
+
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("key %s: RSA keys must be at least 2048 bits", kid)
+		}
+		return &SigningKey{ID: kid, Method: jwt.SigningMethodRS256, sign: key, verify: &key.PublicKey}, nil
+	case ed25519.PrivateKey:
+		return &SigningKey{ID: kid, Method: jwt.SigningMethodEdDSA, sign: key, verify: key.Public()}, nil
+	default:
+		return nil, fmt.Errorf("key %s: unsupported key type %T (use RSA or Ed25519)", kid, parsed)
+	}
+}
+
+// Keyring signs tokens with its active key and verifies them with any key it
+// holds. Rotating means adding the next key, making it active once other
+// services have fetched it from the JWKS, and removing the old key after the
+// longest token lifetime has passed.
+type Keyring struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+func NewKeyring(activeID string, keys ...*SigningKey) (*Keyring, error) {
+	k := &Keyring{keys: make(map[string]*SigningKey, len(keys))}
+	for _, key := range keys {
+		if _, dup := k.keys[key.ID]; dup {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		k.keys[key.ID] = key
+	}
+	k.active = k.keys[activeID]
+	if k.active == nil {
+		return nil, fmt.Errorf("active key %q not found", activeID)
+	}
+	return k, nil
+}
+
+// LoadKeyring builds the keyring from config: the PEM files in JWTKeysDir
+// (named <kid>.pem) if set, otherwise the HS256 JWT secret plus any previous
+// secrets that are still accepted
+func LoadKeyring(cfg *config.Config) (*Keyring, error) {
+	if cfg.JWTKeysDir == "" {
+		active := NewHMACKey(cfg.JWTSecret)
+		keys := []*SigningKey{active}
+		for _, secret := range strings.Split(cfg.JWTPreviousSecrets, ",") {
+			if secret = strings.TrimSpace(secret); secret != "" && secret != cfg.JWTSecret {
+				keys = append(keys, NewHMACKey(secret))
+			}
+		}
+		return NewKeyring(active.ID, keys...)
+	}
+
+	if cfg.JWTActiveKID == "" {
+		return nil, errors.New("JWT_ACTIVE_KID is required with JWT_KEYS_DIR")
+	}
+	files, err := filepath.Glob(filepath.Join(cfg.JWTKeysDir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	var keys []*SigningKey
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		key, err := ParsePrivateKeyPEM(strings.TrimSuffix(filepath.Base(file), ".pem"), data)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return NewKeyring(cfg.JWTActiveKID, keys...)
+}
+
+// Sign signs claims with the active key
+func (k *Keyring) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.active.Method, claims)
+	token.Header["kid"] = k.active.ID
+	return token.SignedString(k.active.sign)
+}
+
+// Parse verifies a token with the key named by its kid. Tokens without a kid
+// (issued before the keyring) are checked against the active key. A key only
+// accepts its own algorithm.
+func (k *Keyring) Parse(tokenString string, claims jwt.Claims, opts ...jwt.ParserOption) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		key := k.active
+		if kid, ok := token.Header["kid"].(string); ok {
+			key = k.keys[kid]
+		}
+		if key == nil {
+			return nil, errors.New("unknown signing key")
+		}
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, jwt.ErrTokenSignatureInvalid
+		}
+		return key.verify, nil
+	}, append(opts, jwt.WithValidMethods(k.methods()))...)
+}
+
+func (k *Keyring) methods() []string {
+	seen := map[string]bool{}
+	var methods []string
+	for _, key := range k.keys {
+		if alg := key.Method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	return methods
+}
+
+// JWK is a public key in JSON Web Key form
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`   // RSA modulus
+	E   string `json:"e,omitempty"`   // RSA exponent
+	Crv string `json:"crv,omitempty"` // OKP curve
+	X   string `json:"x,omitempty"`   // OKP public key
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS lists the public keys for other services to verify tokens with.
+// HS256 secrets are never published, so an HMAC keyring has no keys here.
+func (k *Keyring) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for _, key := range k.keys {
+		switch pub := key.verify.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA",
+				Kid: key.ID,
+				Alg: key.Method.Alg(),
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "OKP",
+				Kid: key.ID,
+				Alg: key.Method.Alg(),
+				Use: "sig",
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+var jwtKeys *Keyring
+
+// InitJWTKeys loads the keyring used by GenerateToken and ValidateToken
+func InitJWTKeys(cfg *config.Config) error {
+	keys, err := LoadKeyring(cfg)
+	if err != nil {
+		return err
+	}
+	jwtKeys = keys
+	return nil
+}
+
+// JWTKeys returns the keyring loaded by InitJWTKeys
+func JWTKeys() *Keyring {
+	return jwtKeys
+}
@@ -0,0 +1,174 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jajanin/backend/internal/config"
+)
+
+func testClaims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Subject:   "user-1",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}
+}
+
+func rsaKeyPEM(t *testing.T) []byte {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+func ed25519KeyPEM(t *testing.T) []byte {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func TestKeyring_SignAndParse(t *testing.T) {
+	rsaKey, err := ParsePrivateKeyPEM("rsa-1", rsaKeyPEM(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	edKey, err := ParsePrivateKeyPEM("ed-1", ed25519KeyPEM(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []*SigningKey{NewHMACKey("secret"), rsaKey, edKey} {
+		ring, err := NewKeyring(key.ID, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signed, err := ring.Sign(testClaims())
+		if err != nil {
+			t.Fatalf("%s: %v", key.ID, err)
+		}
+		claims := &jwt.RegisteredClaims{}
+		token, err := ring.Parse(signed, claims)
+		if err != nil || claims.Subject != "user-1" {
+			t.Fatalf("%s: expected valid token, got %v", key.ID, err)
+		}
+		if token.Header["kid"] != key.ID || token.Method.Alg() != key.Method.Alg() {
+			t.Errorf("%s: unexpected header %v", key.ID, token.Header)
+		}
+	}
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	oldKey := NewHMACKey("old-secret")
+	newKey, err := ParsePrivateKeyPEM("2026-10", ed25519KeyPEM(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before, _ := NewKeyring(oldKey.ID, oldKey)
+	oldToken, _ := before.Sign(testClaims())
+
+	// During the overlap the new key signs and the old one still verifies
+	during, _ := NewKeyring(newKey.ID, newKey, oldKey)
+	if _, err := during.Parse(oldToken, &jwt.RegisteredClaims{}); err != nil {
+		t.Errorf("Expected old token to verify during overlap: %v", err)
+	}
+	newToken, _ := during.Sign(testClaims())
+	if _, err := during.Parse(newToken, &jwt.RegisteredClaims{}); err != nil {
+		t.Errorf("Expected new token to verify: %v", err)
+	}
+
+	after, _ := NewKeyring(newKey.ID, newKey)
+	if _, err := after.Parse(oldToken, &jwt.RegisteredClaims{}); err == nil {
+		t.Error("Expected old token to be rejected once its key is removed")
+	}
+}
+
+func TestKeyring_RejectsAlgorithmConfusion(t *testing.T) {
+	rsaKey, err := ParsePrivateKeyPEM("rsa-1", rsaKeyPEM(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hmacKey := NewHMACKey("secret")
+	ring, _ := NewKeyring(rsaKey.ID, rsaKey, hmacKey)
+
+	// An HS256 token claiming the RSA kid, keyed with the public key bytes
+	pub, _ := x509.MarshalPKIXPublicKey(rsaKey.verify)
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+	forged.Header["kid"] = rsaKey.ID
+	signed, _ := forged.SignedString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub}))
+	if _, err := ring.Parse(signed, &jwt.RegisteredClaims{}); err == nil {
+		t.Error("Expected HS256 token with an RSA kid to be rejected")
+	}
+
+	unknown := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+	unknown.Header["kid"] = "missing"
+	signed, _ = unknown.SignedString([]byte("secret"))
+	if _, err := ring.Parse(signed, &jwt.RegisteredClaims{}); err == nil {
+		t.Error("Expected unknown kid to be rejected")
+	}
+}
+
+func TestKeyring_JWKS(t *testing.T) {
+	rsaKey, _ := ParsePrivateKeyPEM("rsa-1", rsaKeyPEM(t))
+	edKey, _ := ParsePrivateKeyPEM("ed-1", ed25519KeyPEM(t))
+	ring, _ := NewKeyring(edKey.ID, edKey, rsaKey, NewHMACKey("secret"))
+
+	set := ring.JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("Expected 2 public keys (no HMAC), got %d", len(set.Keys))
+	}
+	ed, rs := set.Keys[0], set.Keys[1]
+	if ed.Kid != "ed-1" || ed.Kty != "OKP" || ed.Crv != "Ed25519" || ed.Alg != "EdDSA" || ed.X == "" {
+		t.Errorf("Unexpected Ed25519 JWK %+v", ed)
+	}
+	if rs.Kid != "rsa-1" || rs.Kty != "RSA" || rs.Alg != "RS256" || rs.N == "" || rs.E != "AQAB" {
+		t.Errorf("Unexpected RSA JWK %+v", rs)
+	}
+}
+
+func TestLoadKeyring(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "2026-10.pem"), ed25519KeyPEM(t), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "2026-07.pem"), rsaKeyPEM(t), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ring, err := LoadKeyring(&config.Config{JWTKeysDir: dir, JWTActiveKID: "2026-10"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ring.active.ID != "2026-10" || len(ring.keys) != 2 {
+		t.Errorf("Unexpected keyring: active %s, %d keys", ring.active.ID, len(ring.keys))
+	}
+
+	if _, err := LoadKeyring(&config.Config{JWTKeysDir: dir, JWTActiveKID: "missing"}); err == nil {
+		t.Error("Expected an error for a missing active key")
+	}
+
+	ring, err = LoadKeyring(&config.Config{JWTSecret: "current", JWTPreviousSecrets: "previous, current"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ring.active.ID != NewHMACKey("current").ID || len(ring.keys) != 2 {
+		t.Errorf("Unexpected HMAC keyring: active %s, %d keys", ring.active.ID, len(ring.keys))
+	}
+}
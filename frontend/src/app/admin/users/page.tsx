@@ -1,9 +1,9 @@
 'use client';
 
 import { useEffect, useState } from 'react';
-import { User, Shield, Search, ChevronLeft, ChevronRight } from 'lucide-react';
+import { User, Shield, Search, ChevronLeft, ChevronRight, Lock } from 'lucide-react';
 import AdminLayout from '@/components/AdminLayout';
-import { adminApi } from '@/lib/adminApi';
+import { adminApi, AdminRole, Permission } from '@/lib/adminApi';
 
 interface UserData {
     id: string;
@@ -13,12 +13,15 @@ interface UserData {
     role: string;
     image_url?: string;
     created_at: string;
+    locked_until?: string; // set while logins are locked after failed attempts
 }
 
 export default function AdminUsers() {
     const [users, setUsers] = useState<UserData[]>([]);
     const [isLoading, setIsLoading] = useState(true);
     const [searchTerm, setSearchTerm] = useState('');
+    const [permissions, setPermissions] = useState<Permission[]>([]);
+    const [roles, setRoles] = useState<AdminRole[]>([]);
     const [page, setPage] = useState(1);
     const [totalPages, setTotalPages] = useState(1);
     const limit = 10;
@@ -40,6 +43,44 @@ export default function AdminUsers() {
         fetchUsers();
     }, [page]);
 
+    // Unlocking and assigning roles need more than reading users
+    useEffect(() => {
+        const fetchPermissions = async () => {
+            try {
+                const response = await adminApi.getMe();
+                const granted: Permission[] = response.data.data.permissions;
+                setPermissions(granted);
+                if (granted.includes('roles:manage')) {
+                    const rolesResponse = await adminApi.getRoles();
+                    setRoles(rolesResponse.data.data.roles || []);
+                }
+            } catch (err) {
+                console.error('Failed to fetch permissions', err);
+            }
+        };
+        fetchPermissions();
+    }, []);
+
+    const handleRoleChange = async (user: UserData, role: string) => {
+        if (!confirm(`Ubah role ${user.email} menjadi ${role}?`)) return;
+        try {
+            await adminApi.setUserRole(user.id, role);
+            fetchUsers();
+        } catch (err: any) {
+            alert(err.response?.data?.error || 'Gagal mengubah role user');
+        }
+    };
+
+    const handleUnlock = async (user: UserData) => {
+        if (!confirm(`Buka kunci login untuk ${user.email}?`)) return;
+        try {
+            await adminApi.unlockUser(user.id);
+            fetchUsers();
+        } catch (err: any) {
+            alert(err.response?.data?.error || 'Gagal membuka kunci user');
+        }
+    };
+
     const filteredUsers = users.filter(
         (u) =>
             u.name?.toLowerCase().includes(searchTerm.toLowerCase()) ||
@@ -124,14 +165,36 @@ export default function AdminUsers() {
                                     </td>
                                     <td className="py-4 px-4 text-gray-600 dark:text-gray-400">
                                         {user.email}
+                                        {user.locked_until && permissions.includes('users:write') && (
+                                            <button
+                                                onClick={() => handleUnlock(user)}
+                                                title={`Terkunci sampai ${new Date(user.locked_until).toLocaleString('id-ID')}`}
+                                                className="ml-2 inline-flex items-center gap-1 px-2 py-0.5 rounded-full text-xs bg-red-500/10 text-red-500 hover:bg-red-500/20 transition"
+                                            >
+                                                <Lock className="w-3 h-3" /> Terkunci · Buka
+                                            </button>
+                                        )}
                                     </td>
                                     <td className="py-4 px-4 text-gray-600 dark:text-gray-400">
                                         {user.username ? `@${user.username}` : '-'}
                                     </td>
                                     <td className="py-4 px-4 text-center">
-                                        {user.role === 'admin' ? (
+                                        {roles.length > 0 ? (
+                                            <select
+                                                value={user.role}
+                                                onChange={(e) => handleRoleChange(user, e.target.value)}
+                                                className="input py-1 text-sm w-auto"
+                                            >
+                                                <option value="user">user</option>
+                                                {roles.map((role) => (
+                                                    <option key={role.name} value={role.name}>
+                                                        {role.name}
+                                                    </option>
+                                                ))}
+                                            </select>
+                                        ) : user.role !== 'user' ? (
                                             <span className="flex items-center justify-center gap-1 text-red-500">
-                                                <Shield className="w-4 h-4" /> Admin
+                                                <Shield className="w-4 h-4" /> {user.role}
                                             </span>
                                         ) : (
                                             <span className="text-gray-500">User</span>
@@ -16,7 +16,7 @@ import {
     QrCode,
     BookOpen,
 } from 'lucide-react';
-import { authApi, userApi } from '@/lib/api';
+import { authApi, userApi, overlayApi } from '@/lib/api';
 import { isAuthenticated, User } from '@/lib/auth';
 import DashboardLayout from '@/components/DashboardLayout';
 import QRCodeGenerator from '@/components/QRCodeGenerator';
@@ -63,7 +63,6 @@ export default function OverlaySettingsPage() {
     const [alertSettingsMessage, setAlertSettingsMessage] = useState('');
 
     const baseUrl = typeof window !== 'undefined' ? window.location.origin : '';
-    const apiUrl = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080';
 
     const tabs = [
         { id: 'alert' as TabType, label: 'Alert Box', icon: Bell, description: 'Notifikasi donasi' },
@@ -178,16 +177,12 @@ export default function OverlaySettingsPage() {
     };
 
     const sendTestAlert = async () => {
-        if (!user?.stream_key) return;
-
         setTestSending(true);
         setTestResult(null);
 
         try {
-            const response = await fetch(`${apiUrl}/overlay/test/${user.stream_key}`, {
-                method: 'POST',
-            });
-            const data = await response.json();
+            const response = await overlayApi.sendTest();
+            const data = response.data;
             if (data.success) {
                 setTestResult(`✅ Test alert sent! (${data.client_count} client connected)`);
             } else {
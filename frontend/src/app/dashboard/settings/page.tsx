@@ -13,10 +13,17 @@ import {
     Instagram,
     Youtube,
     Globe,
+    ShieldCheck,
+    KeyRound,
+    Database,
 } from 'lucide-react';
 import { authApi, userApi } from '@/lib/api';
-import { isAuthenticated, User as UserType } from '@/lib/auth';
+import { isAuthenticated, isStaff, User as UserType } from '@/lib/auth';
 import DashboardLayout from '@/components/DashboardLayout';
+import TwoFactorSettings from '@/components/TwoFactorSettings';
+import AccessTokenSettings from '@/components/AccessTokenSettings';
+import PrivacySettings from '@/components/PrivacySettings';
+import AccountSecuritySettings from '@/components/AccountSecuritySettings';
 
 export default function SettingsPage() {
     const router = useRouter();
@@ -25,7 +32,7 @@ export default function SettingsPage() {
     const [isSaving, setIsSaving] = useState(false);
     const [copied, setCopied] = useState(false);
     const [message, setMessage] = useState({ type: '', text: '' });
-    const [activeTab, setActiveTab] = useState<'profile' | 'social'>('profile');
+    const [activeTab, setActiveTab] = useState<'profile' | 'social' | 'security' | 'api' | 'privacy'>('profile');
 
     // Profile form
     const [name, setName] = useState('');
@@ -49,6 +56,11 @@ export default function SettingsPage() {
             return;
         }
 
+        // e.g. ?tab=security when the admin panel asks for two-factor setup
+        if (new URLSearchParams(window.location.search).get('tab') === 'security') {
+            setActiveTab('security');
+        }
+
         const fetchUser = async () => {
             try {
                 const res = await authApi.me();
@@ -167,6 +179,9 @@ export default function SettingsPage() {
     const tabs = [
         { id: 'profile', label: 'Profil & Bank', icon: User },
         { id: 'social', label: 'Social Media', icon: LinkIcon },
+        { id: 'security', label: 'Keamanan', icon: ShieldCheck },
+        { id: 'api', label: 'Token API', icon: KeyRound },
+        { id: 'privacy', label: 'Data & Akun', icon: Database },
     ];
 
     return (
@@ -456,6 +471,22 @@ export default function SettingsPage() {
                         </form>
                     </>
                 )}
+
+                {/* Security Tab */}
+                {activeTab === 'security' && user && (
+                    <div className="space-y-8">
+                        <AccountSecuritySettings user={user} onPasswordSet={() => setUser({ ...user, has_password: true })} />
+                        <div className="pt-8 border-t border-gray-200 dark:border-dark-700">
+                            <TwoFactorSettings isStaff={isStaff(user)} />
+                        </div>
+                    </div>
+                )}
+
+                {/* API Tab */}
+                {activeTab === 'api' && <AccessTokenSettings />}
+
+                {/* Data & Account Tab */}
+                {activeTab === 'privacy' && <PrivacySettings hasPassword={user?.has_password !== false} />}
             </div>
         </DashboardLayout>
     );
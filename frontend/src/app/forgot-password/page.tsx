@@ -0,0 +1,110 @@
+'use client';
+
+import { useState } from 'react';
+import Link from 'next/link';
+import { Heart, Mail, ArrowRight, CheckCircle } from 'lucide-react';
+import { authApi } from '@/lib/api';
+
+export default function ForgotPasswordPage() {
+    const [email, setEmail] = useState('');
+    const [isLoading, setIsLoading] = useState(false);
+    const [isSent, setIsSent] = useState(false);
+    const [error, setError] = useState('');
+
+    const handleSubmit = async (e: React.FormEvent) => {
+        e.preventDefault();
+        setError('');
+        setIsLoading(true);
+
+        try {
+            await authApi.forgotPassword({ email });
+            setIsSent(true);
+        } catch (err: any) {
+            setError(err.response?.data?.error || 'Gagal mengirim link reset password');
+        } finally {
+            setIsLoading(false);
+        }
+    };
+
+    return (
+        <main className="min-h-screen flex items-center justify-center px-4 py-12">
+            <div className="w-full max-w-md">
+                {/* Logo */}
+                <Link href="/" className="flex items-center justify-center gap-2 mb-8">
+                    <div className="w-10 h-10 rounded-lg gradient-bg flex items-center justify-center">
+                        <Heart className="w-6 h-6 text-white" />
+                    </div>
+                    <span className="text-2xl font-bold text-gray-900 dark:text-white">Jajanin</span>
+                </Link>
+
+                <div className="card">
+                    {isSent ? (
+                        <div className="text-center">
+                            <CheckCircle className="w-12 h-12 text-green-500 mx-auto mb-4" />
+                            <h1 className="text-2xl font-bold text-gray-900 dark:text-white mb-2">Cek Email Kamu</h1>
+                            <p className="text-gray-600 dark:text-gray-400">
+                                Kalau <strong>{email}</strong> terdaftar di Jajanin, kami sudah mengirim link untuk membuat password baru.
+                            </p>
+                        </div>
+                    ) : (
+                        <>
+                            <h1 className="text-2xl font-bold text-gray-900 dark:text-white text-center mb-2">
+                                Lupa Password
+                            </h1>
+                            <p className="text-gray-600 dark:text-gray-400 text-center mb-8">
+                                Masukkan email akun kamu, kami akan mengirim link reset password
+                            </p>
+
+                            <form onSubmit={handleSubmit} className="space-y-4">
+                                <div>
+                                    <label className="block text-sm font-medium text-gray-600 dark:text-gray-400 mb-2">
+                                        Email
+                                    </label>
+                                    <div className="relative">
+                                        <Mail className="absolute left-4 top-1/2 -translate-y-1/2 w-5 h-5 text-gray-500" />
+                                        <input
+                                            type="email"
+                                            value={email}
+                                            onChange={(e) => setEmail(e.target.value)}
+                                            placeholder="email@contoh.com"
+                                            className="input input-icon"
+                                            required
+                                        />
+                                    </div>
+                                </div>
+
+                                {error && (
+                                    <div className="bg-red-500/10 border border-red-500/50 rounded-lg p-3 text-red-400 text-sm">
+                                        {error}
+                                    </div>
+                                )}
+
+                                <button
+                                    type="submit"
+                                    disabled={isLoading}
+                                    className="btn-primary w-full flex items-center justify-center gap-2"
+                                >
+                                    {isLoading ? (
+                                        <div className="w-5 h-5 border-2 border-white/30 border-t-white rounded-full animate-spin" />
+                                    ) : (
+                                        <>
+                                            Kirim Link Reset
+                                            <ArrowRight className="w-4 h-4" />
+                                        </>
+                                    )}
+                                </button>
+                            </form>
+                        </>
+                    )}
+
+                    <p className="text-center text-gray-600 dark:text-gray-400 mt-6">
+                        Ingat password?{' '}
+                        <Link href="/login" className="text-primary-600 dark:text-primary-400 hover:text-primary-500 dark:hover:text-primary-300 transition">
+                            Masuk
+                        </Link>
+                    </p>
+                </div>
+            </div>
+        </main>
+    );
+}
@@ -4,9 +4,10 @@ import { useEffect, useState, useCallback, useRef } from 'react';
 import { useParams } from 'next/navigation';
 import { getTTSService } from '@/lib/tts';
 import { AlertSettings, DEFAULT_ALERT_SETTINGS } from '@/lib/alertSettings';
-import { userApi } from '@/lib/api';
+import { userApi, overlayApi } from '@/lib/api';
 
 interface AlertData {
+    donation_id?: string; // absent for test alerts
     supporter_name: string;
     amount: number;
     message: string;
@@ -16,6 +17,15 @@ interface AlertData {
     quantity?: number;
 }
 
+// Envelope of every message on the overlay stream
+interface OverlayEvent {
+    v: number;
+    id: string;
+    type: string;
+    timestamp: string;
+    data?: unknown;
+}
+
 // Font mapping
 const FONT_MAP: Record<string, string> = {
     'inter': "'Inter', system-ui, sans-serif",
@@ -95,19 +105,24 @@ export default function AlertOverlayPage() {
         if (!settings.sound_enabled) return;
 
         try {
-            const audio = new Audio(`/sounds/${settings.sound_file}.mp3`);
+            const audio = new Audio(settings.sound_url || `/sounds/${settings.sound_file}.mp3`);
             audio.volume = settings.sound_volume / 100;
             audio.play().catch(e => console.log('Sound play failed:', e));
             audioRef.current = audio;
         } catch (err) {
             console.error('Failed to play sound:', err);
         }
-    }, [settings.sound_enabled, settings.sound_file, settings.sound_volume]);
+    }, [settings.sound_enabled, settings.sound_file, settings.sound_url, settings.sound_volume]);
 
     const showAlert = useCallback((alert: AlertData) => {
         setCurrentAlert(alert);
         setIsVisible(true);
 
+        // Let the dashboard know this alert was actually displayed
+        if (alert.donation_id) {
+            overlayApi.ackAlert(streamKey, alert.donation_id).catch(() => {});
+        }
+
         // Play sound effect
         playSound();
 
@@ -131,7 +146,7 @@ export default function AlertOverlayPage() {
                 setCurrentAlert(null);
             }, 500); // Wait for fade-out animation
         }, settings.duration * 1000);
-    }, [settings.duration, playSound, ttsEnabled, audioActivated]);
+    }, [streamKey, settings.duration, playSound, ttsEnabled, audioActivated]);
 
     useEffect(() => {
         if (!streamKey) return;
@@ -143,18 +158,29 @@ export default function AlertOverlayPage() {
             setConnectionStatus('connected');
         };
 
-        eventSource.addEventListener('connected', () => {
-            // Connection confirmed
-        });
-
-        eventSource.addEventListener('alert', (event) => {
+        // Every message is an event envelope: { v, id, type, timestamp, data }.
+        // Unknown types are ignored so new events don't break old overlays.
+        eventSource.onmessage = (message) => {
+            let event: OverlayEvent;
             try {
-                const alert: AlertData = JSON.parse(event.data);
-                showAlert(alert);
+                event = JSON.parse(message.data);
             } catch {
-                // Failed to parse alert - ignore
+                return; // Malformed event - ignore
             }
-        });
+
+            switch (event.type) {
+                case 'settings_changed':
+                    // Sent on every (re)connect and whenever the settings are saved
+                    if (event.data) {
+                        setSettings({ ...DEFAULT_ALERT_SETTINGS, ...(event.data as Partial<AlertSettings>) });
+                    }
+                    break;
+                case 'donation':
+                case 'test':
+                    showAlert(event.data as AlertData);
+                    break;
+            }
+        };
 
         eventSource.onerror = () => {
             setConnectionStatus('disconnected');
@@ -216,6 +242,10 @@ export default function AlertOverlayPage() {
                         style={{ background: settings.background_color }}
                     />
                     <div className="alert-content">
+                        {settings.image_url && (
+                            // eslint-disable-next-line @next/next/no-img-element
+                            <img className="alert-image" src={settings.image_url} alt="" />
+                        )}
                         <div className="alert-icon" style={{ color: settings.accent_color }}>
                             <svg viewBox="0 0 24 24" fill="none" xmlns="http://www.w3.org/2000/svg">
                                 <path d="M12 2L15.09 8.26L22 9.27L17 14.14L18.18 21.02L12 17.77L5.82 21.02L7 14.14L2 9.27L8.91 8.26L12 2Z" fill="currentColor" />
@@ -430,6 +460,13 @@ export default function AlertOverlayPage() {
                     gap: 12px;
                 }
 
+                .alert-image {
+                    display: block;
+                    max-width: 100%;
+                    max-height: 200px;
+                    margin: 0 auto 12px;
+                }
+
                 .alert-icon {
                     width: 48px;
                     height: 48px;
@@ -5,45 +5,21 @@ import Link from 'next/link';
 import { useRouter } from 'next/navigation';
 import { Heart, Mail, Lock, User, ArrowRight } from 'lucide-react';
 import { authApi } from '@/lib/api';
-import { setToken, isAuthenticated } from '@/lib/auth';
-
-// Google Icon Component
-const GoogleIcon = () => (
-    <svg className="w-5 h-5" viewBox="0 0 24 24">
-        <path
-            fill="#4285F4"
-            d="M22.56 12.25c0-.78-.07-1.53-.2-2.25H12v4.26h5.92c-.26 1.37-1.04 2.53-2.21 3.31v2.77h3.57c2.08-1.92 3.28-4.74 3.28-8.09z"
-        />
-        <path
-            fill="#34A853"
-            d="M12 23c2.97 0 5.46-.98 7.28-2.66l-3.57-2.77c-.98.66-2.23 1.06-3.71 1.06-2.86 0-5.29-1.93-6.16-4.53H2.18v2.84C3.99 20.53 7.7 23 12 23z"
-        />
-        <path
-            fill="#FBBC05"
-            d="M5.84 14.09c-.22-.66-.35-1.36-.35-2.09s.13-1.43.35-2.09V7.07H2.18C1.43 8.55 1 10.22 1 12s.43 3.45 1.18 4.93l2.85-2.22.81-.62z"
-        />
-        <path
-            fill="#EA4335"
-            d="M12 5.38c1.62 0 3.06.56 4.21 1.64l3.15-3.15C17.45 2.09 14.97 1 12 1 7.7 1 3.99 3.47 2.18 7.07l3.66 2.84c.87-2.6 3.3-4.53 6.16-4.53z"
-        />
-    </svg>
-);
+import { setSession, isAuthenticated, AuthResult, isTwoFactorChallenge } from '@/lib/auth';
 
 // Client-only Google Login Button component
-function GoogleLoginButton({ onSuccess, onError }: { onSuccess: (token: string) => void; onError: (error: string) => void }) {
+function GoogleLoginButton({ onSuccess, onError }: { onSuccess: (result: AuthResult) => void; onError: (error: string) => void }) {
     const [isLoading, setIsLoading] = useState(false);
-    const [GoogleOAuthProvider, setGoogleOAuthProvider] = useState<any>(null);
-    const [useGoogleLogin, setUseGoogleLogin] = useState<any>(null);
+    const [GoogleLogin, setGoogleLogin] = useState<any>(null);
 
     useEffect(() => {
         // Dynamically import Google OAuth only on client side
         import('@react-oauth/google').then((module) => {
-            setGoogleOAuthProvider(() => module.GoogleOAuthProvider);
-            setUseGoogleLogin(() => module.useGoogleLogin);
+            setGoogleLogin(() => module.GoogleLogin);
         });
     }, []);
 
-    if (!GoogleOAuthProvider || !useGoogleLogin) {
+    if (!GoogleLogin || isLoading) {
         return (
             <button
                 disabled
@@ -55,62 +31,36 @@ function GoogleLoginButton({ onSuccess, onError }: { onSuccess: (token: string)
         );
     }
 
-    return (
-        <GoogleLoginButtonInner 
-            useGoogleLogin={useGoogleLogin} 
-            isLoading={isLoading} 
-            setIsLoading={setIsLoading}
-            onSuccess={onSuccess}
-            onError={onError}
-        />
-    );
-}
-
-function GoogleLoginButtonInner({ useGoogleLogin, isLoading, setIsLoading, onSuccess, onError }: any) {
-    const googleLogin = useGoogleLogin({
-        onSuccess: async (tokenResponse: any) => {
-            setIsLoading(true);
-            try {
-                const userInfoResponse = await fetch('https://www.googleapis.com/oauth2/v3/userinfo', {
-                    headers: { Authorization: `Bearer ${tokenResponse.access_token}` },
-                });
-                const userInfo = await userInfoResponse.json();
-
-                const response = await authApi.googleAuth({
-                    google_id: userInfo.sub,
-                    email: userInfo.email,
-                    name: userInfo.name,
-                    image_url: userInfo.picture || '',
-                });
-
-                const { token } = response.data.data;
-                onSuccess(token);
-            } catch (err: any) {
-                onError(err.response?.data?.error || 'Gagal daftar dengan Google');
-            } finally {
-                setIsLoading(false);
-            }
-        },
-        onError: () => {
+    // The credential is a Google-signed ID token; the backend verifies it and
+    // reads the account from it, so nothing else is sent
+    const handleCredential = async (credentialResponse: any) => {
+        if (!credentialResponse.credential) {
             onError('Daftar dengan Google gagal. Silakan coba lagi.');
-        },
-    });
+            return;
+        }
+        setIsLoading(true);
+        try {
+            const response = await authApi.googleAuth({ id_token: credentialResponse.credential });
+            onSuccess(response.data.data);
+        } catch (err: any) {
+            onError(err.response?.data?.error || 'Gagal daftar dengan Google');
+        } finally {
+            setIsLoading(false);
+        }
+    };
 
     return (
-        <button
-            onClick={() => googleLogin()}
-            disabled={isLoading}
-            className="w-full flex items-center justify-center gap-3 px-4 py-3 bg-white dark:bg-dark-800 border-2 border-gray-200 dark:border-dark-600 rounded-xl font-medium text-gray-700 dark:text-gray-200 hover:bg-gray-50 dark:hover:bg-dark-700 hover:border-gray-300 dark:hover:border-dark-500 transition-all duration-200 shadow-sm hover:shadow-md"
-        >
-            {isLoading ? (
-                <div className="w-5 h-5 border-2 border-primary-600 border-t-transparent rounded-full animate-spin" />
-            ) : (
-                <>
-                    <GoogleIcon />
-                    <span>Daftar dengan Google</span>
-                </>
-            )}
-        </button>
+        <div className="w-full flex justify-center">
+            <GoogleLogin
+                onSuccess={handleCredential}
+                onError={() => onError('Daftar dengan Google gagal. Silakan coba lagi.')}
+                text="signup_with"
+                shape="pill"
+                size="large"
+                width="384"
+                locale="id"
+            />
+        </div>
     );
 }
 
@@ -133,8 +83,13 @@ export default function RegisterPage() {
         }
     }, [router]);
 
-    const handleGoogleSuccess = (token: string) => {
-        setToken(token);
+    const handleGoogleSuccess = (result: AuthResult) => {
+        // Existing accounts with two-factor authentication finish on the login page
+        if (isTwoFactorChallenge(result)) {
+            setError('Akun ini memakai verifikasi dua langkah. Silakan masuk lewat halaman login.');
+            return;
+        }
+        setSession(result);
         router.push('/dashboard');
     };
 
@@ -149,8 +104,7 @@ export default function RegisterPage() {
 
         try {
             const response = await authApi.register({ name, email, password });
-            const { token } = response.data.data;
-            setToken(token);
+            setSession(response.data.data);
             router.push('/dashboard');
         } catch (err: any) {
             setError(err.response?.data?.error || 'Gagal mendaftar');
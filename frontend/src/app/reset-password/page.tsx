@@ -0,0 +1,162 @@
+'use client';
+
+import { Suspense, useState } from 'react';
+import Link from 'next/link';
+import { useSearchParams } from 'next/navigation';
+import { Heart, Lock, ArrowRight, CheckCircle } from 'lucide-react';
+import { authApi } from '@/lib/api';
+import { removeToken } from '@/lib/auth';
+
+function ResetPasswordForm() {
+    const searchParams = useSearchParams();
+    const token = searchParams.get('token') || '';
+    const [password, setPassword] = useState('');
+    const [confirmPassword, setConfirmPassword] = useState('');
+    const [isLoading, setIsLoading] = useState(false);
+    const [isDone, setIsDone] = useState(false);
+    const [error, setError] = useState('');
+
+    const handleSubmit = async (e: React.FormEvent) => {
+        e.preventDefault();
+        setError('');
+
+        if (password !== confirmPassword) {
+            setError('Konfirmasi password tidak sama');
+            return;
+        }
+
+        setIsLoading(true);
+        try {
+            await authApi.resetPassword({ token, password });
+            // Every session was signed out by the reset, including this browser's
+            removeToken();
+            setIsDone(true);
+        } catch (err: any) {
+            setError(err.response?.data?.error || 'Gagal mengganti password');
+        } finally {
+            setIsLoading(false);
+        }
+    };
+
+    if (isDone) {
+        return (
+            <div className="text-center">
+                <CheckCircle className="w-12 h-12 text-green-500 mx-auto mb-4" />
+                <h1 className="text-2xl font-bold text-gray-900 dark:text-white mb-2">Password Diganti</h1>
+                <p className="text-gray-600 dark:text-gray-400 mb-6">
+                    Kamu sudah keluar dari semua perangkat. Silakan masuk dengan password baru.
+                </p>
+                <Link href="/login" className="btn-primary inline-flex items-center gap-2">
+                    Masuk
+                    <ArrowRight className="w-4 h-4" />
+                </Link>
+            </div>
+        );
+    }
+
+    if (!token) {
+        return (
+            <div className="text-center">
+                <h1 className="text-2xl font-bold text-gray-900 dark:text-white mb-2">Link Tidak Valid</h1>
+                <p className="text-gray-600 dark:text-gray-400 mb-6">
+                    Link reset password tidak lengkap. Minta link baru dari halaman lupa password.
+                </p>
+                <Link href="/forgot-password" className="btn-primary inline-flex items-center gap-2">
+                    Minta Link Baru
+                </Link>
+            </div>
+        );
+    }
+
+    return (
+        <>
+            <h1 className="text-2xl font-bold text-gray-900 dark:text-white text-center mb-2">
+                Buat Password Baru
+            </h1>
+            <p className="text-gray-600 dark:text-gray-400 text-center mb-8">
+                Password minimal 6 karakter
+            </p>
+
+            <form onSubmit={handleSubmit} className="space-y-4">
+                <div>
+                    <label className="block text-sm font-medium text-gray-600 dark:text-gray-400 mb-2">
+                        Password Baru
+                    </label>
+                    <div className="relative">
+                        <Lock className="absolute left-4 top-1/2 -translate-y-1/2 w-5 h-5 text-gray-500" />
+                        <input
+                            type="password"
+                            value={password}
+                            onChange={(e) => setPassword(e.target.value)}
+                            placeholder="Masukkan password baru"
+                            className="input input-icon"
+                            minLength={6}
+                            required
+                        />
+                    </div>
+                </div>
+
+                <div>
+                    <label className="block text-sm font-medium text-gray-600 dark:text-gray-400 mb-2">
+                        Konfirmasi Password
+                    </label>
+                    <div className="relative">
+                        <Lock className="absolute left-4 top-1/2 -translate-y-1/2 w-5 h-5 text-gray-500" />
+                        <input
+                            type="password"
+                            value={confirmPassword}
+                            onChange={(e) => setConfirmPassword(e.target.value)}
+                            placeholder="Ulangi password baru"
+                            className="input input-icon"
+                            minLength={6}
+                            required
+                        />
+                    </div>
+                </div>
+
+                {error && (
+                    <div className="bg-red-500/10 border border-red-500/50 rounded-lg p-3 text-red-400 text-sm">
+                        {error}
+                    </div>
+                )}
+
+                <button
+                    type="submit"
+                    disabled={isLoading}
+                    className="btn-primary w-full flex items-center justify-center gap-2"
+                >
+                    {isLoading ? (
+                        <div className="w-5 h-5 border-2 border-white/30 border-t-white rounded-full animate-spin" />
+                    ) : (
+                        <>
+                            Simpan Password
+                            <ArrowRight className="w-4 h-4" />
+                        </>
+                    )}
+                </button>
+            </form>
+        </>
+    );
+}
+
+export default function ResetPasswordPage() {
+    return (
+        <main className="min-h-screen flex items-center justify-center px-4 py-12">
+            <div className="w-full max-w-md">
+                {/* Logo */}
+                <Link href="/" className="flex items-center justify-center gap-2 mb-8">
+                    <div className="w-10 h-10 rounded-lg gradient-bg flex items-center justify-center">
+                        <Heart className="w-6 h-6 text-white" />
+                    </div>
+                    <span className="text-2xl font-bold text-gray-900 dark:text-white">Jajanin</span>
+                </Link>
+
+                <div className="card">
+                    <Suspense fallback={<div className="w-8 h-8 border-4 border-primary-600 border-t-transparent rounded-full animate-spin mx-auto" />}>
+                        <ResetPasswordForm />
+                    </Suspense>
+                </div>
+            </div>
+        </main>
+    );
+}
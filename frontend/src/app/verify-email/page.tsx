@@ -0,0 +1,95 @@
+'use client';
+
+import { Suspense, useEffect, useRef, useState } from 'react';
+import Link from 'next/link';
+import { useSearchParams } from 'next/navigation';
+import { Heart, CheckCircle, XCircle, ArrowRight } from 'lucide-react';
+import { authApi } from '@/lib/api';
+import { isAuthenticated } from '@/lib/auth';
+
+function VerifyEmailContent() {
+    const searchParams = useSearchParams();
+    const token = searchParams.get('token') || '';
+    const [status, setStatus] = useState<'loading' | 'success' | 'error'>('loading');
+    const [error, setError] = useState('');
+    const submitted = useRef(false);
+
+    useEffect(() => {
+        // Tokens are single-use, so don't send it twice (React strict mode runs effects twice)
+        if (submitted.current) return;
+        submitted.current = true;
+
+        if (!token) {
+            setStatus('error');
+            setError('Link verifikasi tidak lengkap');
+            return;
+        }
+        authApi
+            .verifyEmail({ token })
+            .then(() => setStatus('success'))
+            .catch((err) => {
+                setStatus('error');
+                setError(err.response?.data?.error || 'Link verifikasi tidak valid atau sudah kedaluwarsa');
+            });
+    }, [token]);
+
+    if (status === 'loading') {
+        return (
+            <div className="text-center">
+                <div className="w-12 h-12 border-4 border-primary-600 border-t-transparent rounded-full animate-spin mx-auto mb-4" />
+                <p className="text-gray-600 dark:text-gray-400">Memverifikasi email...</p>
+            </div>
+        );
+    }
+
+    const next = isAuthenticated() ? '/dashboard' : '/login';
+
+    if (status === 'success') {
+        return (
+            <div className="text-center">
+                <CheckCircle className="w-12 h-12 text-green-500 mx-auto mb-4" />
+                <h1 className="text-2xl font-bold text-gray-900 dark:text-white mb-2">Email Terverifikasi</h1>
+                <p className="text-gray-600 dark:text-gray-400 mb-6">Terima kasih! Email kamu sudah terverifikasi.</p>
+                <Link href={next} className="btn-primary inline-flex items-center gap-2">
+                    Lanjut
+                    <ArrowRight className="w-4 h-4" />
+                </Link>
+            </div>
+        );
+    }
+
+    return (
+        <div className="text-center">
+            <XCircle className="w-12 h-12 text-red-500 mx-auto mb-4" />
+            <h1 className="text-2xl font-bold text-gray-900 dark:text-white mb-2">Verifikasi Gagal</h1>
+            <p className="text-gray-600 dark:text-gray-400 mb-6">
+                {error}. Kamu bisa meminta link baru dari dashboard.
+            </p>
+            <Link href={next} className="btn-primary inline-flex items-center gap-2">
+                Kembali
+            </Link>
+        </div>
+    );
+}
+
+export default function VerifyEmailPage() {
+    return (
+        <main className="min-h-screen flex items-center justify-center px-4 py-12">
+            <div className="w-full max-w-md">
+                {/* Logo */}
+                <Link href="/" className="flex items-center justify-center gap-2 mb-8">
+                    <div className="w-10 h-10 rounded-lg gradient-bg flex items-center justify-center">
+                        <Heart className="w-6 h-6 text-white" />
+                    </div>
+                    <span className="text-2xl font-bold text-gray-900 dark:text-white">Jajanin</span>
+                </Link>
+
+                <div className="card">
+                    <Suspense fallback={<div className="w-8 h-8 border-4 border-primary-600 border-t-transparent rounded-full animate-spin mx-auto" />}>
+                        <VerifyEmailContent />
+                    </Suspense>
+                </div>
+            </div>
+        </main>
+    );
+}
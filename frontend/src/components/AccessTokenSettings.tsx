@@ -0,0 +1,209 @@
+'use client';
+
+import { useEffect, useState } from 'react';
+import { KeyRound, Copy, Check, Trash2 } from 'lucide-react';
+import { authApi } from '@/lib/api';
+
+interface AccessToken {
+    id: string;
+    name: string;
+    scopes: string[];
+    token_prefix: string;
+    expires_at?: string;
+    last_used_at?: string;
+    last_used_ip?: string;
+    created_at: string;
+}
+
+const scopeOptions = [
+    { id: 'donations:read', label: 'Baca donasi' },
+    { id: 'stats:read', label: 'Baca statistik' },
+    { id: 'alerts:test', label: 'Kirim test alert' },
+];
+
+const expiryOptions = [7, 30, 90, 365];
+
+const formatDate = (value?: string) =>
+    value
+        ? new Date(value).toLocaleDateString('id-ID', { day: 'numeric', month: 'short', year: 'numeric' })
+        : '-';
+
+// Create and revoke personal access tokens for bots and scripts
+export default function AccessTokenSettings() {
+    const [tokens, setTokens] = useState<AccessToken[]>([]);
+    const [name, setName] = useState('');
+    const [scopes, setScopes] = useState<string[]>(['donations:read']);
+    const [expiresInDays, setExpiresInDays] = useState(90);
+    const [newToken, setNewToken] = useState('');
+    const [isBusy, setIsBusy] = useState(false);
+    const [copied, setCopied] = useState(false);
+    const [error, setError] = useState('');
+
+    const loadTokens = async () => {
+        try {
+            const response = await authApi.getAccessTokens();
+            setTokens(response.data.data || []);
+        } catch (err) {
+            console.error('Failed to load access tokens:', err);
+        }
+    };
+
+    useEffect(() => {
+        loadTokens();
+    }, []);
+
+    const toggleScope = (scope: string) => {
+        setScopes((current) =>
+            current.includes(scope) ? current.filter((s) => s !== scope) : [...current, scope]
+        );
+    };
+
+    const create = async (e: React.FormEvent) => {
+        e.preventDefault();
+        setError('');
+        setIsBusy(true);
+        try {
+            const response = await authApi.createAccessToken({ name: name.trim(), scopes, expires_in_days: expiresInDays });
+            setNewToken(response.data.data.token);
+            setName('');
+            await loadTokens();
+        } catch (err: any) {
+            setError(err.response?.data?.error || 'Gagal membuat token');
+        } finally {
+            setIsBusy(false);
+        }
+    };
+
+    const revoke = async (token: AccessToken) => {
+        if (!confirm(`Cabut token "${token.name}"? Bot yang memakainya akan langsung berhenti.`)) return;
+        try {
+            await authApi.revokeAccessToken(token.id);
+            await loadTokens();
+        } catch (err: any) {
+            setError(err.response?.data?.error || 'Gagal mencabut token');
+        }
+    };
+
+    const copyToken = () => {
+        navigator.clipboard.writeText(newToken);
+        setCopied(true);
+        setTimeout(() => setCopied(false), 2000);
+    };
+
+    return (
+        <div className="space-y-6">
+            <div className="flex items-center gap-3">
+                <div className="w-10 h-10 rounded-lg bg-primary-600/20 flex items-center justify-center">
+                    <KeyRound className="w-5 h-5 text-primary-600 dark:text-primary-400" />
+                </div>
+                <div>
+                    <h2 className="text-lg font-semibold text-gray-900 dark:text-white">Token Akses API</h2>
+                    <p className="text-sm text-gray-600 dark:text-gray-400">
+                        Untuk bot Discord, spreadsheet atau skrip. Kirim sebagai header{' '}
+                        <span className="font-mono">Authorization: Bearer &lt;token&gt;</span>.
+                    </p>
+                </div>
+            </div>
+
+            {/* The plain token is only returned once */}
+            {newToken && (
+                <div className="p-4 rounded-lg bg-gray-50 dark:bg-dark-800 border border-gray-200 dark:border-dark-600">
+                    <p className="text-sm text-gray-600 dark:text-gray-400 mb-3">
+                        Salin token ini sekarang. Token tidak akan ditampilkan lagi.
+                    </p>
+                    <div className="flex items-center gap-3">
+                        <input type="text" value={newToken} readOnly className="input flex-1 font-mono text-sm" />
+                        <button onClick={copyToken} className="btn-secondary flex items-center gap-2">
+                            {copied ? <Check className="w-4 h-4" /> : <Copy className="w-4 h-4" />}
+                            {copied ? 'Tersalin!' : 'Salin'}
+                        </button>
+                    </div>
+                </div>
+            )}
+
+            <form onSubmit={create} className="space-y-4">
+                <div>
+                    <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-2">Nama Token</label>
+                    <input
+                        type="text"
+                        value={name}
+                        onChange={(e) => setName(e.target.value)}
+                        placeholder="Bot Discord"
+                        maxLength={100}
+                        className="input"
+                    />
+                </div>
+                <div>
+                    <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-2">Akses</label>
+                    <div className="flex flex-wrap gap-4">
+                        {scopeOptions.map((scope) => (
+                            <label key={scope.id} className="flex items-center gap-2 text-sm text-gray-700 dark:text-gray-300">
+                                <input
+                                    type="checkbox"
+                                    checked={scopes.includes(scope.id)}
+                                    onChange={() => toggleScope(scope.id)}
+                                />
+                                {scope.label}
+                            </label>
+                        ))}
+                    </div>
+                </div>
+                <div>
+                    <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-2">Berlaku</label>
+                    <select
+                        value={expiresInDays}
+                        onChange={(e) => setExpiresInDays(Number(e.target.value))}
+                        className="input max-w-xs"
+                    >
+                        {expiryOptions.map((days) => (
+                            <option key={days} value={days}>
+                                {days} hari
+                            </option>
+                        ))}
+                    </select>
+                </div>
+                <button type="submit" disabled={isBusy || !name.trim() || scopes.length === 0} className="btn-primary">
+                    Buat Token
+                </button>
+            </form>
+
+            {error && (
+                <div className="bg-red-500/10 border border-red-500/50 rounded-lg p-3 text-red-400 text-sm">
+                    {error}
+                </div>
+            )}
+
+            <div className="space-y-3">
+                {tokens.length === 0 ? (
+                    <p className="text-sm text-gray-500">Belum ada token.</p>
+                ) : (
+                    tokens.map((token) => (
+                        <div
+                            key={token.id}
+                            className="flex items-center justify-between gap-4 p-4 rounded-lg border border-gray-200 dark:border-dark-700"
+                        >
+                            <div className="min-w-0">
+                                <p className="font-medium text-gray-900 dark:text-white">
+                                    {token.name}{' '}
+                                    <span className="font-mono text-xs text-gray-500">{token.token_prefix}…</span>
+                                </p>
+                                <p className="text-xs text-gray-500 mt-1">{token.scopes.join(', ')}</p>
+                                <p className="text-xs text-gray-500 mt-1">
+                                    Dibuat {formatDate(token.created_at)} · Berlaku sampai {formatDate(token.expires_at)} ·
+                                    Terakhir dipakai {formatDate(token.last_used_at)}
+                                </p>
+                            </div>
+                            <button
+                                onClick={() => revoke(token)}
+                                className="p-2 text-red-500 hover:bg-red-500/10 rounded-lg transition"
+                                title="Cabut token"
+                            >
+                                <Trash2 className="w-4 h-4" />
+                            </button>
+                        </div>
+                    ))
+                )}
+            </div>
+        </div>
+    );
+}
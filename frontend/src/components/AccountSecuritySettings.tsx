@@ -0,0 +1,193 @@
+'use client';
+
+import { useEffect, useState } from 'react';
+import { Mail, Lock } from 'lucide-react';
+import { authApi } from '@/lib/api';
+import { User } from '@/lib/auth';
+
+interface AccountSecuritySettingsProps {
+    user: User;
+    onPasswordSet: () => void; // a Google-only account now has a password
+}
+
+// Change the sign-in email and password
+export default function AccountSecuritySettings({ user, onPasswordSet }: AccountSecuritySettingsProps) {
+    const hasPassword = user.has_password !== false;
+
+    const [newEmail, setNewEmail] = useState('');
+    const [emailPassword, setEmailPassword] = useState('');
+    const [currentPassword, setCurrentPassword] = useState('');
+    const [newPassword, setNewPassword] = useState('');
+    const [confirmPassword, setConfirmPassword] = useState('');
+    const [googleIdToken, setGoogleIdToken] = useState('');
+    const [GoogleLogin, setGoogleLogin] = useState<any>(null);
+    const [isBusy, setIsBusy] = useState(false);
+    const [message, setMessage] = useState({ type: '', text: '' });
+
+    useEffect(() => {
+        // Setting a first password needs a fresh Google sign-in
+        if (hasPassword) return;
+        import('@react-oauth/google').then((module) => {
+            setGoogleLogin(() => module.GoogleLogin);
+        });
+    }, [hasPassword]);
+
+    const changeEmail = async (e: React.FormEvent) => {
+        e.preventDefault();
+        setMessage({ type: '', text: '' });
+        setIsBusy(true);
+        try {
+            await authApi.changeEmail({ email: newEmail.trim(), password: emailPassword });
+            setMessage({
+                type: 'success',
+                text: `Link konfirmasi sudah dikirim ke ${newEmail.trim()}. Email akan diganti setelah link dibuka.`,
+            });
+            setNewEmail('');
+            setEmailPassword('');
+        } catch (err: any) {
+            setMessage({ type: 'error', text: err.response?.data?.error || 'Gagal mengganti email' });
+        } finally {
+            setIsBusy(false);
+        }
+    };
+
+    const changePassword = async (e: React.FormEvent) => {
+        e.preventDefault();
+        setMessage({ type: '', text: '' });
+        if (newPassword !== confirmPassword) {
+            setMessage({ type: 'error', text: 'Konfirmasi password tidak cocok' });
+            return;
+        }
+        setIsBusy(true);
+        try {
+            await authApi.changePassword({
+                ...(hasPassword ? { current_password: currentPassword } : { google_id_token: googleIdToken }),
+                new_password: newPassword,
+            });
+            setMessage({
+                type: 'success',
+                text: hasPassword
+                    ? 'Password berhasil diganti. Perangkat lain sudah dikeluarkan.'
+                    : 'Password berhasil dibuat. Sekarang kamu juga bisa masuk dengan email dan password.',
+            });
+            setCurrentPassword('');
+            setNewPassword('');
+            setConfirmPassword('');
+            setGoogleIdToken('');
+            if (!hasPassword) onPasswordSet();
+        } catch (err: any) {
+            setMessage({ type: 'error', text: err.response?.data?.error || 'Gagal mengganti password' });
+        } finally {
+            setIsBusy(false);
+        }
+    };
+
+    return (
+        <div className="space-y-8">
+            {message.text && (
+                <div
+                    className={`p-3 rounded-lg text-sm ${message.type === 'success'
+                        ? 'bg-green-500/10 border border-green-500/50 text-green-600 dark:text-green-400'
+                        : 'bg-red-500/10 border border-red-500/50 text-red-400'
+                        }`}
+                >
+                    {message.text}
+                </div>
+            )}
+
+            <form onSubmit={changeEmail} className="space-y-4">
+                <div className="flex items-center gap-3">
+                    <Mail className="w-5 h-5 text-primary-600 dark:text-primary-400" />
+                    <div>
+                        <h2 className="text-lg font-semibold text-gray-900 dark:text-white">Email</h2>
+                        <p className="text-sm text-gray-600 dark:text-gray-400">Saat ini: {user.email}</p>
+                    </div>
+                </div>
+                {hasPassword ? (
+                    <>
+                        <input
+                            type="email"
+                            value={newEmail}
+                            onChange={(e) => setNewEmail(e.target.value)}
+                            placeholder="Email baru"
+                            className="input max-w-md"
+                        />
+                        <input
+                            type="password"
+                            value={emailPassword}
+                            onChange={(e) => setEmailPassword(e.target.value)}
+                            placeholder="Password saat ini"
+                            className="input max-w-md"
+                        />
+                        <button type="submit" disabled={isBusy || !newEmail || !emailPassword} className="btn-primary">
+                            Ganti Email
+                        </button>
+                    </>
+                ) : (
+                    <p className="text-sm text-gray-600 dark:text-gray-400">
+                        Buat password terlebih dahulu untuk mengganti email.
+                    </p>
+                )}
+            </form>
+
+            <form onSubmit={changePassword} className="space-y-4 pt-8 border-t border-gray-200 dark:border-dark-700">
+                <div className="flex items-center gap-3">
+                    <Lock className="w-5 h-5 text-primary-600 dark:text-primary-400" />
+                    <div>
+                        <h2 className="text-lg font-semibold text-gray-900 dark:text-white">
+                            {hasPassword ? 'Ganti Password' : 'Buat Password'}
+                        </h2>
+                        <p className="text-sm text-gray-600 dark:text-gray-400">
+                            {hasPassword
+                                ? 'Perangkat lain akan dikeluarkan setelah password diganti.'
+                                : 'Akun kamu masuk dengan Google. Konfirmasi dengan Google, lalu buat password agar bisa masuk dengan email juga.'}
+                        </p>
+                    </div>
+                </div>
+                {hasPassword ? (
+                    <input
+                        type="password"
+                        value={currentPassword}
+                        onChange={(e) => setCurrentPassword(e.target.value)}
+                        placeholder="Password saat ini"
+                        className="input max-w-md"
+                    />
+                ) : googleIdToken ? (
+                    <p className="text-sm text-green-600 dark:text-green-400">Akun Google terkonfirmasi.</p>
+                ) : (
+                    GoogleLogin && (
+                        <GoogleLogin
+                            onSuccess={(response: any) => setGoogleIdToken(response.credential || '')}
+                            onError={() => setMessage({ type: 'error', text: 'Konfirmasi dengan Google gagal' })}
+                            text="continue_with"
+                            shape="pill"
+                            locale="id"
+                        />
+                    )
+                )}
+                <input
+                    type="password"
+                    value={newPassword}
+                    onChange={(e) => setNewPassword(e.target.value)}
+                    placeholder="Password baru (min. 6 karakter)"
+                    minLength={6}
+                    className="input max-w-md"
+                />
+                <input
+                    type="password"
+                    value={confirmPassword}
+                    onChange={(e) => setConfirmPassword(e.target.value)}
+                    placeholder="Ulangi password baru"
+                    className="input max-w-md"
+                />
+                <button
+                    type="submit"
+                    disabled={isBusy || newPassword.length < 6 || (hasPassword ? !currentPassword : !googleIdToken)}
+                    className="btn-primary"
+                >
+                    {hasPassword ? 'Ganti Password' : 'Buat Password'}
+                </button>
+            </form>
+        </div>
+    );
+}
@@ -15,7 +15,8 @@ import {
     Settings,
 } from 'lucide-react';
 import { authApi } from '@/lib/api';
-import { isAuthenticated, logout } from '@/lib/auth';
+import { adminApi, Permission } from '@/lib/adminApi';
+import { isAuthenticated, isStaff, logout } from '@/lib/auth';
 import ThemeToggle from '@/components/ThemeToggle';
 
 interface User {
@@ -30,18 +31,27 @@ interface AdminLayoutProps {
     children: React.ReactNode;
 }
 
-const navItems = [
-    { href: '/admin', icon: LayoutDashboard, label: 'Dashboard' },
-    { href: '/admin/products', icon: Package, label: 'Produk Jajan' },
-    { href: '/admin/withdrawals', icon: Wallet, label: 'Withdrawals' },
-    { href: '/admin/users', icon: Users, label: 'Users' },
-    { href: '/admin/settings', icon: Settings, label: 'Settings' },
+// Each page is only shown to staff with the permission its API needs
+const navItems: { href: string; icon: typeof LayoutDashboard; label: string; permission: Permission }[] = [
+    { href: '/admin', icon: LayoutDashboard, label: 'Dashboard', permission: 'stats:read' },
+    { href: '/admin/products', icon: Package, label: 'Produk Jajan', permission: 'products:manage' },
+    { href: '/admin/withdrawals', icon: Wallet, label: 'Withdrawals', permission: 'withdrawals:read' },
+    { href: '/admin/users', icon: Users, label: 'Users', permission: 'users:read' },
+    { href: '/admin/settings', icon: Settings, label: 'Settings', permission: 'settings:manage' },
 ];
 
+const roleLabels: Record<string, string> = {
+    admin: 'Admin',
+    finance: 'Finance',
+    support: 'Support',
+    content: 'Konten',
+};
+
 export default function AdminLayout({ children }: AdminLayoutProps) {
     const router = useRouter();
     const pathname = usePathname();
     const [user, setUser] = useState<User | null>(null);
+    const [permissions, setPermissions] = useState<Permission[]>([]);
     const [isLoading, setIsLoading] = useState(true);
     const [isMobileMenuOpen, setIsMobileMenuOpen] = useState(false);
 
@@ -56,12 +66,33 @@ export default function AdminLayout({ children }: AdminLayoutProps) {
                 const response = await authApi.me();
                 const userData = response.data.data;
 
-                // Check if user is admin
-                if (userData.role !== 'admin') {
+                // Check if user is staff
+                if (!isStaff(userData)) {
                     router.push('/dashboard');
                     return;
                 }
 
+                // Admin routes require two-factor authentication
+                const twoFactor = await authApi.getTwoFactor();
+                if (!twoFactor.data.data.enabled) {
+                    router.push('/dashboard/settings?tab=security');
+                    return;
+                }
+
+                const me = await adminApi.getMe();
+                const granted: Permission[] = me.data.data.permissions;
+
+                // Send staff to a page they can use
+                const current = navItems.find((item) => item.href === pathname);
+                if (current && !granted.includes(current.permission)) {
+                    const allowed = navItems.find((item) => granted.includes(item.permission));
+                    if (allowed) {
+                        router.replace(allowed.href);
+                        return;
+                    }
+                }
+
+                setPermissions(granted);
                 setUser(userData);
             } catch (err) {
                 router.push('/login');
@@ -71,7 +102,7 @@ export default function AdminLayout({ children }: AdminLayoutProps) {
         };
 
         fetchUser();
-    }, [router]);
+    }, [router, pathname]);
 
     const handleLogout = () => {
         logout();
@@ -125,7 +156,7 @@ export default function AdminLayout({ children }: AdminLayoutProps) {
 
                 {/* Navigation */}
                 <nav className="p-4 space-y-2">
-                    {navItems.map((item) => {
+                    {navItems.filter((item) => permissions.includes(item.permission)).map((item) => {
                         const isActive = pathname === item.href;
                         return (
                             <Link
@@ -152,7 +183,7 @@ export default function AdminLayout({ children }: AdminLayoutProps) {
                         </div>
                         <div className="flex-1 min-w-0">
                             <p className="font-semibold text-gray-900 dark:text-white truncate">{user.name}</p>
-                            <p className="text-sm text-red-500">Admin</p>
+                            <p className="text-sm text-red-500">{roleLabels[user.role] || user.role}</p>
                         </div>
                     </div>
 
@@ -16,8 +16,9 @@ import {
     Coffee,
     Shield,
 } from 'lucide-react';
-import { logout, User } from '@/lib/auth';
+import { isStaff, logout, User } from '@/lib/auth';
 import ThemeToggle from './ThemeToggle';
+import VerifyEmailBanner from './VerifyEmailBanner';
 
 interface DashboardLayoutProps {
     user: User | null;
@@ -154,7 +155,7 @@ export default function DashboardLayout({ user, children }: DashboardLayoutProps
                                     {user?.name?.charAt(0)}
                                 </div>
                             )}
-                            {user?.role === 'admin' && (
+                            {isStaff(user) && (
                                 <Link
                                     href="/admin"
                                     className="p-2 rounded-lg text-red-500 hover:bg-red-500/10 transition"
@@ -192,7 +193,7 @@ export default function DashboardLayout({ user, children }: DashboardLayoutProps
                                 </div>
                             </div>
                             <div className="flex items-center gap-2">
-                                {user?.role === 'admin' && (
+                                {isStaff(user) && (
                                     <Link
                                         href="/admin"
                                         className="flex items-center gap-2 text-red-500 hover:bg-red-500/10 px-3 py-1.5 rounded-lg transition text-sm"
@@ -216,6 +217,7 @@ export default function DashboardLayout({ user, children }: DashboardLayoutProps
 
             {/* Main Content */}
             <main className={`flex-1 ${isCollapsed ? 'ml-20' : 'ml-64'} p-8 transition-all duration-300 ease-in-out`}>
+                {user && !user.email_verified_at && <VerifyEmailBanner email={user.email} />}
                 {children}
             </main>
         </div>
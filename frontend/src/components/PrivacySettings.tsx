@@ -0,0 +1,116 @@
+'use client';
+
+import { useState } from 'react';
+import { Download, Trash2 } from 'lucide-react';
+import { userApi } from '@/lib/api';
+import { logout } from '@/lib/auth';
+
+interface PrivacySettingsProps {
+    hasPassword: boolean; // Google-only accounts confirm with their email instead
+}
+
+// Download a copy of the account's data, or delete the account
+export default function PrivacySettings({ hasPassword }: PrivacySettingsProps) {
+    const googleOnly = !hasPassword;
+    const [isExporting, setIsExporting] = useState(false);
+    const [isDeleting, setIsDeleting] = useState(false);
+    const [confirmation, setConfirmation] = useState('');
+    const [forfeitBalance, setForfeitBalance] = useState(false);
+    const [error, setError] = useState('');
+
+    const exportData = async () => {
+        setError('');
+        setIsExporting(true);
+        try {
+            const response = await userApi.exportData();
+            const url = URL.createObjectURL(response.data);
+            const link = document.createElement('a');
+            link.href = url;
+            link.download = `jajanin-export-${new Date().toISOString().slice(0, 10)}.zip`;
+            link.click();
+            URL.revokeObjectURL(url);
+        } catch (err: any) {
+            setError(err.response?.data?.error || 'Gagal mengunduh data');
+        } finally {
+            setIsExporting(false);
+        }
+    };
+
+    const deleteAccount = async (e: React.FormEvent) => {
+        e.preventDefault();
+        if (!confirm('Hapus akun secara permanen? Tindakan ini tidak bisa dibatalkan.')) return;
+        setError('');
+        setIsDeleting(true);
+        try {
+            await userApi.deleteAccount({
+                ...(googleOnly ? { email: confirmation.trim() } : { password: confirmation }),
+                forfeit_balance: forfeitBalance,
+            });
+            await logout();
+        } catch (err: any) {
+            setError(err.response?.data?.error || 'Gagal menghapus akun');
+            setIsDeleting(false);
+        }
+    };
+
+    return (
+        <div className="space-y-8">
+            <div>
+                <h2 className="text-lg font-semibold text-gray-900 dark:text-white mb-2">Unduh Data Saya</h2>
+                <p className="text-sm text-gray-600 dark:text-gray-400 mb-4">
+                    Arsip ZIP berisi profil, donasi yang diterima, jajan yang kamu beli, riwayat penarikan dan
+                    pengaturan overlay.
+                </p>
+                <button onClick={exportData} disabled={isExporting} className="btn-secondary flex items-center gap-2">
+                    <Download className="w-4 h-4" />
+                    {isExporting ? 'Menyiapkan...' : 'Unduh Data'}
+                </button>
+            </div>
+
+            <form onSubmit={deleteAccount} className="space-y-4 pt-8 border-t border-gray-200 dark:border-dark-700">
+                <div>
+                    <h2 className="text-lg font-semibold text-red-600 dark:text-red-400 mb-2">Hapus Akun</h2>
+                    <p className="text-sm text-gray-600 dark:text-gray-400">
+                        Data pribadi kamu dan nama pendukung di donasi akan dihapus. Catatan transaksi tetap disimpan
+                        untuk keperluan keuangan. Saldo harus ditarik terlebih dahulu. Username baru bisa dipakai
+                        orang lain setelah masa tunggu.
+                    </p>
+                </div>
+                <div>
+                    <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-2">
+                        {googleOnly ? 'Ketik alamat email kamu' : 'Kata sandi'}
+                    </label>
+                    <input
+                        type={googleOnly ? 'email' : 'password'}
+                        value={confirmation}
+                        onChange={(e) => setConfirmation(e.target.value)}
+                        className="input max-w-md"
+                    />
+                </div>
+                <label className="flex items-center gap-2 text-sm text-gray-700 dark:text-gray-300">
+                    <input
+                        type="checkbox"
+                        checked={forfeitBalance}
+                        onChange={(e) => setForfeitBalance(e.target.checked)}
+                    />
+                    Saya merelakan sisa saldo di bawah minimum penarikan (Rp 50.000)
+                </label>
+
+                {error && (
+                    <div className="bg-red-500/10 border border-red-500/50 rounded-lg p-3 text-red-400 text-sm">
+                        {error}
+                    </div>
+                )}
+
+                <button
+                    type="submit"
+                    disabled={isDeleting || !confirmation}
+                    className="flex items-center gap-2 px-4 py-2 rounded-lg bg-red-600 text-white hover:bg-red-700 transition disabled:opacity-50"
+                >
+                    <Trash2 className="w-4 h-4" />
+                    {isDeleting ? 'Menghapus...' : 'Hapus Akun'}
+                </button>
+            </form>
+        </div>
+    );
+}
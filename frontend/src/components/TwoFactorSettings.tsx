@@ -0,0 +1,221 @@
+'use client';
+
+import { useEffect, useState } from 'react';
+import { ShieldCheck, ShieldOff, KeyRound, Copy, Check } from 'lucide-react';
+import { authApi } from '@/lib/api';
+
+interface TwoFactorStatus {
+    enabled: boolean;
+    enabled_at?: string;
+    recovery_codes_remaining: number;
+}
+
+interface TwoFactorSetup {
+    secret: string;
+    uri: string;
+    qr_code: string;
+}
+
+// Enroll, manage and turn off TOTP two-factor authentication
+export default function TwoFactorSettings({ isStaff }: { isStaff?: boolean }) {
+    const [status, setStatus] = useState<TwoFactorStatus | null>(null);
+    const [setup, setSetup] = useState<TwoFactorSetup | null>(null);
+    const [recoveryCodes, setRecoveryCodes] = useState<string[]>([]);
+    const [code, setCode] = useState('');
+    const [isBusy, setIsBusy] = useState(false);
+    const [copied, setCopied] = useState(false);
+    const [error, setError] = useState('');
+
+    const loadStatus = async () => {
+        try {
+            const response = await authApi.getTwoFactor();
+            setStatus(response.data.data);
+        } catch (err) {
+            console.error('Failed to load two-factor status:', err);
+        }
+    };
+
+    useEffect(() => {
+        loadStatus();
+    }, []);
+
+    // Runs an action that needs a code, then clears the input
+    const withCode = async (action: (code: string) => Promise<void>) => {
+        setError('');
+        setIsBusy(true);
+        try {
+            await action(code.trim());
+            setCode('');
+        } catch (err: any) {
+            setError(err.response?.data?.error || 'Kode verifikasi salah');
+        } finally {
+            setIsBusy(false);
+        }
+    };
+
+    const startSetup = async () => {
+        setError('');
+        setIsBusy(true);
+        try {
+            const response = await authApi.setupTwoFactor();
+            setSetup(response.data.data);
+        } catch (err: any) {
+            setError(err.response?.data?.error || 'Gagal memulai pengaturan');
+        } finally {
+            setIsBusy(false);
+        }
+    };
+
+    const enable = () =>
+        withCode(async (value) => {
+            const response = await authApi.enableTwoFactor({ code: value });
+            setRecoveryCodes(response.data.data.recovery_codes);
+            setSetup(null);
+            await loadStatus();
+        });
+
+    const disable = () =>
+        withCode(async (value) => {
+            await authApi.disableTwoFactor({ code: value });
+            setRecoveryCodes([]);
+            await loadStatus();
+        });
+
+    const regenerate = () =>
+        withCode(async (value) => {
+            const response = await authApi.regenerateRecoveryCodes({ code: value });
+            setRecoveryCodes(response.data.data.recovery_codes);
+            await loadStatus();
+        });
+
+    const copyRecoveryCodes = () => {
+        navigator.clipboard.writeText(recoveryCodes.join('\n'));
+        setCopied(true);
+        setTimeout(() => setCopied(false), 2000);
+    };
+
+    if (!status) {
+        return (
+            <div className="flex justify-center py-8">
+                <div className="w-6 h-6 border-2 border-primary-600 border-t-transparent rounded-full animate-spin" />
+            </div>
+        );
+    }
+
+    const codeInput = (placeholder: string) => (
+        <input
+            type="text"
+            value={code}
+            onChange={(e) => setCode(e.target.value)}
+            placeholder={placeholder}
+            autoComplete="one-time-code"
+            className="input max-w-xs tracking-widest"
+        />
+    );
+
+    return (
+        <div className="space-y-6">
+            <div className="flex items-center gap-3">
+                <div className="w-10 h-10 rounded-lg bg-primary-600/20 flex items-center justify-center">
+                    {status.enabled ? (
+                        <ShieldCheck className="w-5 h-5 text-primary-600 dark:text-primary-400" />
+                    ) : (
+                        <ShieldOff className="w-5 h-5 text-primary-600 dark:text-primary-400" />
+                    )}
+                </div>
+                <div>
+                    <h2 className="text-lg font-semibold text-gray-900 dark:text-white">Verifikasi Dua Langkah</h2>
+                    <p className="text-sm text-gray-600 dark:text-gray-400">
+                        {status.enabled
+                            ? `Aktif · ${status.recovery_codes_remaining} kode pemulihan tersisa`
+                            : 'Lindungi saldo dan rekening bank kamu dengan kode dari aplikasi autentikator'}
+                    </p>
+                </div>
+            </div>
+
+            {isStaff && !status.enabled && (
+                <div className="p-4 rounded-lg bg-yellow-500/10 border border-yellow-500/50 text-yellow-700 dark:text-yellow-400 text-sm">
+                    Akun staf wajib mengaktifkan verifikasi dua langkah untuk membuka panel admin.
+                </div>
+            )}
+
+            {/* Recovery codes are only shown once */}
+            {recoveryCodes.length > 0 && (
+                <div className="p-4 rounded-lg bg-gray-50 dark:bg-dark-800 border border-gray-200 dark:border-dark-600">
+                    <div className="flex items-center justify-between mb-3">
+                        <p className="font-medium text-gray-900 dark:text-white flex items-center gap-2">
+                            <KeyRound className="w-4 h-4" />
+                            Kode Pemulihan
+                        </p>
+                        <button onClick={copyRecoveryCodes} className="btn-secondary flex items-center gap-2 text-sm">
+                            {copied ? <Check className="w-4 h-4" /> : <Copy className="w-4 h-4" />}
+                            {copied ? 'Tersalin!' : 'Salin'}
+                        </button>
+                    </div>
+                    <p className="text-sm text-gray-600 dark:text-gray-400 mb-3">
+                        Simpan kode ini di tempat aman. Setiap kode hanya bisa dipakai sekali jika kamu kehilangan akses ke aplikasi autentikator. Kode ini tidak akan ditampilkan lagi.
+                    </p>
+                    <div className="grid grid-cols-2 gap-2 font-mono text-sm text-gray-900 dark:text-white">
+                        {recoveryCodes.map((recoveryCode) => (
+                            <span key={recoveryCode}>{recoveryCode}</span>
+                        ))}
+                    </div>
+                </div>
+            )}
+
+            {!status.enabled && !setup && (
+                <button onClick={startSetup} disabled={isBusy} className="btn-primary">
+                    Aktifkan Verifikasi Dua Langkah
+                </button>
+            )}
+
+            {!status.enabled && setup && (
+                <div className="space-y-4">
+                    <p className="text-sm text-gray-600 dark:text-gray-400">
+                        Pindai kode QR ini dengan Google Authenticator, Authy atau aplikasi sejenis, lalu masukkan 6 digit kode yang muncul.
+                    </p>
+                    <img src={setup.qr_code} alt="QR verifikasi dua langkah" className="w-48 h-48 rounded-lg bg-white" />
+                    <p className="text-sm text-gray-600 dark:text-gray-400">
+                        Tidak bisa memindai? Masukkan kode ini secara manual:{' '}
+                        <span className="font-mono text-gray-900 dark:text-white break-all">{setup.secret}</span>
+                    </p>
+                    <div className="flex flex-wrap gap-3">
+                        {codeInput('123456')}
+                        <button onClick={enable} disabled={isBusy || !code} className="btn-primary">
+                            Konfirmasi
+                        </button>
+                    </div>
+                </div>
+            )}
+
+            {status.enabled && (
+                <div className="space-y-3">
+                    <p className="text-sm text-gray-600 dark:text-gray-400">
+                        Masukkan kode dari aplikasi autentikator untuk membuat kode pemulihan baru atau menonaktifkan verifikasi dua langkah.
+                    </p>
+                    <div className="flex flex-wrap gap-3">
+                        {codeInput('Kode verifikasi')}
+                        <button onClick={regenerate} disabled={isBusy || !code} className="btn-secondary">
+                            Buat Kode Pemulihan Baru
+                        </button>
+                        {!isStaff && (
+                            <button
+                                onClick={disable}
+                                disabled={isBusy || !code}
+                                className="px-4 py-2 rounded-lg border border-red-500/50 text-red-600 dark:text-red-400 hover:bg-red-500/10 transition disabled:opacity-50"
+                            >
+                                Nonaktifkan
+                            </button>
+                        )}
+                    </div>
+                </div>
+            )}
+
+            {error && (
+                <div className="bg-red-500/10 border border-red-500/50 rounded-lg p-3 text-red-400 text-sm">
+                    {error}
+                </div>
+            )}
+        </div>
+    );
+}
@@ -0,0 +1,41 @@
+'use client';
+
+import { useState } from 'react';
+import { MailWarning } from 'lucide-react';
+import { authApi } from '@/lib/api';
+
+// Shown in the dashboard until the creator verifies their email;
+// withdrawals are blocked until then
+export default function VerifyEmailBanner({ email }: { email: string }) {
+    const [status, setStatus] = useState<'idle' | 'sending' | 'sent' | 'error'>('idle');
+
+    const resend = async () => {
+        setStatus('sending');
+        try {
+            await authApi.resendVerification();
+            setStatus('sent');
+        } catch {
+            setStatus('error');
+        }
+    };
+
+    return (
+        <div className="mb-6 flex flex-col sm:flex-row sm:items-center gap-3 bg-yellow-500/10 border border-yellow-500/50 rounded-xl p-4">
+            <MailWarning className="w-5 h-5 text-yellow-600 dark:text-yellow-400 flex-shrink-0" />
+            <p className="flex-1 text-sm text-gray-700 dark:text-gray-300">
+                Verifikasi email <strong>{email}</strong> untuk bisa menarik saldo. Cek inbox kamu untuk link verifikasi.
+            </p>
+            {status === 'sent' ? (
+                <span className="text-sm text-green-600 dark:text-green-400">Email terkirim</span>
+            ) : (
+                <button
+                    onClick={resend}
+                    disabled={status === 'sending'}
+                    className="text-sm font-medium text-primary-600 dark:text-primary-400 hover:text-primary-500 dark:hover:text-primary-300 transition disabled:opacity-50"
+                >
+                    {status === 'error' ? 'Gagal, coba lagi' : 'Kirim ulang'}
+                </button>
+            )}
+        </div>
+    );
+}
@@ -1,10 +1,43 @@
 import api from './api';
 
+// Staff permissions, matching the backend's models.Perm* constants
+export type Permission =
+    | 'stats:read'
+    | 'users:read'
+    | 'users:write'
+    | 'withdrawals:read'
+    | 'withdrawals:write'
+    | 'products:manage'
+    | 'settings:manage'
+    | 'audit:read'
+    | 'roles:manage';
+
+export interface AdminRole {
+    name: string;
+    description: string;
+    permissions: Permission[];
+    editable: boolean;
+}
+
 // Admin APIs
 export const adminApi = {
+    // Role and permissions of the signed-in staff member
+    getMe: () => api.get('/api/v1/admin/me'),
     getStats: () => api.get('/api/v1/admin/stats'),
     getUsers: (page = 1, limit = 10) =>
         api.get(`/api/v1/admin/users?page=${page}&limit=${limit}`),
+    // Lift a login lockout early
+    unlockUser: (id: string) => api.post(`/api/v1/admin/users/${id}/unlock`),
+    setUserRole: (id: string, role: string) => api.put(`/api/v1/admin/users/${id}/role`, { role }),
+    getAuditLogs: (filter: { user_id?: string; action?: string; email?: string } = {}, page = 1, limit = 20) => {
+        const params = new URLSearchParams();
+        Object.entries(filter).forEach(([key, value]) => {
+            if (value) params.append(key, value);
+        });
+        params.append('page', page.toString());
+        params.append('limit', limit.toString());
+        return api.get(`/api/v1/admin/audit-logs?${params.toString()}`);
+    },
     getWithdrawals: (status?: string, page = 1, limit = 10) => {
         const params = new URLSearchParams();
         if (status) params.append('status', status);
@@ -31,4 +64,9 @@ export const adminApi = {
     getSettings: () => api.get('/api/v1/admin/settings'),
     updateSettings: (data: { admin_fee_percent: number }) =>
         api.put('/api/v1/admin/settings', data),
+
+    // Roles
+    getRoles: () => api.get('/api/v1/admin/roles'),
+    updateRole: (name: string, permissions: Permission[]) =>
+        api.put(`/api/v1/admin/roles/${name}`, { permissions }),
 };
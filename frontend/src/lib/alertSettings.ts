@@ -1,6 +1,8 @@
 // Alert Settings Types and API
 
 export interface AlertSettings {
+    version?: number;          // schema version, managed by the server
+
     // Visual Settings
     theme: 'default' | 'custom';
     background_color: string;  // hex color
@@ -17,6 +19,14 @@ export interface AlertSettings {
     sound_enabled: boolean;
     sound_file: string;        // 'default', 'coin', 'bell', 'chime'
     sound_volume: number;      // 0-100
+
+    // Uploaded assets (see assetApi)
+    sound_asset_id?: string;
+    image_asset_id?: string;
+
+    // Signed URLs, only present when served to overlays
+    sound_url?: string;
+    image_url?: string;
 }
 
 export const DEFAULT_ALERT_SETTINGS: AlertSettings = {
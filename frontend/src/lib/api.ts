@@ -19,10 +19,88 @@ api.interceptors.request.use((config) => {
     return config;
 });
 
+// Refresh the session once for all requests that hit a 401 at the same time;
+// the refresh token rotates, so concurrent refreshes would revoke the session
+let refreshing: Promise<string | null> | null = null;
+
+const refreshSession = (): Promise<string | null> => {
+    const refreshToken = Cookies.get('refresh_token');
+    if (!refreshToken) {
+        return Promise.resolve(null);
+    }
+    if (!refreshing) {
+        refreshing = axios
+            .post(`${API_URL}/api/v1/auth/refresh`, { refresh_token: refreshToken })
+            .then((response) => {
+                const { token, refresh_token } = response.data.data;
+                Cookies.set('token', token, { expires: 30 });
+                Cookies.set('refresh_token', refresh_token, { expires: 30 });
+                return token as string;
+            })
+            .catch(() => {
+                Cookies.remove('refresh_token');
+                return null;
+            })
+            .finally(() => {
+                refreshing = null;
+            });
+    }
+    return refreshing;
+};
+
+// Sensitive actions (bank details, withdrawals, stream key) need a fresh
+// two-factor code; ask for one and retry once
+const STEP_UP_MESSAGE = 'two-factor verification required';
+
+const stepUp = async (): Promise<boolean> => {
+    if (typeof window === 'undefined') {
+        return false;
+    }
+    const code = window.prompt('Masukkan kode dari aplikasi autentikator (atau kode pemulihan) untuk melanjutkan:');
+    if (!code) {
+        return false;
+    }
+    try {
+        await api.post('/api/v1/auth/2fa/step-up', { code: code.trim() });
+        return true;
+    } catch (err: any) {
+        window.alert(err.response?.data?.error || 'Kode verifikasi salah');
+        return false;
+    }
+};
+
 // Handle auth errors
 api.interceptors.response.use(
     (response) => response,
-    (error) => {
+    async (error) => {
+        const original = error.config;
+        // Downloads (data export) get their JSON errors back as a Blob
+        if (error.response?.data instanceof Blob && error.response.data.type.includes('json')) {
+            try {
+                error.response.data = JSON.parse(await error.response.data.text());
+            } catch {
+                // Leave the Blob as is
+            }
+        }
+        if (
+            error.response?.status === 403 &&
+            error.response.data?.error === STEP_UP_MESSAGE &&
+            original &&
+            !original._stepUp
+        ) {
+            original._stepUp = true;
+            if (await stepUp()) {
+                return api(original);
+            }
+        }
+        if (error.response?.status === 401 && original && !original._retry) {
+            original._retry = true;
+            const token = await refreshSession();
+            if (token) {
+                original.headers.Authorization = `Bearer ${token}`;
+                return api(original);
+            }
+        }
         if (error.response?.status === 401) {
             Cookies.remove('token');
             // Only redirect to login if on a protected page (dashboard)
@@ -47,10 +125,60 @@ export const authApi = {
     login: (data: { email: string; password: string }) =>
         api.post('/api/v1/auth/login', data),
 
-    googleAuth: (data: { google_id: string; email: string; name: string; image_url: string }) =>
+    googleAuth: (data: { id_token: string }) =>
         api.post('/api/v1/auth/google', data),
 
     me: () => api.get('/api/v1/auth/me'),
+
+    logout: () => api.post('/api/v1/auth/logout'),
+
+    logoutAll: () => api.post('/api/v1/auth/logout-all'),
+
+    getSessions: () => api.get('/api/v1/auth/sessions'),
+
+    revokeSession: (id: string) => api.delete(`/api/v1/auth/sessions/${id}`),
+
+    verifyEmail: (data: { token: string }) => api.post('/api/v1/auth/verify-email', data),
+
+    resendVerification: () => api.post('/api/v1/auth/verify-email/resend'),
+
+    forgotPassword: (data: { email: string }) => api.post('/api/v1/auth/forgot-password', data),
+
+    resetPassword: (data: { token: string; password: string }) =>
+        api.post('/api/v1/auth/reset-password', data),
+
+    // Google-only accounts setting their first password send a fresh Google
+    // ID token instead of current_password
+    changePassword: (data: { current_password?: string; google_id_token?: string; new_password: string }) =>
+        api.post('/api/v1/auth/change-password', data),
+
+    // Sends a confirmation link to the new address
+    changeEmail: (data: { email: string; password: string }) => api.post('/api/v1/auth/change-email', data),
+
+    confirmEmailChange: (data: { token: string }) => api.post('/api/v1/auth/confirm-email-change', data),
+
+    // Second step of a login that returned two_factor_required
+    verifyTwoFactor: (data: { challenge_token: string; code: string }) =>
+        api.post('/api/v1/auth/2fa/verify', data),
+
+    getTwoFactor: () => api.get('/api/v1/auth/2fa'),
+
+    setupTwoFactor: () => api.post('/api/v1/auth/2fa/setup'),
+
+    enableTwoFactor: (data: { code: string }) => api.post('/api/v1/auth/2fa/enable', data),
+
+    disableTwoFactor: (data: { code: string }) => api.post('/api/v1/auth/2fa/disable', data),
+
+    regenerateRecoveryCodes: (data: { code: string }) =>
+        api.post('/api/v1/auth/2fa/recovery-codes', data),
+
+    // Personal access tokens for bots and scripts
+    getAccessTokens: () => api.get('/api/v1/auth/tokens'),
+
+    createAccessToken: (data: { name: string; scopes: string[]; expires_in_days?: number }) =>
+        api.post('/api/v1/auth/tokens', data),
+
+    revokeAccessToken: (id: string) => api.delete(`/api/v1/auth/tokens/${id}`),
 };
 
 // User APIs
@@ -79,9 +207,21 @@ export const userApi = {
     updateAlertSettings: (data: any) =>
         api.put('/api/v1/users/alert-settings', data),
 
+    // Partial update: only the fields sent are changed
+    patchAlertSettings: (data: any) =>
+        api.patch('/api/v1/users/alert-settings', data),
+
     regenerateStreamKey: () =>
         api.post('/api/v1/users/regenerate-stream-key'),
 
+    // ZIP of profile, donations, purchases, withdrawals and settings
+    exportData: () =>
+        api.get('/api/v1/users/me/export', { responseType: 'blob' }),
+
+    // Confirm with the password, or the email address for Google-only accounts
+    deleteAccount: (data: { password?: string; email?: string; forfeit_balance?: boolean }) =>
+        api.delete('/api/v1/users/me', { data }),
+
     getAlertSettingsByStreamKey: (streamKey: string) =>
         api.get(`/overlay/settings/${streamKey}`),
 };
@@ -127,6 +267,44 @@ export const configApi = {
     getConfig: () => api.get('/api/v1/config'),
 };
 
+// Asset APIs (custom alert sounds / images)
+export const assetApi = {
+    getAll: () => api.get('/api/v1/assets'),
+    upload: (file: File) => {
+        const form = new FormData();
+        form.append('file', file);
+        return api.post('/api/v1/assets', form, {
+            headers: { 'Content-Type': 'multipart/form-data' },
+        });
+    },
+    delete: (id: string) => api.delete(`/api/v1/assets/${id}`),
+};
+
+// Overlay APIs (authenticated overlay management)
+export const overlayApi = {
+    getStatus: (limit = 10) => api.get(`/api/v1/overlay/status?limit=${limit}`),
+    sendTest: () => api.post('/api/v1/overlay/test'),
+    getTokens: () => api.get('/api/v1/overlay/tokens'),
+    createToken: (data: { label: string; widget: string }) =>
+        api.post('/api/v1/overlay/tokens', data),
+    revokeToken: (id: string) => api.delete(`/api/v1/overlay/tokens/${id}`),
+
+    // Named overlay profiles
+    getProfiles: () => api.get('/api/v1/overlay/profiles'),
+    createProfile: (data: { name: string; settings?: any; create_token?: boolean }) =>
+        api.post('/api/v1/overlay/profiles', data),
+    updateProfile: (id: string, data: { name?: string; settings?: any }) =>
+        api.patch(`/api/v1/overlay/profiles/${id}`, data),
+    deleteProfile: (id: string) => api.delete(`/api/v1/overlay/profiles/${id}`),
+    cloneProfile: (id: string, name?: string) =>
+        api.post(`/api/v1/overlay/profiles/${id}/clone`, name ? { name } : {}),
+    // Copies the profile's settings into the creator's alert settings
+    setDefaultProfile: (id: string) => api.post(`/api/v1/overlay/profiles/${id}/default`),
+    // Called by the alert overlay (public, authenticated by overlay token)
+    ackAlert: (token: string, donationId: string) =>
+        api.post(`/overlay/ack/${token}`, { donation_id: donationId }),
+};
+
 // Product APIs (global jajan items)
 export const productApi = {
     getAll: () => api.get('/api/v1/products'),
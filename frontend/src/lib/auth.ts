@@ -1,8 +1,11 @@
 import Cookies from 'js-cookie';
+import { authApi } from './api';
 
 export interface User {
     id: string;
     email: string;
+    email_verified_at: string | null;
+    has_password?: boolean; // false for accounts that only sign in with Google
     name: string;
     username: string | null;
     image_url: string | null;
@@ -14,8 +17,42 @@ export interface User {
     stream_key?: string;
 }
 
-export const setToken = (token: string) => {
-    Cookies.set('token', token, { expires: 7 });
+// Staff are every role except a plain user: admin, finance, support, content
+export const isStaff = (user?: { role?: string } | null) => !!user?.role && user.role !== 'user';
+
+// Returned by login, register and Google sign-in
+export interface AuthTokens {
+    token: string;
+    refresh_token: string;
+}
+
+// Login and Google sign-in return this instead of tokens when the account has
+// two-factor authentication; the code is then sent with authApi.verifyTwoFactor
+export interface TwoFactorChallenge {
+    two_factor_required: true;
+    challenge_token: string;
+}
+
+export type AuthResult = AuthTokens | TwoFactorChallenge;
+
+export const isTwoFactorChallenge = (result: AuthResult): result is TwoFactorChallenge =>
+    'two_factor_required' in result && result.two_factor_required === true;
+
+export interface Session {
+    id: string;
+    user_agent: string;
+    device_name: string;
+    ip_address: string;
+    last_used_at: string;
+    expires_at: string;
+    created_at: string;
+    current: boolean;
+}
+
+// The access token is short-lived and renewed with the refresh token (see lib/api.ts)
+export const setSession = (tokens: AuthTokens) => {
+    Cookies.set('token', tokens.token, { expires: 30 });
+    Cookies.set('refresh_token', tokens.refresh_token, { expires: 30 });
 };
 
 export const getToken = (): string | undefined => {
@@ -24,13 +61,22 @@ export const getToken = (): string | undefined => {
 
 export const removeToken = () => {
     Cookies.remove('token');
+    Cookies.remove('refresh_token');
 };
 
 export const isAuthenticated = (): boolean => {
-    return !!getToken();
+    return !!getToken() || !!Cookies.get('refresh_token');
 };
 
-export const logout = () => {
+export const logout = async () => {
+    // Revoke the session server-side; clear local tokens even if that fails
+    try {
+        if (getToken()) {
+            await authApi.logout();
+        }
+    } catch {
+        // ignore
+    }
     removeToken();
     if (typeof window !== 'undefined') {
         window.location.href = '/';